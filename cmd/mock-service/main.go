@@ -1,34 +1,63 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"mock-service/internal/admin"
+	"mock-service/internal/chaos"
 	"mock-service/internal/config"
 	"mock-service/internal/handler"
+	"mock-service/internal/journal"
 	"mock-service/internal/logger"
 	"mock-service/internal/matcher"
+	"mock-service/internal/persistence"
+	"mock-service/internal/resource"
 	"mock-service/internal/response"
+	"mock-service/internal/sequence"
 
 	"github.com/gin-gonic/gin"
 )
 
+// defaultStateFlushInterval is how often the state file is saved by default
+const defaultStateFlushInterval = 5 * time.Second
+
+// defaultJournalSize is how many requests the journal keeps in memory by default
+const defaultJournalSize = 1000
+
+// journalFileMode is the permission of a journal file created by the service
+const journalFileMode = 0o644
+
 func main() {
 	// Parse command line flags
 	var configFile string
 	var port string
+	var stateFile string
+	var stateFlushInterval time.Duration
+	var writeBack bool
+	var journalSize int
+	var journalFile string
 
 	flag.StringVar(&configFile, "config", "config.json", "Path to configuration file")
 	flag.StringVar(&port, "port", "8080", "Port to listen on")
+	flag.StringVar(&stateFile, "state-file", "", "Path to a file keeping the mock state across restarts (default: disabled)")
+	flag.DurationVar(&stateFlushInterval, "state-flush-interval", defaultStateFlushInterval,
+		"How often the state file is saved; 0 saves on shutdown only")
+	flag.BoolVar(&writeBack, "write-back", false, "Write rules changed through the admin API back to the config file")
+	flag.IntVar(&journalSize, "journal-size", defaultJournalSize,
+		"How many recent requests the journal keeps in memory; 0 keeps none")
+	flag.StringVar(&journalFile, "journal-file", "", "Path to a JSON Lines file every request is appended to (default: disabled)")
 	flag.Parse()
 
 	// Initialize components
 	configManager := config.NewConfigManager()
-	pathMatcher := matcher.NewPathMatcher()
+	pathMatcher := matcher.NewIndexedMatcher()
 	responseBuilder := response.NewResponseBuilder()
 	appLogger := logger.NewLogger()
 
@@ -36,6 +65,43 @@ func main() {
 	if err := configManager.LoadConfig(configFile); err != nil {
 		log.Fatalf("Failed to load configuration from %s: %v", configFile, err)
 	}
+	pathMatcher.SetSelectionStrategy(configManager.GetSelectionStrategy())
+	if writeBack {
+		configManager.EnableWriteBack()
+	}
+
+	// Exhausted response sequences that fall through, and rules whose scenario is in another state, stop matching
+	sequenceCounters := sequence.NewCounters()
+	scenarioStore := configManager.GetScenarioStore()
+	pathMatcher.SetRuleGuard(matcher.AllGuards(sequenceCounters.Available, scenarioStore.Allows))
+	pathMatcher.Rebuild(configManager.GetConfig())
+
+	chaosEngine, err := chaos.NewEngine(configManager.GetChaos())
+	if err != nil {
+		log.Fatalf("Failed to set up chaos: %v", err)
+	}
+
+	resourceStore, err := resource.NewStore(configManager.GetResources())
+	if err != nil {
+		log.Fatalf("Failed to set up resources: %v", err)
+	}
+
+	// Restore the state saved by a previous run and keep saving it
+	var persister *persistence.Persister
+	stopFlushing := func() {}
+	if stateFile != "" {
+		persister = persistence.NewPersister(
+			stateFile,
+			persistence.WithScenarios(scenarioStore),
+			persistence.WithSequences(sequenceCounters),
+			persistence.WithResources(resourceStore),
+		)
+		stopFlushing = startPersistence(persister, stateFlushInterval)
+	}
+
+	// Record every request in the journal
+	requestJournal, closeJournal := newJournal(journalSize, journalFile)
+	defer closeJournal()
 
 	// Create universal handler
 	universalHandler := handler.NewUniversalHandler(
@@ -43,6 +109,12 @@ func main() {
 		pathMatcher,
 		responseBuilder,
 		appLogger,
+		handler.WithDefaultDelay(configManager.GetDefaultDelay()),
+		handler.WithChaos(chaosEngine),
+		handler.WithSequenceCounters(sequenceCounters),
+		handler.WithScenarioStore(scenarioStore),
+		handler.WithResources(resourceStore),
+		handler.WithJournal(requestJournal),
 	)
 
 	// Set up Gin router
@@ -58,6 +130,15 @@ func main() {
 		c.JSON(healthStatusCode, gin.H{"status": "healthy", "service": "mock-service"})
 	})
 
+	// Register the admin API
+	admin.NewHandler(
+		admin.WithChaos(chaosEngine),
+		admin.WithSequences(sequenceCounters),
+		admin.WithScenarios(scenarioStore),
+		admin.WithRules(configManager),
+		admin.WithJournal(requestJournal),
+	).Register(router)
+
 	// Register universal handler for all other paths and methods
 	// Note: NoRoute handles requests that don't match any registered routes
 	router.NoRoute(universalHandler.HandleRequest)
@@ -79,4 +160,50 @@ func main() {
 	// Wait for shutdown signal
 	<-quit
 	fmt.Println("\nShutting down mock service...")
+
+	// Save the final state
+	if persister != nil {
+		stopFlushing()
+		if err := persister.Flush(); err != nil {
+			log.Printf("Failed to save state: %v", err)
+		}
+	}
+}
+
+// startPersistence restores the saved state and starts saving it every interval
+// It returns a function that stops the periodic saves
+func startPersistence(persister *persistence.Persister, interval time.Duration) func() {
+	if err := persister.Load(); err != nil {
+		log.Fatalf("Failed to restore state: %v", err)
+	}
+	if interval <= 0 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go persister.Run(ctx, interval, func(err error) {
+		log.Printf("Failed to save state: %v", err)
+	})
+	return cancel
+}
+
+// newJournal creates the request journal, appending every request to file when it is set
+// It returns a function that closes the file
+func newJournal(size int, file string) (*journal.Journal, func()) {
+	if file == "" {
+		return journal.NewJournal(size), func() {}
+	}
+
+	sink, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, journalFileMode)
+	if err != nil {
+		log.Fatalf("Failed to open journal file: %v", err)
+	}
+	requestJournal := journal.NewJournal(size, journal.WithSink(sink, func(err error) {
+		log.Printf("Failed to record request: %v", err)
+	}))
+	return requestJournal, func() {
+		if err := sink.Close(); err != nil {
+			log.Printf("Failed to close journal file: %v", err)
+		}
+	}
 }
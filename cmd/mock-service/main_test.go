@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	"mock-service/internal/config"
 	"mock-service/internal/logger"
 	"mock-service/internal/matcher"
+	"mock-service/internal/models"
 	"mock-service/internal/response"
 )
 
@@ -159,21 +161,21 @@ func TestComponentsIntegration(t *testing.T) {
 
 	// Test path matching
 	rules := configManager.GetConfig()
-	rule, found := pathMatcher.FindMatch("/test/integration", rules)
+	match, found := pathMatcher.FindMatch(&models.Request{Method: "GET", Path: "/test/integration"}, rules)
 	if !found {
 		t.Fatal("Expected to find matching rule")
 	}
 
 	// Test response building
-	statusCode, body := responseBuilder.BuildResponse(rule)
+	statusCode, _, body := responseBuilder.BuildResponse(match)
 	if statusCode != 201 {
 		t.Errorf("Expected status code 201, got %d", statusCode)
 	}
 
 	// Verify response structure
-	bodyMap, ok := body.(map[string]interface{})
-	if !ok {
-		t.Fatal("Expected response body to be a map")
+	var bodyMap map[string]interface{}
+	if err := json.Unmarshal(body, &bodyMap); err != nil {
+		t.Fatalf("Expected response body to be a JSON object: %v", err)
 	}
 
 	if bodyMap["status"] != "success" {
@@ -181,19 +183,19 @@ func TestComponentsIntegration(t *testing.T) {
 	}
 
 	// Test default response
-	_, found = pathMatcher.FindMatch("/nonexistent", rules)
+	_, found = pathMatcher.FindMatch(&models.Request{Method: "GET", Path: "/nonexistent"}, rules)
 	if found {
 		t.Error("Expected no match for non-existent path")
 	}
 
-	defaultStatusCode, defaultBody := responseBuilder.BuildDefaultResponse()
+	defaultStatusCode, _, defaultBody := responseBuilder.BuildDefaultResponse()
 	if defaultStatusCode != 200 {
 		t.Errorf("Expected default status code 200, got %d", defaultStatusCode)
 	}
 
-	defaultBodyMap, ok := defaultBody.(map[string]interface{})
-	if !ok {
-		t.Fatal("Expected default response body to be a map")
+	var defaultBodyMap map[string]interface{}
+	if err := json.Unmarshal(defaultBody, &defaultBodyMap); err != nil {
+		t.Fatalf("Expected default response body to be a JSON object: %v", err)
 	}
 
 	// Should be empty JSON object
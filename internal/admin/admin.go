@@ -0,0 +1,120 @@
+// Package admin serves the runtime administration API under /__admin
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"mock-service/internal/chaos"
+	"mock-service/internal/config"
+	"mock-service/internal/interfaces"
+	"mock-service/internal/journal"
+	"mock-service/internal/sequence"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PathPrefix is the path under which the admin API is served
+const PathPrefix = config.AdminPathPrefix
+
+// Handler serves the admin API for the collaborators it is given
+// Endpoints of collaborators that are not set are not registered
+type Handler struct {
+	chaos     *chaos.Engine
+	sequences *sequence.Counters
+	scenarios interfaces.ScenarioStore
+	rules     interfaces.ConfigManager
+	journal   *journal.Journal
+}
+
+// Option configures the collaborators of Handler
+type Option func(*Handler)
+
+// WithChaos exposes the chaos engine under /__admin/chaos
+func WithChaos(engine *chaos.Engine) Option {
+	return func(h *Handler) {
+		h.chaos = engine
+	}
+}
+
+// WithSequences exposes the response sequence counters under /__admin/sequences
+func WithSequences(counters *sequence.Counters) Option {
+	return func(h *Handler) {
+		h.sequences = counters
+	}
+}
+
+// WithScenarios exposes the scenario states under /__admin/scenarios
+func WithScenarios(store interfaces.ScenarioStore) Option {
+	return func(h *Handler) {
+		h.scenarios = store
+	}
+}
+
+// WithRules exposes the rules of the configuration under /__admin/rules, to list and change them at runtime
+func WithRules(manager interfaces.ConfigManager) Option {
+	return func(h *Handler) {
+		h.rules = manager
+	}
+}
+
+// WithJournal exposes the recorded requests under /__admin/requests
+func WithJournal(j *journal.Journal) Option {
+	return func(h *Handler) {
+		h.journal = j
+	}
+}
+
+// NewHandler creates a new instance of Handler
+func NewHandler(options ...Option) *Handler {
+	h := &Handler{}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+// Register registers the admin endpoints on router
+// The whole path prefix is reserved: requests under it that no endpoint serves get 404 instead of a mock response
+func (h *Handler) Register(router gin.IRouter) {
+	router.Use(reservePrefix)
+	group := router.Group(PathPrefix)
+
+	if h.chaos != nil {
+		group.GET("/chaos", h.getChaos)
+		group.PUT("/chaos", h.updateChaos)
+	}
+	if h.sequences != nil {
+		group.GET("/sequences", h.getSequences)
+		group.POST("/sequences/reset", h.resetSequences)
+		group.POST("/sequences/:id/reset", h.resetSequence)
+	}
+	if h.scenarios != nil {
+		group.GET("/scenarios", h.getScenarios)
+		group.POST("/scenarios/reset", h.resetScenarios)
+		group.POST("/scenarios/:name/reset", h.resetScenario)
+	}
+	if h.rules != nil {
+		group.GET("/rules", h.getRules)
+		group.POST("/rules", h.createRule)
+		group.POST("/rules/reorder", h.reorderRules)
+		group.GET("/rules/:id", h.getRule)
+		group.PUT("/rules/:id", h.updateRule)
+		group.DELETE("/rules/:id", h.deleteRule)
+	}
+	if h.journal != nil {
+		group.GET("/requests", h.getRequests)
+		group.DELETE("/requests", h.clearRequests)
+	}
+}
+
+// reservePrefix answers 404 to requests under the admin path prefix that matched no admin endpoint
+// Gin runs global middleware before the NoRoute handlers too; FullPath is only empty when no route matched
+func reservePrefix(c *gin.Context) {
+	path := c.Request.URL.Path
+	if c.FullPath() == "" && (path == PathPrefix || strings.HasPrefix(path, PathPrefix+"/")) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "unknown admin endpoint " + c.Request.Method + " " + path})
+		return
+	}
+	c.Next()
+}
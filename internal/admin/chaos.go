@@ -0,0 +1,43 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// chaosUpdate is the body of PUT /__admin/chaos; fields that are not set are left unchanged
+type chaosUpdate struct {
+	Enabled    *bool    `json:"enabled"`
+	Percentage *float64 `json:"percentage"`
+	Seed       *int64   `json:"seed"`
+}
+
+// getChaos returns the current chaos profile
+func (h *Handler) getChaos(c *gin.Context) {
+	c.JSON(http.StatusOK, h.chaos.Profile())
+}
+
+// updateChaos switches chaos on or off, changes its percentage or restarts its random sequence
+func (h *Handler) updateChaos(c *gin.Context) {
+	var update chaosUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if update.Percentage != nil {
+		if err := h.chaos.SetPercentage(*update.Percentage); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if update.Enabled != nil {
+		h.chaos.SetEnabled(*update.Enabled)
+	}
+	if update.Seed != nil {
+		h.chaos.Reseed(*update.Seed)
+	}
+
+	c.JSON(http.StatusOK, h.chaos.Profile())
+}
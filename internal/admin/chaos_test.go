@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mock-service/internal/chaos"
+	"mock-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newChaosRouter returns a router serving the admin API for a chaos engine
+func newChaosRouter(t *testing.T, profile *models.ChaosConfig) (*gin.Engine, *chaos.Engine) {
+	t.Helper()
+
+	engine, err := chaos.NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(WithChaos(engine)).Register(router)
+	return router, engine
+}
+
+// serve sends a request to router and returns the recorded response
+func serve(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequestWithContext(context.Background(), method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestGetChaos tests that the current profile is returned with its seed
+func TestGetChaos(t *testing.T) {
+	seed := int64(5)
+	router, _ := newChaosRouter(t, &models.ChaosConfig{Enabled: true, Percentage: 20, Seed: &seed})
+
+	w := serve(router, "GET", "/__admin/chaos", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var profile models.ChaosConfig
+	if err := json.Unmarshal(w.Body.Bytes(), &profile); err != nil {
+		t.Fatalf("Expected a JSON profile: %v", err)
+	}
+	if !profile.Enabled || profile.Percentage != 20 || profile.Seed == nil || *profile.Seed != 5 {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+}
+
+// TestUpdateChaos tests switching chaos at runtime
+func TestUpdateChaos(t *testing.T) {
+	router, engine := newChaosRouter(t, nil)
+
+	w := serve(router, "PUT", "/__admin/chaos", `{"enabled": true, "percentage": 100, "seed": 3}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	profile := engine.Profile()
+	if !profile.Enabled || profile.Percentage != 100 || *profile.Seed != 3 {
+		t.Errorf("Expected the update to apply, got %+v", profile)
+	}
+
+	w = serve(router, "PUT", "/__admin/chaos", `{"enabled": false}`)
+	if w.Code != http.StatusOK || engine.Profile().Enabled || engine.Profile().Percentage != 100 {
+		t.Errorf("Expected only enabled to change, got %+v", engine.Profile())
+	}
+}
+
+// TestUpdateChaosInvalid tests that invalid updates are rejected without side effects
+func TestUpdateChaosInvalid(t *testing.T) {
+	router, engine := newChaosRouter(t, nil)
+
+	tests := []string{`{"percentage": 101, "enabled": true}`, `{"enabled": "yes"}`, `not json`}
+	for _, body := range tests {
+		if w := serve(router, "PUT", "/__admin/chaos", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, w.Code)
+		}
+	}
+	if engine.Profile().Enabled {
+		t.Error("Expected rejected updates to leave chaos disabled")
+	}
+}
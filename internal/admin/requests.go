@@ -0,0 +1,25 @@
+package admin
+
+import (
+	"net/http"
+
+	"mock-service/internal/journal"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getRequests returns the recorded requests accepted by the filter in the query, oldest first
+func (h *Handler) getRequests(c *gin.Context) {
+	filter, err := journal.ParseFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"requests": h.journal.Entries(filter)})
+}
+
+// clearRequests drops every recorded request
+func (h *Handler) clearRequests(c *gin.Context) {
+	h.journal.Clear()
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"mock-service/internal/journal"
+	"mock-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRequestsEndpoints tests querying and clearing the request journal
+func TestRequestsEndpoints(t *testing.T) {
+	requests := journal.NewJournal(10)
+	for _, path := range []string{"/users", "/orders", "/users"} {
+		httpReq, _ := http.NewRequestWithContext(context.Background(), "GET", path, http.NoBody)
+		req, err := models.NewRequest(httpReq)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+		requests.Record(req, time.Now(), &journal.Outcome{StatusCode: http.StatusOK})
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(WithJournal(requests)).Register(router)
+
+	w := serve(router, "GET", "/__admin/requests?path=/users", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d %s", w.Code, w.Body.String())
+	}
+	var list struct {
+		Requests []journal.Entry `json:"requests"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Invalid requests list %s: %v", w.Body.String(), err)
+	}
+	if len(list.Requests) != 2 || list.Requests[0].ID != 1 || list.Requests[1].ID != 3 {
+		t.Errorf("Expected requests 1 and 3, got %+v", list.Requests)
+	}
+
+	if w = serve(router, "GET", "/__admin/requests?limit=-1", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid filter, got %d", w.Code)
+	}
+
+	if w = serve(router, "DELETE", "/__admin/requests", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if w = serve(router, "GET", "/__admin/requests", ""); !strings.Contains(w.Body.String(), `"requests":[]`) {
+		t.Errorf("Expected no requests after clearing, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,123 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"mock-service/internal/config"
+	"mock-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rulesOrder is the body of POST /__admin/rules/reorder
+type rulesOrder struct {
+	IDs []string `json:"ids"`
+}
+
+// getRules returns every rule in matching order
+func (h *Handler) getRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"rules": h.rules.GetConfig()})
+}
+
+// getRule returns one rule
+func (h *Handler) getRule(c *gin.Context) {
+	rule, ok := h.rules.GetRule(c.Param("id"))
+	if !ok {
+		ruleError(c, config.ErrRuleNotFound, c.Param("id"))
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// createRule adds a rule at the end of the list, or at the 0-based position given in the query
+func (h *Handler) createRule(c *gin.Context) {
+	position := -1
+	if text, ok := c.GetQuery("position"); ok {
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "position must be a non-negative integer"})
+			return
+		}
+		position = n
+	}
+
+	var rule models.MockRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.rules.AddRule(rule, position)
+	if err != nil {
+		ruleError(c, err, rule.ID)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// updateRule replaces a rule; its response sequence starts over
+func (h *Handler) updateRule(c *gin.Context) {
+	var rule models.MockRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id := c.Param("id")
+	updated, err := h.rules.UpdateRule(id, rule)
+	if err != nil {
+		ruleError(c, err, id)
+		return
+	}
+	if h.sequences != nil {
+		h.sequences.Reset(id)
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// deleteRule removes a rule
+func (h *Handler) deleteRule(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.rules.DeleteRule(id); err != nil {
+		ruleError(c, err, id)
+		return
+	}
+	if h.sequences != nil {
+		h.sequences.Reset(id)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// reorderRules puts the rules in the order of the given IDs
+func (h *Handler) reorderRules(c *gin.Context) {
+	var order rulesOrder
+	if err := c.ShouldBindJSON(&order); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.rules.ReorderRules(order.IDs); err != nil {
+		ruleError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": h.rules.GetConfig()})
+}
+
+// ruleError answers a refused rule change: 404 for an unknown rule, 409 for a duplicate ID,
+// 500 when the config file could not be written and 400 for an invalid rule
+func ruleError(c *gin.Context, err error, id string) {
+	if _, ok := err.(*config.WriteBackError); ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch err {
+	case config.ErrRuleNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown rule " + id})
+	case config.ErrDuplicateRuleID:
+		c.JSON(http.StatusConflict, gin.H{"error": "rule " + id + " already exists"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
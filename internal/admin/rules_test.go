@@ -0,0 +1,180 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mock-service/internal/config"
+	"mock-service/internal/models"
+	"mock-service/internal/sequence"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRulesRouter loads the given config and serves its rules through the admin API
+func newRulesRouter(t *testing.T, content string) (*gin.Engine, *config.ConfigManagerImpl, *sequence.Counters) {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+	manager := config.NewConfigManager()
+	if err := manager.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	counters := sequence.NewCounters()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(WithRules(manager), WithSequences(counters)).Register(router)
+	return router, manager, counters
+}
+
+// listedIDs decodes a {"rules": [...]} body and returns the rule IDs in order
+func listedIDs(t *testing.T, body string) string {
+	t.Helper()
+	var list struct {
+		Rules []models.MockRule `json:"rules"`
+	}
+	if err := json.Unmarshal([]byte(body), &list); err != nil {
+		t.Fatalf("Invalid rules list %s: %v", body, err)
+	}
+	ids := make([]string, 0, len(list.Rules))
+	for _, rule := range list.Rules {
+		ids = append(ids, rule.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+// TestRulesEndpoints tests listing, getting, creating, updating and deleting rules
+func TestRulesEndpoints(t *testing.T) {
+	router, manager, _ := newRulesRouter(t, `{"rules": [{"id": "users", "path": "/users", "response": {"n": 1}}]}`)
+
+	w := serve(router, "GET", "/__admin/rules", "")
+	if w.Code != http.StatusOK || listedIDs(t, w.Body.String()) != "users" {
+		t.Errorf("Expected the configured rule, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w = serve(router, "POST", "/__admin/rules", `{"path": "/orders", "code": 201}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"id":"rule-1"`) {
+		t.Errorf("Expected a generated id, got %s", w.Body.String())
+	}
+
+	if w = serve(router, "POST", "/__admin/rules?position=0", `{"id": "top", "path": "/top"}`); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d %s", w.Code, w.Body.String())
+	}
+	if ids := listedIDs(t, serve(router, "GET", "/__admin/rules", "").Body.String()); ids != "top,users,rule-1" {
+		t.Errorf("Expected the rule to be inserted first, got %s", ids)
+	}
+
+	w = serve(router, "GET", "/__admin/rules/users", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"path":"/users"`) {
+		t.Errorf("Expected the users rule, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = serve(router, "PUT", "/__admin/rules/users", `{"path": "/users", "response": {"n": 2}}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"n":2`) {
+		t.Errorf("Expected the updated rule, got %d %s", w.Code, w.Body.String())
+	}
+	if rule, _ := manager.GetRule("users"); rule.Response["n"] != float64(2) {
+		t.Errorf("Expected the rule to be replaced, got %v", rule.Response)
+	}
+
+	if w = serve(router, "DELETE", "/__admin/rules/top", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if _, ok := manager.GetRule("top"); ok {
+		t.Error("Expected the rule to be deleted")
+	}
+}
+
+// TestRulesEndpointsErrors tests the status codes of refused rule changes
+func TestRulesEndpointsErrors(t *testing.T) {
+	router, _, _ := newRulesRouter(t, `{"rules": [{"id": "a", "path": "/a"}, {"id": "b", "path": "/b"}]}`)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"GET", "/__admin/rules/missing", "", http.StatusNotFound},
+		{"PUT", "/__admin/rules/missing", `{"path": "/x"}`, http.StatusNotFound},
+		{"DELETE", "/__admin/rules/missing", "", http.StatusNotFound},
+		{"POST", "/__admin/rules", `{"id": "a", "path": "/x"}`, http.StatusConflict},
+		{"POST", "/__admin/rules", `{"path": "(", "pathMatch": "regex"}`, http.StatusBadRequest},
+		{"POST", "/__admin/rules", `not json`, http.StatusBadRequest},
+		{"POST", "/__admin/rules", `{"path": "/x", "bodyFile": "/etc/hostname"}`, http.StatusBadRequest},
+		{"POST", "/__admin/rules", `{"path": "/x", "bodyFile": "../../etc/hostname"}`, http.StatusBadRequest},
+		{"POST", "/__admin/rules?position=-1", `{"path": "/x"}`, http.StatusBadRequest},
+		{"PUT", "/__admin/rules/a", `{"id": "b", "path": "/a"}`, http.StatusBadRequest},
+		{"POST", "/__admin/rules/reorder", `{"ids": ["a"]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := serve(router, tt.method, tt.path, tt.body)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d %s", tt.method, tt.path, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+// TestAdminPrefixReserved tests that unknown endpoints under the admin prefix never reach the mock rules
+func TestAdminPrefixReserved(t *testing.T) {
+	router, _, _ := newRulesRouter(t, `{"rules": [{"id": "a", "path": "/a"}]}`)
+	router.NoRoute(func(c *gin.Context) {
+		c.String(http.StatusOK, "mocked")
+	})
+
+	for _, target := range []struct{ method, path string }{
+		{"GET", "/__admin"},
+		{"GET", "/__admin/unknown"},
+		{"POST", "/__admin/rules/a"},
+		{"GET", "/__admin/rules/a/b"},
+	} {
+		if w := serve(router, target.method, target.path, ""); w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: expected status 404, got %d %s", target.method, target.path, w.Code, w.Body.String())
+		}
+	}
+
+	if w := serve(router, "GET", "/__administrators", ""); w.Body.String() != "mocked" {
+		t.Errorf("Expected paths outside the prefix to reach the mock, got %d %s", w.Code, w.Body.String())
+	}
+	w := serve(router, "GET", "/__admin/rules/a", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"a"`) {
+		t.Errorf("Expected admin endpoints to keep working, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestReorderRulesEndpoint tests reordering rules by ID
+func TestReorderRulesEndpoint(t *testing.T) {
+	router, _, _ := newRulesRouter(t,
+		`{"rules": [{"id": "a", "path": "/a"}, {"id": "b", "path": "/b"}, {"id": "c", "path": "/c"}]}`)
+
+	w := serve(router, "POST", "/__admin/rules/reorder", `{"ids": ["c", "a", "b"]}`)
+	if w.Code != http.StatusOK || listedIDs(t, w.Body.String()) != "c,a,b" {
+		t.Errorf("Expected the new order, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestUpdateRuleResetsSequence tests that changing a rule restarts its response sequence
+func TestUpdateRuleResetsSequence(t *testing.T) {
+	router, manager, counters := newRulesRouter(t,
+		`{"rules": [{"id": "job", "path": "/job", "responses": [{"code": 202}, {"code": 200}]}]}`)
+	rule, _ := manager.GetRule("job")
+	counters.Next(&rule)
+
+	w := serve(router, "PUT", "/__admin/rules/job", `{"path": "/job", "responses": [{"code": 202}, {"code": 201}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d %s", w.Code, w.Body.String())
+	}
+	if counts := counters.Counts(); len(counts) != 0 {
+		t.Errorf("Expected the sequence to start over, got %v", counts)
+	}
+}
@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getScenarios returns the current state of every scenario
+func (h *Handler) getScenarios(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"scenarios": h.scenarios.States()})
+}
+
+// resetScenarios moves every scenario back to its initial state
+func (h *Handler) resetScenarios(c *gin.Context) {
+	h.scenarios.ResetAll()
+	c.Status(http.StatusNoContent)
+}
+
+// resetScenario moves one scenario back to its initial state
+func (h *Handler) resetScenario(c *gin.Context) {
+	name := c.Param("name")
+	if !h.scenarios.Reset(name) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown scenario " + name})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"mock-service/internal/config"
+	"mock-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestScenariosEndpoints tests inspecting and resetting scenario states
+func TestScenariosEndpoints(t *testing.T) {
+	rules := []models.MockRule{{Path: "/cart/items", Scenario: "cart", NewState: "item-added"}}
+	store := config.NewScenarioStore()
+	store.Register(rules)
+	store.Advance(&rules[0])
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(WithScenarios(store)).Register(router)
+
+	w := serve(router, "GET", "/__admin/scenarios", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `{"name":"cart","state":"item-added"}`) {
+		t.Errorf("Expected the cart state, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w = serve(router, "POST", "/__admin/scenarios/unknown/reset", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown scenario, got %d", w.Code)
+	}
+	if w = serve(router, "POST", "/__admin/scenarios/cart/reset", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if states := store.States(); states[0].State != models.ScenarioStateStarted {
+		t.Errorf("Expected the cart to be reset, got %v", states)
+	}
+
+	store.Advance(&rules[0])
+	if w = serve(router, "POST", "/__admin/scenarios/reset", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if states := store.States(); states[0].State != models.ScenarioStateStarted {
+		t.Errorf("Expected every scenario to be reset, got %v", states)
+	}
+}
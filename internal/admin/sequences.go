@@ -0,0 +1,24 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getSequences returns the number of calls counted for each rule with a response sequence
+func (h *Handler) getSequences(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"sequences": h.sequences.Counts()})
+}
+
+// resetSequences moves every response sequence back to its start
+func (h *Handler) resetSequences(c *gin.Context) {
+	h.sequences.ResetAll()
+	c.Status(http.StatusNoContent)
+}
+
+// resetSequence moves the response sequence of one rule back to its start
+func (h *Handler) resetSequence(c *gin.Context) {
+	h.sequences.Reset(c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
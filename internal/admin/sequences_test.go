@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"mock-service/internal/models"
+	"mock-service/internal/sequence"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestSequencesEndpoints tests inspecting and resetting response sequence counters
+func TestSequencesEndpoints(t *testing.T) {
+	counters := sequence.NewCounters()
+	rules := []*models.MockRule{
+		{ID: "a", SequenceRules: []*models.MockRule{{}, {}}},
+		{ID: "b", SequenceRules: []*models.MockRule{{}, {}}},
+	}
+	for _, rule := range rules {
+		counters.Next(rule)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(WithSequences(counters)).Register(router)
+
+	w := serve(router, "GET", "/__admin/sequences", "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `{"ruleId":"a","count":1}`) {
+		t.Errorf("Expected the counts, got %d %s", w.Code, w.Body.String())
+	}
+
+	if w = serve(router, "POST", "/__admin/sequences/a/reset", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if got := counters.Counts(); len(got) != 1 || got[0].RuleID != "b" {
+		t.Errorf("Expected only rule b to keep its count, got %v", got)
+	}
+
+	if w = serve(router, "POST", "/__admin/sequences/reset", ""); w.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", w.Code)
+	}
+	if got := counters.Counts(); len(got) != 0 {
+		t.Errorf("Expected no counts after a full reset, got %v", got)
+	}
+}
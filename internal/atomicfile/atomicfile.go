@@ -0,0 +1,42 @@
+// Package atomicfile replaces files so that readers see either the old or the new content
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// defaultMode is the permission of files that did not exist before
+const defaultMode os.FileMode = 0o644
+
+// Write replaces the file at path with data through a temporary file in the same directory
+// A crash while writing leaves the previous content intact; the permissions of an existing file are kept
+func Write(path string, data []byte) error {
+	mode := defaultMode
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,48 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWrite tests creating and replacing a file
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+
+	if err := Write(path, []byte("first")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Mode().Perm() != defaultMode {
+		t.Fatalf("Expected a new file with mode %v, got %v, %v", defaultMode, info, err)
+	}
+
+	if err := os.Chmod(path, 0o600); err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+	if err := Write(path, []byte("second")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Errorf("Expected the new content, got %q, %v", data, err)
+	}
+	if info, err = os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the mode of the replaced file to be kept, got %v, %v", info, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected no temporary file to be left behind, got %v", entries)
+	}
+}
+
+// TestWriteError tests that a file in a missing directory is reported
+func TestWriteError(t *testing.T) {
+	if err := Write(filepath.Join(t.TempDir(), "missing", "data.json"), []byte("x")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,263 @@
+// Package chaos randomly disrupts matched requests according to a chaos profile
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"sync"
+	"time"
+
+	"mock-service/internal/delay"
+	"mock-service/internal/matcher"
+	"mock-service/internal/models"
+	"mock-service/internal/response"
+)
+
+// maxPercentage is the percentage at which every request is disrupted
+const maxPercentage = 100
+
+var (
+	// defaultActions are used when the profile does not list any
+	defaultActions = []string{models.ChaosActionError, models.ChaosActionDelay, models.ChaosActionFault}
+	// defaultErrorCodes are used by the error action when the profile does not list any
+	defaultErrorCodes = []int{500, 502, 503, 504}
+	// defaultFaults are used by the fault action when the profile does not list any
+	defaultFaults = []string{
+		models.FaultConnectionReset, models.FaultEmptyResponse, models.FaultGarbage,
+		models.FaultTruncatedJSON, models.FaultContentLengthMismatch,
+	}
+)
+
+// defaultDelay is added by the delay action when the profile does not set a delay
+var defaultDelay = func() *models.DelaySpec {
+	minDelay, maxDelay := models.Duration(time.Second), models.Duration(5*time.Second)
+	return &models.DelaySpec{Min: &minDelay, Max: &maxDelay}
+}()
+
+// Validate checks a chaos profile; a nil profile is valid and means no chaos
+func Validate(profile *models.ChaosConfig) error {
+	if profile == nil {
+		return nil
+	}
+
+	if err := validatePercentage(profile.Percentage); err != nil {
+		return err
+	}
+	for _, target := range profile.Paths {
+		if _, err := matcher.CompileGlob(target.Path); err != nil {
+			return fmt.Errorf("invalid chaos path: %w", err)
+		}
+		if target.Percentage != nil {
+			if err := validatePercentage(*target.Percentage); err != nil {
+				return fmt.Errorf("invalid chaos path %q: %w", target.Path, err)
+			}
+		}
+	}
+	for _, action := range profile.Actions {
+		switch action {
+		case models.ChaosActionError, models.ChaosActionDelay, models.ChaosActionFault:
+		default:
+			return fmt.Errorf("invalid chaos action %q", action)
+		}
+	}
+	for _, code := range profile.ErrorCodes {
+		if code < 500 || code > 599 {
+			return fmt.Errorf("invalid chaos error code %d: must be a 5xx status", code)
+		}
+	}
+	for _, fault := range profile.Faults {
+		if fault == "" {
+			return fmt.Errorf("invalid chaos fault: name must not be empty")
+		}
+		if err := response.ValidateFault(fault); err != nil {
+			return fmt.Errorf("invalid chaos fault: %w", err)
+		}
+	}
+	if err := delay.Validate(profile.Delay); err != nil {
+		return fmt.Errorf("invalid chaos delay: %w", err)
+	}
+	return nil
+}
+
+// ValidateRule checks the chaos settings of a rule
+func ValidateRule(rule *models.MockRule) error {
+	if rule.ChaosPercentage == nil {
+		return nil
+	}
+	if err := validatePercentage(*rule.ChaosPercentage); err != nil {
+		return fmt.Errorf("invalid chaosPercentage: %w", err)
+	}
+	return nil
+}
+
+// validatePercentage checks that a percentage lies between 0 and 100
+func validatePercentage(percentage float64) error {
+	// Written so that NaN fails too
+	if !(percentage >= 0 && percentage <= maxPercentage) {
+		return fmt.Errorf("chaos percentage must be between 0 and 100, got %v", percentage)
+	}
+	return nil
+}
+
+// compiledPath is a chaos path with its compiled glob
+type compiledPath struct {
+	pattern    *regexp.Regexp
+	percentage *float64
+}
+
+// Engine decides which requests are disrupted
+// Decisions are drawn from a seeded random sequence, so the same seed and the same
+// sequence of requests produce the same decisions. It is safe for concurrent use
+type Engine struct {
+	mu      sync.Mutex
+	profile models.ChaosConfig
+	seed    int64
+	paths   []compiledPath
+	rng     *rand.Rand
+	delays  *delay.Sampler
+}
+
+// NewEngine creates an Engine for a validated profile; a nil profile yields a disabled engine
+func NewEngine(profile *models.ChaosConfig) (*Engine, error) {
+	if err := Validate(profile); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{}
+	if profile != nil {
+		e.profile = *profile
+	}
+	for _, target := range e.profile.Paths {
+		pattern, err := matcher.CompileGlob(target.Path)
+		if err != nil {
+			return nil, err
+		}
+		e.paths = append(e.paths, compiledPath{pattern: pattern, percentage: target.Percentage})
+	}
+
+	seed := time.Now().UnixNano()
+	if e.profile.Seed != nil {
+		seed = *e.profile.Seed
+	}
+	e.reseed(seed)
+	return e, nil
+}
+
+// Decide rolls for a request matching rule and returns the decision
+// It returns nil when chaos does not apply to the request at all: chaos is disabled,
+// the path is not targeted or the percentage is 0
+func (e *Engine) Decide(rule *models.MockRule, path string) *models.ChaosDecision {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.profile.Enabled {
+		return nil
+	}
+	percentage, targeted := e.percentageFor(rule, path)
+	if !targeted || percentage <= 0 {
+		return nil
+	}
+
+	decision := &models.ChaosDecision{
+		Path:       path,
+		Rule:       rule,
+		Percentage: percentage,
+		Roll:       e.rng.Float64() * maxPercentage,
+	}
+	if decision.Roll >= percentage {
+		return decision
+	}
+
+	decision.Action = pick(e.rng, orDefault(e.profile.Actions, defaultActions))
+	switch decision.Action {
+	case models.ChaosActionError:
+		decision.StatusCode = pick(e.rng, orDefault(e.profile.ErrorCodes, defaultErrorCodes))
+	case models.ChaosActionDelay:
+		spec := e.profile.Delay
+		if spec == nil {
+			spec = defaultDelay
+		}
+		decision.Delay = e.delays.Sample(spec)
+	case models.ChaosActionFault:
+		decision.Fault = pick(e.rng, orDefault(e.profile.Faults, defaultFaults))
+	}
+	return decision
+}
+
+// percentageFor returns the percentage applying to a request and whether chaos targets it at all
+// A rule override wins over the path globs, which win over the profile percentage
+func (e *Engine) percentageFor(rule *models.MockRule, path string) (float64, bool) {
+	if rule != nil && rule.ChaosPercentage != nil {
+		return *rule.ChaosPercentage, true
+	}
+	if len(e.paths) == 0 {
+		return e.profile.Percentage, true
+	}
+	for _, target := range e.paths {
+		if target.pattern.MatchString(path) {
+			if target.percentage != nil {
+				return *target.percentage, true
+			}
+			return e.profile.Percentage, true
+		}
+	}
+	return 0, false
+}
+
+// Profile returns a copy of the current profile, with the seed in use
+func (e *Engine) Profile() models.ChaosConfig {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	profile := e.profile
+	seed := e.seed
+	profile.Seed = &seed
+	return profile
+}
+
+// SetEnabled switches chaos on or off
+func (e *Engine) SetEnabled(enabled bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.profile.Enabled = enabled
+}
+
+// SetPercentage changes the profile percentage; rule and path overrides are kept
+func (e *Engine) SetPercentage(percentage float64) error {
+	if err := validatePercentage(percentage); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.profile.Percentage = percentage
+	return nil
+}
+
+// Reseed restarts the random sequence from seed
+func (e *Engine) Reseed(seed int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.reseed(seed)
+}
+
+// reseed restarts the random sequences; the caller must hold the lock or own the engine
+func (e *Engine) reseed(seed int64) {
+	e.seed = seed
+	e.rng = rand.New(rand.NewSource(seed))
+	e.delays = delay.NewSampler(seed)
+}
+
+// orDefault returns values, or fallback when values is empty
+func orDefault[T any](values, fallback []T) []T {
+	if len(values) == 0 {
+		return fallback
+	}
+	return values
+}
+
+// pick returns a uniformly chosen element of a non-empty slice
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,208 @@
+package chaos
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"mock-service/internal/models"
+	"mock-service/internal/testutil"
+)
+
+// TestValidate tests validation of chaos profiles
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile *models.ChaosConfig
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", &models.ChaosConfig{Percentage: 25, Actions: []string{"error"}, ErrorCodes: []int{503}}, false},
+		{"negative", &models.ChaosConfig{Percentage: -1}, true},
+		{"path percentage", &models.ChaosConfig{
+			Paths: []models.ChaosPath{{Path: "/a/*", Percentage: testutil.Ptr[float64](101)}},
+		}, true},
+		{"action", &models.ChaosConfig{Actions: []string{"panic"}}, true},
+		{"error code", &models.ChaosConfig{ErrorCodes: []int{200}}, true},
+		{"empty fault", &models.ChaosConfig{Faults: []string{""}}, true},
+		{"delay", &models.ChaosConfig{Delay: &models.DelaySpec{}}, true},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.profile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestValidateRule tests validation of the per-rule chaos percentage
+func TestValidateRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage *float64
+		wantErr    bool
+	}{
+		{"unset", nil, false},
+		{"exempt", testutil.Ptr[float64](0), false},
+		{"fraction", testutil.Ptr[float64](12.5), false},
+		{"always", testutil.Ptr[float64](100), false},
+		{"negative", testutil.Ptr[float64](-5), true},
+		{"above 100", testutil.Ptr[float64](250), true},
+		{"NaN", testutil.Ptr[float64](math.NaN()), true},
+	}
+
+	for _, tt := range tests {
+		err := ValidateRule(&models.MockRule{Path: "/a", ChaosPercentage: tt.percentage})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidateRule() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// decisions returns the decisions of a fresh engine for n requests to path
+func decisions(t *testing.T, profile *models.ChaosConfig, rule *models.MockRule, path string, n int) []*models.ChaosDecision {
+	t.Helper()
+
+	engine, err := NewEngine(profile)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	result := make([]*models.ChaosDecision, 0, n)
+	for i := 0; i < n; i++ {
+		result = append(result, engine.Decide(rule, path))
+	}
+	return result
+}
+
+// TestDecideIsReproducible tests that the same seed yields the same decisions
+func TestDecideIsReproducible(t *testing.T) {
+	profile := &models.ChaosConfig{Enabled: true, Percentage: 50, Seed: testutil.Ptr[int64](42)}
+	rule := &models.MockRule{Path: "/api/users"}
+
+	first := decisions(t, profile, rule, "/api/users", 50)
+	second := decisions(t, profile, rule, "/api/users", 50)
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Expected the same seed to produce the same decisions")
+	}
+
+	injected := 0
+	for _, decision := range first {
+		if decision.Action != "" {
+			injected++
+		}
+	}
+	if injected == 0 || injected == len(first) {
+		t.Errorf("Expected about half of the requests to be disrupted, got %d of %d", injected, len(first))
+	}
+}
+
+// TestDecideActions tests that injected decisions carry the details of their action
+func TestDecideActions(t *testing.T) {
+	profile := &models.ChaosConfig{
+		Enabled:    true,
+		Percentage: 100,
+		Seed:       testutil.Ptr[int64](1),
+		ErrorCodes: []int{503},
+		Faults:     []string{models.FaultGarbage},
+		Delay:      &models.DelaySpec{Fixed: testutil.Ptr(models.Duration(7 * time.Millisecond))},
+	}
+
+	for _, decision := range decisions(t, profile, &models.MockRule{}, "/a", 30) {
+		switch decision.Action {
+		case models.ChaosActionError:
+			if decision.StatusCode != 503 {
+				t.Errorf("Expected status 503, got %d", decision.StatusCode)
+			}
+		case models.ChaosActionDelay:
+			if decision.Delay != 7*time.Millisecond {
+				t.Errorf("Expected a 7ms delay, got %v", decision.Delay)
+			}
+		case models.ChaosActionFault:
+			if decision.Fault != models.FaultGarbage {
+				t.Errorf("Expected the garbage fault, got %q", decision.Fault)
+			}
+		default:
+			t.Errorf("Expected every request to be disrupted, got %+v", decision)
+		}
+	}
+}
+
+// TestDecideTargeting tests the precedence of rule overrides, path globs and the profile percentage
+func TestDecideTargeting(t *testing.T) {
+	profile := &models.ChaosConfig{
+		Enabled:    true,
+		Percentage: 100,
+		Paths: []models.ChaosPath{
+			{Path: "/api/health/**", Percentage: testutil.Ptr[float64](0)},
+			{Path: "/api/**"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		rule     *models.MockRule
+		path     string
+		wantNil  bool
+		injected bool
+	}{
+		{"profile percentage", &models.MockRule{}, "/api/users", false, true},
+		{"path percentage", &models.MockRule{}, "/api/health/live", true, false},
+		{"untargeted path", &models.MockRule{}, "/static/app.js", true, false},
+		{"rule exempt", &models.MockRule{ChaosPercentage: testutil.Ptr[float64](0)}, "/api/users", true, false},
+		{"rule override", &models.MockRule{ChaosPercentage: testutil.Ptr[float64](100)}, "/static/app.js", false, true},
+	}
+
+	for _, tt := range tests {
+		decision := decisions(t, profile, tt.rule, tt.path, 1)[0]
+		if (decision == nil) != tt.wantNil {
+			t.Errorf("%s: expected nil decision %v, got %+v", tt.name, tt.wantNil, decision)
+			continue
+		}
+		if decision != nil && (decision.Action != "") != tt.injected {
+			t.Errorf("%s: expected injected %v, got %+v", tt.name, tt.injected, decision)
+		}
+	}
+}
+
+// TestEngineRuntimeControl tests switching chaos on and off, changing the percentage and reseeding
+func TestEngineRuntimeControl(t *testing.T) {
+	engine, err := NewEngine(nil)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	rule := &models.MockRule{}
+
+	if decision := engine.Decide(rule, "/a"); decision != nil {
+		t.Errorf("Expected no chaos without a profile, got %+v", decision)
+	}
+
+	engine.SetEnabled(true)
+	if err := engine.SetPercentage(100); err != nil {
+		t.Fatalf("SetPercentage() error = %v", err)
+	}
+	if decision := engine.Decide(rule, "/a"); decision == nil || decision.Action == "" {
+		t.Errorf("Expected chaos after enabling it, got %+v", decision)
+	}
+	if err := engine.SetPercentage(200); err == nil {
+		t.Error("Expected an error for a percentage above 100")
+	}
+
+	engine.Reseed(9)
+	first := engine.Decide(rule, "/a")
+	engine.Reseed(9)
+	if second := engine.Decide(rule, "/a"); !reflect.DeepEqual(first, second) {
+		t.Errorf("Expected reseeding to restart the sequence, got %+v and %+v", first, second)
+	}
+
+	profile := engine.Profile()
+	if !profile.Enabled || profile.Percentage != 100 || profile.Seed == nil || *profile.Seed != 9 {
+		t.Errorf("Expected the profile to reflect the runtime changes, got %+v", profile)
+	}
+
+	engine.SetEnabled(false)
+	if decision := engine.Decide(rule, "/a"); decision != nil {
+		t.Errorf("Expected no chaos after disabling it, got %+v", decision)
+	}
+}
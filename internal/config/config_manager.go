@@ -4,25 +4,45 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
+	"mock-service/internal/chaos"
+	"mock-service/internal/delay"
+	"mock-service/internal/matcher"
 	"mock-service/internal/models"
+	"mock-service/internal/resource"
+	"mock-service/internal/response"
 )
 
+// AdminPathPrefix is the path prefix reserved for the admin API; rule paths may not start with it
+const AdminPathPrefix = "/__admin"
+
 // ConfigManagerImpl implements the ConfigManager interface
 // It handles loading and managing JSON configuration files
 type ConfigManagerImpl struct {
-	config models.Config
+	// mu guards config.Rules, which rule changes replace copy-on-write so callers of GetConfig keep a consistent list
+	mu        sync.RWMutex
+	config    models.Config
+	filePath  string
+	writeBack bool
+	scenarios *ScenarioStoreImpl
+	// lastRuleNumber is the highest N of the "rule-N" IDs in use since loading; generated IDs only go up from it
+	lastRuleNumber int
 }
 
 // NewConfigManager creates a new instance of ConfigManager
 func NewConfigManager() *ConfigManagerImpl {
 	return &ConfigManagerImpl{
-		config: models.Config{Rules: []models.MockRule{}},
+		config:    models.Config{Rules: []models.MockRule{}},
+		scenarios: NewScenarioStore(),
 	}
 }
 
 // LoadConfig loads configuration from the specified file path
-// Returns error if file cannot be read or JSON is invalid
+// Returns error if file cannot be read, JSON is invalid or a rule cannot be compiled
 func (cm *ConfigManagerImpl) LoadConfig(filePath string) error {
 	// Read the configuration file
 	data, err := os.ReadFile(filePath)
@@ -36,12 +56,122 @@ func (cm *ConfigManagerImpl) LoadConfig(filePath string) error {
 		return fmt.Errorf("failed to parse JSON config file %s: %w", filePath, err)
 	}
 
-	// Store the loaded configuration
+	// Validate the selection strategy
+	switch config.Selection {
+	case "", models.SelectionFirst, models.SelectionPriority, models.SelectionMostSpecific:
+	default:
+		return fmt.Errorf("invalid selection strategy %q in config file %s", config.Selection, filePath)
+	}
+
+	// Validate the default delay
+	if err := delay.Validate(config.DefaultDelay); err != nil {
+		return fmt.Errorf("invalid defaultDelay in config file %s: %w", filePath, err)
+	}
+
+	// Validate the chaos profile
+	if err := chaos.Validate(config.Chaos); err != nil {
+		return fmt.Errorf("invalid chaos profile in config file %s: %w", filePath, err)
+	}
+
+	// Validate the in-memory resources
+	if err := resource.Validate(config.Resources); err != nil {
+		return fmt.Errorf("invalid resources in config file %s: %w", filePath, err)
+	}
+
+	// Validate rules and precompile their path patterns and responses
+	ids := make(map[string]bool, len(config.Rules))
+	for i := range config.Rules {
+		if config.Rules[i].ID == "" {
+			config.Rules[i].ID = ruleIDPrefix + strconv.Itoa(i+1)
+		}
+		if ids[config.Rules[i].ID] {
+			return fmt.Errorf("invalid rule #%d (%s) in config file %s: duplicate id %q",
+				i+1, config.Rules[i].Path, filePath, config.Rules[i].ID)
+		}
+		ids[config.Rules[i].ID] = true
+
+		if err := compileRule(&config.Rules[i], filepath.Dir(filePath)); err != nil {
+			return fmt.Errorf("invalid rule #%d (%s) in config file %s: %w", i+1, config.Rules[i].Path, filePath, err)
+		}
+	}
+
+	// Store the loaded configuration; every scenario starts over
+	cm.mu.Lock()
 	cm.config = config
+	cm.filePath = filePath
+	cm.lastRuleNumber = 0
+	for i := range config.Rules {
+		cm.noteRuleID(config.Rules[i].ID)
+	}
+	cm.mu.Unlock()
+	cm.scenarios.Register(config.Rules)
 	return nil
 }
 
+// compileRule validates a rule and precompiles its path pattern and response
+// A relative bodyFile is resolved against baseDir, the directory of the config file
+func compileRule(rule *models.MockRule, baseDir string) error {
+	if isAdminPath(strings.TrimPrefix(rule.Path, "^")) {
+		return fmt.Errorf("path %q is reserved for the admin API", rule.Path)
+	}
+	if err := matcher.CompileRule(rule); err != nil {
+		return err
+	}
+	if err := response.CompileResponse(rule, baseDir); err != nil {
+		return err
+	}
+	if err := delay.Validate(rule.Delay); err != nil {
+		return err
+	}
+	if err := chaos.ValidateRule(rule); err != nil {
+		return err
+	}
+	if rule.Scenario == "" && (rule.RequiredState != "" || rule.NewState != "") {
+		return fmt.Errorf("requiredState and newState require a scenario")
+	}
+	return nil
+}
+
+// isAdminPath reports whether path is the admin path prefix or lies under it
+func isAdminPath(path string) bool {
+	return path == AdminPathPrefix || strings.HasPrefix(path, AdminPathPrefix+"/")
+}
+
 // GetConfig returns the current list of mock rules
+// The returned slice is never modified: rule changes publish a new one
 func (cm *ConfigManagerImpl) GetConfig() []models.MockRule {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
 	return cm.config.Rules
 }
+
+// GetSelectionStrategy returns the configured rule selection strategy
+// Defaults to first-match when the configuration does not set one
+func (cm *ConfigManagerImpl) GetSelectionStrategy() string {
+	if cm.config.Selection == "" {
+		return models.SelectionFirst
+	}
+	return cm.config.Selection
+}
+
+// GetDefaultDelay returns the delay applied to responses whose rule does not set one
+// Returns nil when the configuration does not set a default delay
+func (cm *ConfigManagerImpl) GetDefaultDelay() *models.DelaySpec {
+	return cm.config.DefaultDelay
+}
+
+// GetChaos returns the chaos profile of the configuration
+// Returns nil when the configuration does not set one
+func (cm *ConfigManagerImpl) GetChaos() *models.ChaosConfig {
+	return cm.config.Chaos
+}
+
+// GetResources returns the in-memory REST collections declared by the configuration
+func (cm *ConfigManagerImpl) GetResources() []models.ResourceConfig {
+	return cm.config.Resources
+}
+
+// GetScenarioStore returns the store holding the state of the scenarios declared by the rules
+func (cm *ConfigManagerImpl) GetScenarioStore() *ScenarioStoreImpl {
+	return cm.scenarios
+}
@@ -3,7 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // TestNewConfigManager tests the creation of a new ConfigManager instance
@@ -164,3 +166,723 @@ func TestGetConfigAfterLoad(t *testing.T) {
 		t.Errorf("Expected path '/test', got '%s'", rules[0].Path)
 	}
 }
+
+// TestLoadConfigMethodField tests that the method field accepts a string or a list
+func TestLoadConfigMethodField(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.json")
+
+	configContent := `{
+		"rules": [
+			{"path": "/api/users", "method": "get", "response": {}, "code": 200},
+			{"path": "/api/users", "method": ["PUT", "patch"], "response": {}, "code": 200},
+			{"path": "/api/users", "response": {}, "code": 200}
+		]
+	}`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	rules := cm.GetConfig()
+	if len(rules[0].Method) != 1 || rules[0].Method[0] != "GET" {
+		t.Errorf("Expected method [GET], got %v", rules[0].Method)
+	}
+	if len(rules[1].Method) != 2 || rules[1].Method[0] != "PUT" || rules[1].Method[1] != "PATCH" {
+		t.Errorf("Expected method [PUT PATCH], got %v", rules[1].Method)
+	}
+	if len(rules[2].Method) != 0 {
+		t.Errorf("Expected no method restriction, got %v", rules[2].Method)
+	}
+}
+
+// TestLoadConfigInvalidMethodField tests that a malformed method field is rejected
+func TestLoadConfigInvalidMethodField(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.json")
+
+	configContent := `{"rules": [{"path": "/api/users", "method": 42, "response": {}}]}`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err == nil {
+		t.Error("LoadConfig should return error for a numeric method field")
+	}
+}
+
+// TestLoadConfigCompilesPathPatterns tests that glob and regex paths are compiled at load time
+func TestLoadConfigCompilesPathPatterns(t *testing.T) {
+	tempDir := t.TempDir()
+	configFile := filepath.Join(tempDir, "config.json")
+
+	configContent := `{
+		"rules": [
+			{"path": "/static/**", "pathMatch": "glob", "response": {}},
+			{"path": "^/v[0-9]+/items/\\d+$", "pathMatch": "regex", "response": {}},
+			{"path": "/api/", "pathMatch": "prefix", "response": {}}
+		]
+	}`
+
+	err := os.WriteFile(configFile, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	rules := cm.GetConfig()
+	if rules[0].PathPattern == nil {
+		t.Error("Expected glob path to be compiled")
+	}
+	if rules[1].PathPattern == nil {
+		t.Error("Expected regex path to be compiled")
+	}
+	if rules[2].PathPattern != nil {
+		t.Error("Expected prefix path not to be compiled")
+	}
+}
+
+// TestLoadConfigInvalidPathPattern tests that broken patterns fail at load time
+func TestLoadConfigInvalidPathPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid regex", `{"rules": [{"path": "/items/(\\d+", "pathMatch": "regex", "response": {}}]}`},
+		{"unknown mode", `{"rules": [{"path": "/items", "pathMatch": "fuzzy", "response": {}}]}`},
+		{"admin path", `{"rules": [{"path": "/__admin/chaos", "response": {}}]}`},
+		{"admin glob", `{"rules": [{"path": "/__admin/**", "pathMatch": "glob", "response": {}}]}`},
+		{"admin regex", `{"rules": [{"path": "^/__admin/.*", "pathMatch": "regex", "response": {}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config file: %v", err)
+			}
+
+			cm := NewConfigManager()
+			if err := cm.LoadConfig(configFile); err == nil {
+				t.Error("LoadConfig should return error for an invalid path pattern")
+			}
+
+			if len(cm.GetConfig()) != 0 {
+				t.Error("Failed load should not replace the current configuration")
+			}
+		})
+	}
+}
+
+// TestLoadConfigQueryMatchers tests parsing of query matchers, including the string shorthand
+func TestLoadConfigQueryMatchers(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{
+		"rules": [
+			{
+				"path": "/api/users",
+				"query": {
+					"status": "active",
+					"page": {"regex": "^[0-9]+$"},
+					"debug": {"absent": true},
+					"filter": "",
+					"sort": {"equals": ""}
+				},
+				"response": {}
+			}
+		]
+	}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	query := cm.GetConfig()[0].Query
+	if equals := query["status"].Equals; equals == nil || *equals != "active" {
+		t.Errorf("Expected status shorthand to mean equals 'active', got %+v", query["status"])
+	}
+	if query["page"].Pattern == nil {
+		t.Error("Expected page regex to be compiled")
+	}
+	for _, name := range []string{"filter", "sort"} {
+		if equals := query[name].Equals; equals == nil || *equals != "" {
+			t.Errorf("Expected %s to require an empty value, got %+v", name, query[name])
+		}
+	}
+	if !query["debug"].Absent {
+		t.Error("Expected debug matcher to require absence")
+	}
+}
+
+// TestLoadConfigInvalidQueryRegex tests that a broken query regex fails at load time
+func TestLoadConfigInvalidQueryRegex(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{"rules": [{"path": "/api/users", "query": {"id": {"regex": "[0-9"}}, "response": {}}]}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err == nil {
+		t.Error("LoadConfig should return error for an invalid query regex")
+	}
+}
+
+// TestLoadConfigHeaderMatchers tests parsing and compilation of header matchers
+func TestLoadConfigHeaderMatchers(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{
+		"rules": [
+			{
+				"path": "/api/users",
+				"headers": {
+					"Accept": {"contains": "json"},
+					"X-Api-Version": {"regex": "^2"},
+					"X-Tenant": "acme"
+				},
+				"response": {}
+			}
+		]
+	}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	headers := cm.GetConfig()[0].Headers
+	if headers["Accept"].Contains != "json" {
+		t.Errorf("Expected Accept to contain 'json', got %+v", headers["Accept"])
+	}
+	if headers["X-Api-Version"].Pattern == nil {
+		t.Error("Expected X-Api-Version regex to be compiled")
+	}
+	if equals := headers["X-Tenant"].Equals; equals == nil || *equals != "acme" {
+		t.Errorf("Expected X-Tenant to equal 'acme', got %+v", headers["X-Tenant"])
+	}
+}
+
+// TestLoadConfigBodyMatcher tests parsing and compilation of body matchers
+func TestLoadConfigBodyMatcher(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{
+		"rules": [
+			{
+				"path": "/api/orders",
+				"body": {
+					"jsonPath": {
+						"$.user.type": "admin",
+						"$.items.length()": {"gt": 3}
+					},
+					"equalToJson": {"currency": "EUR"},
+					"ignoreExtraFields": true
+				},
+				"response": {}
+			}
+		]
+	}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	body := cm.GetConfig()[0].Body
+	if body == nil {
+		t.Fatal("Expected body matcher to be parsed")
+	}
+	if len(body.CompiledJSONPath) != 2 {
+		t.Errorf("Expected 2 compiled JSONPath expressions, got %d", len(body.CompiledJSONPath))
+	}
+	if gt := body.JSONPath["$.items.length()"].GreaterThan; gt == nil || *gt != 3 {
+		t.Errorf("Expected gt 3, got %v", gt)
+	}
+	if !body.IgnoreExtraFields {
+		t.Error("Expected ignoreExtraFields to be set")
+	}
+}
+
+// TestLoadConfigInvalidJSONPath tests that a malformed JSONPath fails at load time
+func TestLoadConfigInvalidJSONPath(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{"rules": [{"path": "/api/orders", "body": {"jsonPath": {"$.items[": {"present": true}}}, "response": {}}]}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err == nil {
+		t.Error("LoadConfig should return error for an invalid JSONPath expression")
+	}
+}
+
+// TestLoadConfigFormAndXMLMatchers tests parsing of form, multipart and XPath body matchers
+func TestLoadConfigFormAndXMLMatchers(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{
+		"rules": [
+			{
+				"path": "/soap",
+				"body": {"xpath": {"//GetUser/UserId": "42", "count(//Item)": {"gt": 1}}},
+				"response": {}
+			},
+			{
+				"path": "/upload",
+				"body": {
+					"form": {"title": {"present": true}},
+					"multipart": [{"name": "file", "filename": {"regex": "\\.pdf$"}}]
+				},
+				"response": {}
+			}
+		]
+	}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	rules := cm.GetConfig()
+	if len(rules[0].Body.CompiledXPath) != 2 {
+		t.Errorf("Expected 2 compiled XPath expressions, got %d", len(rules[0].Body.CompiledXPath))
+	}
+	multipart := rules[1].Body.Multipart
+	if len(multipart) != 1 || multipart[0].Filename == nil || multipart[0].Filename.Pattern == nil {
+		t.Errorf("Expected compiled multipart filename matcher, got %+v", multipart)
+	}
+}
+
+// TestLoadConfigInvalidXPath tests that a malformed XPath fails at load time
+func TestLoadConfigInvalidXPath(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{"rules": [{"path": "/soap", "body": {"xpath": {"GetUser/UserId": "42"}}, "response": {}}]}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err == nil {
+		t.Error("LoadConfig should return error for a relative XPath expression")
+	}
+}
+
+// TestLoadConfigSelectionStrategy tests parsing and validation of the selection strategy
+func TestLoadConfigSelectionStrategy(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{`{"rules": []}`, "first", false},
+		{`{"selection": "priority", "rules": [{"path": "/a", "priority": 3, "response": {}}]}`, "priority", false},
+		{`{"selection": "most-specific", "rules": []}`, "most-specific", false},
+		{`{"selection": "random", "rules": []}`, "", true},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected LoadConfig to fail for %s", tt.content)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("LoadConfig failed for %s: %v", tt.content, err)
+		}
+
+		if got := cm.GetSelectionStrategy(); got != tt.expected {
+			t.Errorf("Expected strategy %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+// TestLoadConfigResponseHeaders tests parsing of single and multi-value response headers
+func TestLoadConfigResponseHeaders(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{"rules": [{
+		"path": "/api/login",
+		"responseHeaders": {"Location": "/home", "Set-Cookie": ["a=1", "b=2"]},
+		"response": {}
+	}]}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+
+	headers := cm.GetConfig()[0].ResponseHeaders
+	if got := strings.Join(headers["Location"], ","); got != "/home" {
+		t.Errorf("Expected Location '/home', got '%s'", got)
+	}
+	if got := strings.Join(headers["Set-Cookie"], ","); got != "a=1,b=2" {
+		t.Errorf("Expected Set-Cookie 'a=1,b=2', got '%s'", got)
+	}
+}
+
+// TestLoadConfigInvalidResponseHeader tests that invalid response header names are rejected
+func TestLoadConfigInvalidResponseHeader(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+
+	configContent := `{"rules": [{"path": "/api/users", "responseHeaders": {"Bad Header": "x"}, "response": {}}]}`
+
+	if err := os.WriteFile(configFile, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err == nil {
+		t.Error("LoadConfig should return error for an invalid response header name")
+	}
+}
+
+// TestLoadConfigAlternativeBodies tests parsing of bodyJson, bodyText and bodyBase64 and rejection of conflicts
+func TestLoadConfigAlternativeBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr bool
+	}{
+		{"json array", `{"path": "/a", "bodyJson": [1, 2]}`, false},
+		{"json null", `{"path": "/a", "bodyJson": null}`, false},
+		{"text", `{"path": "/a", "bodyText": "<ok/>", "contentType": "application/xml"}`, false},
+		{"base64", `{"path": "/a", "bodyBase64": "aGVsbG8="}`, false},
+		{"invalid base64", `{"path": "/a", "bodyBase64": "%%%"}`, true},
+		{"conflicting bodies", `{"path": "/a", "bodyText": "x", "response": {}}`, true},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(`{"rules": [`+tt.rule+`]}`), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestLoadConfigBodyFile tests that bodyFile is resolved relative to the config file, must stay in its directory and must exist
+func TestLoadConfigBodyFile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	fixture := filepath.Join(dir, "users.json")
+
+	if err := os.WriteFile(fixture, []byte(`[]`), 0644); err != nil {
+		t.Fatalf("Failed to create fixture: %v", err)
+	}
+	content := `{"rules": [{"path": "/api/users", "bodyFile": "users.json"}]}`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if got := cm.GetConfig()[0].BodyFilePath; got != fixture {
+		t.Errorf("Expected resolved body file %s, got %s", fixture, got)
+	}
+
+	for _, bodyFile := range []string{"missing.json", fixture, "../" + filepath.Base(dir) + "/users.json"} {
+		content = `{"rules": [{"path": "/api/users", "bodyFile": "` + filepath.ToSlash(bodyFile) + `"}]}`
+		if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+		if err := cm.LoadConfig(configFile); err == nil {
+			t.Errorf("LoadConfig should return error for body file %q", bodyFile)
+		}
+	}
+}
+
+// TestLoadConfigTemplates tests that response templates are parsed when the configuration loads
+func TestLoadConfigTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr bool
+	}{
+		{"valid template", `{"path": "/a/{id}", "template": true, "response": {"id": "{{.params.id}}"}}`, false},
+		{"broken template", `{"path": "/a/{id}", "template": true, "response": {"id": "{{.params.id"}}`, true},
+		{"template not enabled", `{"path": "/a/{id}", "response": {"id": "{{.params.id"}}`, false},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(`{"rules": [`+tt.rule+`]}`), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestLoadConfigDelays tests parsing and validation of rule and default delays
+func TestLoadConfigDelays(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"shorthand", `{"defaultDelay": "50ms", "rules": [{"path": "/a", "delay": 250}]}`, false},
+		{"uniform", `{"rules": [{"path": "/a", "delay": {"min": "100ms", "max": "1s"}}]}`, false},
+		{"lognormal", `{"rules": [{"path": "/a", "delay": {"p50": "80ms", "p99": "2s"}}]}`, false},
+		{"invalid duration", `{"rules": [{"path": "/a", "delay": "soon"}]}`, true},
+		{"mixed forms", `{"rules": [{"path": "/a", "delay": {"fixed": "1s", "min": "1s", "max": "2s"}}]}`, true},
+		{"invalid default", `{"defaultDelay": {"p50": "1s"}, "rules": []}`, true},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.name == "shorthand" {
+			if got := cm.GetDefaultDelay(); got == nil || got.Fixed == nil || time.Duration(*got.Fixed) != 50*time.Millisecond {
+				t.Errorf("Expected a 50ms default delay, got %+v", got)
+			}
+			if got := cm.GetConfig()[0].Delay; got == nil || time.Duration(*got.Fixed) != 250*time.Millisecond {
+				t.Errorf("Expected a 250ms rule delay, got %+v", got)
+			}
+		}
+	}
+}
+
+// TestLoadConfigChaos tests parsing and validation of the chaos profile
+func TestLoadConfigChaos(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"profile", `{"chaos": {"enabled": true, "percentage": 10, "seed": 7, "paths": [{"path": "/api/**"}]}, "rules": []}`,
+			false},
+		{"rule override", `{"rules": [{"path": "/a", "chaosPercentage": 0}]}`, false},
+		{"rule percentage negative", `{"rules": [{"path": "/a", "chaosPercentage": -5}]}`, true},
+		{"rule percentage too high", `{"rules": [{"path": "/a", "chaosPercentage": 250}]}`, true},
+		{"percentage too high", `{"chaos": {"percentage": 150}, "rules": []}`, true},
+		{"unknown action", `{"chaos": {"percentage": 10, "actions": ["explode"]}, "rules": []}`, true},
+		{"non-5xx code", `{"chaos": {"percentage": 10, "errorCodes": [404]}, "rules": []}`, true},
+		{"unknown fault", `{"chaos": {"percentage": 10, "faults": ["timeout"]}, "rules": []}`, true},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.name == "profile" {
+			if got := cm.GetChaos(); got == nil || !got.Enabled || got.Percentage != 10 || got.Seed == nil || *got.Seed != 7 {
+				t.Errorf("Expected the chaos profile to be loaded, got %+v", got)
+			}
+		}
+	}
+}
+
+// TestLoadConfigVariants tests that response variants are compiled when the configuration loads
+func TestLoadConfigVariants(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	content := `{"rules": [{
+		"path": "/api/flaky",
+		"response": {"ok": true},
+		"variants": [{"weight": 9}, {"weight": 1, "code": 503, "response": {"error": "unavailable"}}]
+	}]}`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig should succeed, got error: %v", err)
+	}
+
+	rule := cm.GetConfig()[0]
+	if len(rule.VariantRules) != 2 || rule.VariantPicker == nil {
+		t.Fatalf("Expected two compiled variants and a picker, got %+v", rule.VariantRules)
+	}
+	if rule.VariantRules[1].Code != 503 || rule.VariantRules[0].Response["ok"] != true {
+		t.Errorf("Expected the variants to be merged with the rule, got %+v and %+v", rule.VariantRules[0], rule.VariantRules[1])
+	}
+}
+
+// TestLoadConfigRuleIDs tests that rule ids default to their position and must be unique
+func TestLoadConfigRuleIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantIDs string
+		wantErr bool
+	}{
+		{
+			"defaults", `{"rules": [{"path": "/a"}, {"id": "orders", "path": "/b"}, {"path": "/c"}]}`,
+			"rule-1,orders,rule-3", false,
+		},
+		{"duplicate", `{"rules": [{"id": "x", "path": "/a"}, {"id": "x", "path": "/b"}]}`, "", true},
+		{"clash with default", `{"rules": [{"path": "/a"}, {"id": "rule-1", "path": "/b"}]}`, "", true},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		ids := make([]string, 0, len(cm.GetConfig()))
+		for _, rule := range cm.GetConfig() {
+			ids = append(ids, rule.ID)
+		}
+		if got := strings.Join(ids, ","); got != tt.wantIDs {
+			t.Errorf("%s: expected ids %s, got %s", tt.name, tt.wantIDs, got)
+		}
+	}
+}
+
+// TestLoadConfigScenarios tests that scenarios are registered and validated when the configuration loads
+func TestLoadConfigScenarios(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"scenario", `{"rules": [{"path": "/cart", "scenario": "cart", "requiredState": "Started", "newState": "open"}]}`, false},
+		{"state without scenario", `{"rules": [{"path": "/cart", "newState": "open"}]}`, true},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil {
+			states := cm.GetScenarioStore().States()
+			if len(states) != 1 || states[0].Name != "cart" || states[0].State != "Started" {
+				t.Errorf("%s: expected the cart scenario to be registered, got %v", tt.name, states)
+			}
+		}
+	}
+}
+
+// TestLoadConfigResources tests parsing and validation of the in-memory resources
+func TestLoadConfigResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"resources", `{"resources": [{"name": "users", "basePath": "/api/users", "seed": [{"id": 1}]}], "rules": []}`, false},
+		{"missing name", `{"resources": [{"basePath": "/api/users"}], "rules": []}`, true},
+		{"invalid base path", `{"resources": [{"name": "users", "basePath": "users"}], "rules": []}`, true},
+		{
+			"duplicate seed id",
+			`{"resources": [{"name": "u", "basePath": "/u", "seed": [{"id": 1}, {"id": 1}]}], "rules": []}`, true,
+		},
+	}
+
+	for _, tt := range tests {
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(configFile, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test config file: %v", err)
+		}
+
+		cm := NewConfigManager()
+		err := cm.LoadConfig(configFile)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: LoadConfig error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.name == "resources" {
+			if got := cm.GetResources(); len(got) != 1 || got[0].Name != "users" || len(got[0].Seed) != 1 {
+				t.Errorf("Expected the resources to be loaded, got %+v", got)
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"mock-service/internal/atomicfile"
+	"mock-service/internal/models"
+)
+
+// ruleIDPrefix starts the IDs given to rules that do not set one
+const ruleIDPrefix = "rule-"
+
+var (
+	// ErrRuleNotFound is returned by rule changes addressing an unknown rule ID
+	ErrRuleNotFound = fmt.Errorf("rule not found")
+	// ErrDuplicateRuleID is returned when a new rule uses the ID of an existing rule
+	ErrDuplicateRuleID = fmt.Errorf("duplicate rule id")
+)
+
+// WriteBackError reports that a rule change could not be written to the config file
+// The change is not applied
+type WriteBackError struct {
+	Err error
+}
+
+// Error returns the message of the underlying error
+func (e *WriteBackError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *WriteBackError) Unwrap() error {
+	return e.Err
+}
+
+// EnableWriteBack makes every rule change rewrite the config file the rules were loaded from
+func (cm *ConfigManagerImpl) EnableWriteBack() {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cm.writeBack = true
+}
+
+// GetRule returns the rule with the given ID
+func (cm *ConfigManagerImpl) GetRule(id string) (models.MockRule, bool) {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	if i := indexOfRule(cm.config.Rules, id); i >= 0 {
+		return cm.config.Rules[i], true
+	}
+	return models.MockRule{}, false
+}
+
+// AddRule validates a rule and inserts it at position, or appends it when position is out of range
+// A rule without an ID gets a "rule-N" ID above every one used since loading, so the ID of a deleted rule
+// is never handed out again; returns the compiled rule
+func (cm *ConfigManagerImpl) AddRule(rule models.MockRule, position int) (models.MockRule, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	current := cm.config.Rules
+	if rule.ID == "" {
+		rule.ID = cm.nextRuleID(current)
+	} else if indexOfRule(current, rule.ID) >= 0 {
+		return models.MockRule{}, ErrDuplicateRuleID
+	}
+	if err := compileRule(&rule, filepath.Dir(cm.filePath)); err != nil {
+		return models.MockRule{}, err
+	}
+
+	if position < 0 || position > len(current) {
+		position = len(current)
+	}
+	rules := make([]models.MockRule, 0, len(current)+1)
+	rules = append(rules, current[:position]...)
+	rules = append(rules, rule)
+	rules = append(rules, current[position:]...)
+
+	if err := cm.publish(rules); err != nil {
+		return models.MockRule{}, err
+	}
+	cm.noteRuleID(rule.ID)
+	return rule, nil
+}
+
+// UpdateRule replaces the rule with the given ID, keeping its position
+// The new rule may omit the ID but cannot change it; returns the compiled rule
+func (cm *ConfigManagerImpl) UpdateRule(id string, rule models.MockRule) (models.MockRule, error) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	current := cm.config.Rules
+	i := indexOfRule(current, id)
+	if i < 0 {
+		return models.MockRule{}, ErrRuleNotFound
+	}
+	if rule.ID != "" && rule.ID != id {
+		return models.MockRule{}, fmt.Errorf("rule id %q cannot be changed to %q", id, rule.ID)
+	}
+	rule.ID = id
+	if err := compileRule(&rule, filepath.Dir(cm.filePath)); err != nil {
+		return models.MockRule{}, err
+	}
+
+	rules := append([]models.MockRule(nil), current...)
+	rules[i] = rule
+	return rule, cm.publish(rules)
+}
+
+// DeleteRule removes the rule with the given ID
+func (cm *ConfigManagerImpl) DeleteRule(id string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	current := cm.config.Rules
+	i := indexOfRule(current, id)
+	if i < 0 {
+		return ErrRuleNotFound
+	}
+
+	rules := make([]models.MockRule, 0, len(current)-1)
+	rules = append(rules, current[:i]...)
+	rules = append(rules, current[i+1:]...)
+	return cm.publish(rules)
+}
+
+// ReorderRules puts the rules in the order of the given IDs, which must list every rule exactly once
+func (cm *ConfigManagerImpl) ReorderRules(ids []string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	current := cm.config.Rules
+	if len(ids) != len(current) {
+		return fmt.Errorf("expected %d rule ids, got %d", len(current), len(ids))
+	}
+
+	rules := make([]models.MockRule, 0, len(current))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		i := indexOfRule(current, id)
+		if i < 0 {
+			return fmt.Errorf("unknown rule id %q", id)
+		}
+		if seen[id] {
+			return fmt.Errorf("rule id %q is listed twice", id)
+		}
+		seen[id] = true
+		rules = append(rules, current[i])
+	}
+	return cm.publish(rules)
+}
+
+// publish makes rules the current rule list, writing them to the config file first when write-back is enabled
+// Scenarios declared by new rules start in their initial state; the caller must hold the write lock
+func (cm *ConfigManagerImpl) publish(rules []models.MockRule) error {
+	if cm.writeBack {
+		config := cm.config
+		config.Rules = rules
+		if err := writeConfig(cm.filePath, &config); err != nil {
+			return &WriteBackError{Err: err}
+		}
+	}
+
+	cm.config.Rules = rules
+	cm.scenarios.Sync(rules)
+	return nil
+}
+
+// fileConfig is the configuration as written back to the config file
+type fileConfig struct {
+	Rules []fileRule `json:"rules"`
+	*models.Config
+}
+
+// fileRule is a rule as written back to the config file
+// An unset response is left out instead of written as null, while an empty one is kept
+type fileRule struct {
+	models.MockRule
+	Response *map[string]interface{} `json:"response,omitempty"`
+}
+
+// writeConfig replaces the config file with config, leaving out the fields the rules do not set
+func writeConfig(filePath string, config *models.Config) error {
+	view := fileConfig{Rules: make([]fileRule, len(config.Rules)), Config: config}
+	for i, rule := range config.Rules {
+		view.Rules[i].MockRule = rule
+		if rule.Response != nil {
+			view.Rules[i].Response = &config.Rules[i].Response
+		}
+	}
+
+	data, err := json.MarshalIndent(view, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := atomicfile.Write(filePath, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", filePath, err)
+	}
+	return nil
+}
+
+// indexOfRule returns the position of the rule with the given ID, or -1
+func indexOfRule(rules []models.MockRule, id string) int {
+	for i := range rules {
+		if rules[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextRuleID returns the first "rule-N" ID above the highest one used since loading that no rule has
+// The caller must hold the write lock
+func (cm *ConfigManagerImpl) nextRuleID(rules []models.MockRule) string {
+	for n := cm.lastRuleNumber + 1; ; n++ {
+		id := ruleIDPrefix + strconv.Itoa(n)
+		if indexOfRule(rules, id) < 0 {
+			return id
+		}
+	}
+}
+
+// noteRuleID raises the highest "rule-N" number in use when id is of that form
+// The caller must hold the write lock
+func (cm *ConfigManagerImpl) noteRuleID(id string) {
+	text, ok := strings.CutPrefix(id, ruleIDPrefix)
+	if !ok {
+		return
+	}
+	if n, err := strconv.Atoi(text); err == nil && n > cm.lastRuleNumber {
+		cm.lastRuleNumber = n
+	}
+}
@@ -0,0 +1,291 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// loadRules writes a config file with the given content and loads it
+func loadRules(t *testing.T, content string) (*ConfigManagerImpl, string) {
+	t.Helper()
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cm := NewConfigManager()
+	if err := cm.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	return cm, configFile
+}
+
+// ruleIDs returns the IDs of the current rules in order
+func ruleIDs(cm *ConfigManagerImpl) []string {
+	var ids []string
+	for _, rule := range cm.GetConfig() {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
+
+// TestAddRule tests inserting rules, generating IDs and refusing invalid rules
+func TestAddRule(t *testing.T) {
+	cm, _ := loadRules(t, `{"rules": [{"path": "/a"}, {"id": "rule-3", "path": "/b"}]}`)
+
+	added, err := cm.AddRule(models.MockRule{Path: "/users/{id}"}, -1)
+	if err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if added.ID != "rule-4" {
+		t.Errorf("Expected the generated id rule-4 above the loaded ones, got %q", added.ID)
+	}
+
+	if _, err = cm.AddRule(models.MockRule{ID: "first", Path: "/first"}, 0); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if _, err = cm.AddRule(models.MockRule{ID: "last", Path: "/last"}, 99); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if want := []string{"first", "rule-1", "rule-3", "rule-4", "last"}; !reflect.DeepEqual(ruleIDs(cm), want) {
+		t.Errorf("Expected %v, got %v", want, ruleIDs(cm))
+	}
+
+	if _, err = cm.AddRule(models.MockRule{ID: "first", Path: "/x"}, -1); err != ErrDuplicateRuleID {
+		t.Errorf("Expected ErrDuplicateRuleID, got %v", err)
+	}
+	if _, err = cm.AddRule(models.MockRule{Path: "(", PathMatch: "regex"}, -1); err == nil {
+		t.Error("Expected an invalid rule to be refused")
+	}
+	percentage := 250.0
+	if _, err = cm.AddRule(models.MockRule{Path: "/x", ChaosPercentage: &percentage}, -1); err == nil {
+		t.Error("Expected an invalid chaos percentage to be refused")
+	}
+	if _, err = cm.AddRule(models.MockRule{Path: "/__admin/**", PathMatch: models.PathMatchGlob}, -1); err == nil {
+		t.Error("Expected a rule under the admin path prefix to be refused")
+	}
+	if _, err = cm.AddRule(models.MockRule{Path: "/__administrators"}, -1); err != nil {
+		t.Errorf("Expected a path that only shares the prefix text to be accepted, got %v", err)
+	}
+	if len(cm.GetConfig()) != 6 {
+		t.Errorf("Expected refused rules not to be added, got %v", ruleIDs(cm))
+	}
+}
+
+// TestAddRuleNeverReusesIDs tests that the ID of a deleted rule is not generated again
+func TestAddRuleNeverReusesIDs(t *testing.T) {
+	cm, _ := loadRules(t, `{"rules": [{"path": "/a"}, {"path": "/b"}, {"path": "/c"}]}`)
+
+	if err := cm.DeleteRule("rule-3"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+	added, err := cm.AddRule(models.MockRule{Path: "/d"}, -1)
+	if err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if added.ID != "rule-4" {
+		t.Errorf("Expected rule-4 after deleting rule-3, got %q", added.ID)
+	}
+
+	if _, err = cm.AddRule(models.MockRule{ID: "rule-9", Path: "/e"}, -1); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if err = cm.DeleteRule("rule-9"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+	if added, _ = cm.AddRule(models.MockRule{Path: "/f"}, -1); added.ID != "rule-10" {
+		t.Errorf("Expected generated ids to go past explicit ones, got %q", added.ID)
+	}
+}
+
+// TestUpdateAndDeleteRule tests replacing and removing rules by ID
+func TestUpdateAndDeleteRule(t *testing.T) {
+	cm, _ := loadRules(t, `{"rules": [{"id": "a", "path": "/a"}, {"id": "b", "path": "/b"}]}`)
+	published := cm.GetConfig()
+
+	updated, err := cm.UpdateRule("a", models.MockRule{Path: "/a2", Code: 201})
+	if err != nil {
+		t.Fatalf("UpdateRule() error = %v", err)
+	}
+	if rule, ok := cm.GetRule("a"); !ok || updated.ID != "a" || rule.Path != "/a2" || rule.Code != 201 {
+		t.Errorf("Expected rule a to be replaced, got %+v", rule)
+	}
+	if published[0].Path != "/a" {
+		t.Error("Expected a list returned before the update to stay unchanged")
+	}
+
+	if _, err = cm.UpdateRule("a", models.MockRule{ID: "other", Path: "/a"}); err == nil {
+		t.Error("Expected an id change to be refused")
+	}
+	if _, err = cm.UpdateRule("a", models.MockRule{Path: "/__admin"}); err == nil {
+		t.Error("Expected the admin path prefix to be refused")
+	}
+	if _, err = cm.UpdateRule("missing", models.MockRule{Path: "/a"}); err != ErrRuleNotFound {
+		t.Errorf("Expected ErrRuleNotFound, got %v", err)
+	}
+
+	if err = cm.DeleteRule("a"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+	if err = cm.DeleteRule("a"); err != ErrRuleNotFound {
+		t.Errorf("Expected ErrRuleNotFound for a deleted rule, got %v", err)
+	}
+	if want := []string{"b"}; !reflect.DeepEqual(ruleIDs(cm), want) {
+		t.Errorf("Expected %v, got %v", want, ruleIDs(cm))
+	}
+}
+
+// TestReorderRules tests that the order must list every rule exactly once
+func TestReorderRules(t *testing.T) {
+	cm, _ := loadRules(t, `{"rules": [{"id": "a", "path": "/a"}, {"id": "b", "path": "/b"}, {"id": "c", "path": "/c"}]}`)
+
+	for _, ids := range [][]string{{"a", "b"}, {"a", "b", "x"}, {"a", "a", "b"}} {
+		if err := cm.ReorderRules(ids); err == nil {
+			t.Errorf("Expected %v to be refused", ids)
+		}
+	}
+
+	if err := cm.ReorderRules([]string{"c", "a", "b"}); err != nil {
+		t.Fatalf("ReorderRules() error = %v", err)
+	}
+	if want := []string{"c", "a", "b"}; !reflect.DeepEqual(ruleIDs(cm), want) {
+		t.Errorf("Expected %v, got %v", want, ruleIDs(cm))
+	}
+}
+
+// TestRuleChangesSyncScenarios tests that new scenarios start and existing ones keep their state
+func TestRuleChangesSyncScenarios(t *testing.T) {
+	cm, _ := loadRules(t, `{"rules": [{"id": "add", "path": "/cart", "scenario": "cart", "newState": "full"}]}`)
+	rule, _ := cm.GetRule("add")
+	cm.GetScenarioStore().Advance(&rule)
+
+	if _, err := cm.AddRule(models.MockRule{Path: "/login", Scenario: "auth"}, -1); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	want := []models.ScenarioState{{Name: "auth", State: models.ScenarioStateStarted}, {Name: "cart", State: "full"}}
+	if got := cm.GetScenarioStore().States(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if err := cm.DeleteRule("add"); err != nil {
+		t.Fatalf("DeleteRule() error = %v", err)
+	}
+	want = []models.ScenarioState{{Name: "auth", State: models.ScenarioStateStarted}}
+	if got := cm.GetScenarioStore().States(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestRuleChangesWriteBack tests that rule changes are written to the config file when enabled
+func TestRuleChangesWriteBack(t *testing.T) {
+	content := `{"selection": "priority", "rules": [{"path": "/a", "delay": "250ms"}]}`
+	cm, configFile := loadRules(t, content)
+
+	if _, err := cm.AddRule(models.MockRule{ID: "b", Path: "/b"}, -1); err != nil {
+		t.Fatalf("AddRule() error = %v", err)
+	}
+	if data, _ := os.ReadFile(configFile); string(data) != content {
+		t.Errorf("Expected the config file not to change without write-back, got %s", data)
+	}
+
+	cm.EnableWriteBack()
+	if err := cm.ReorderRules([]string{"b", "rule-1"}); err != nil {
+		t.Fatalf("ReorderRules() error = %v", err)
+	}
+
+	reloaded := NewConfigManager()
+	if err := reloaded.LoadConfig(configFile); err != nil {
+		t.Fatalf("LoadConfig() of the written file error = %v", err)
+	}
+	if want := []string{"b", "rule-1"}; !reflect.DeepEqual(ruleIDs(reloaded), want) {
+		t.Errorf("Expected %v, got %v", want, ruleIDs(reloaded))
+	}
+	if reloaded.GetSelectionStrategy() != models.SelectionPriority {
+		t.Errorf("Expected the rest of the configuration to be kept, got %q", reloaded.GetSelectionStrategy())
+	}
+	if rule, _ := reloaded.GetRule("rule-1"); rule.Delay == nil || rule.Delay.Fixed == nil {
+		t.Errorf("Expected the delay to be kept, got %+v", rule.Delay)
+	}
+
+	// A change that cannot be written is not applied
+	if err := os.RemoveAll(filepath.Dir(configFile)); err != nil {
+		t.Fatalf("RemoveAll() error = %v", err)
+	}
+	_, err := cm.AddRule(models.MockRule{Path: "/c"}, -1)
+	if _, ok := err.(*WriteBackError); !ok {
+		t.Errorf("Expected a WriteBackError, got %v", err)
+	}
+	if len(cm.GetConfig()) != 2 {
+		t.Errorf("Expected the rule not to be added, got %v", ruleIDs(cm))
+	}
+}
+
+// TestRuleChangesWriteBackRoundTrip tests that writing back unchanged rules leaves the config file as it was
+func TestRuleChangesWriteBackRoundTrip(t *testing.T) {
+	content := `{
+  "rules": [
+    {
+      "id": "users",
+      "path": "/users",
+      "code": 201,
+      "delay": "250ms",
+      "response": {}
+    },
+    {
+      "id": "text",
+      "path": "/text",
+      "bodyText": "hi"
+    }
+  ],
+  "selection": "priority"
+}
+`
+	cm, configFile := loadRules(t, content)
+	cm.EnableWriteBack()
+
+	if err := cm.ReorderRules([]string{"users", "text"}); err != nil {
+		t.Fatalf("ReorderRules() error = %v", err)
+	}
+	if data, _ := os.ReadFile(configFile); string(data) != content {
+		t.Errorf("Expected the config file to be unchanged, got %s", data)
+	}
+}
+
+// TestRuleChangesConcurrent tests that readers and writers can use the rules concurrently
+func TestRuleChangesConcurrent(t *testing.T) {
+	cm, _ := loadRules(t, `{"rules": []}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := cm.AddRule(models.MockRule{Path: "/a"}, 0); err != nil {
+				t.Errorf("AddRule() error = %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for _, rule := range cm.GetConfig() {
+				_ = rule.Path
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, id := range ruleIDs(cm) {
+		if seen[id] {
+			t.Fatalf("Expected unique generated ids, got %v", ruleIDs(cm))
+		}
+		seen[id] = true
+	}
+	if len(seen) != 20 {
+		t.Errorf("Expected 20 rules, got %d", len(seen))
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"sort"
+	"sync"
+
+	"mock-service/internal/models"
+)
+
+// ScenarioStoreImpl implements the ScenarioStore interface
+// It keeps the state of every scenario declared by the rules in memory
+type ScenarioStoreImpl struct {
+	mu     sync.Mutex
+	states map[string]string
+}
+
+// NewScenarioStore creates a new instance of ScenarioStore without any scenario
+func NewScenarioStore() *ScenarioStoreImpl {
+	return &ScenarioStoreImpl{states: make(map[string]string)}
+}
+
+// Register replaces the known scenarios with those declared by the rules, all in their initial state
+func (s *ScenarioStoreImpl) Register(rules []models.MockRule) {
+	states := make(map[string]string)
+	for i := range rules {
+		if rules[i].Scenario != "" {
+			states[rules[i].Scenario] = models.ScenarioStateStarted
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = states
+}
+
+// Sync adds the scenarios newly declared by the rules in their initial state and forgets those no longer declared
+// Scenarios declared before and after keep their current state
+func (s *ScenarioStoreImpl) Sync(rules []models.MockRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make(map[string]string)
+	for i := range rules {
+		if name := rules[i].Scenario; name != "" {
+			states[name] = s.state(name)
+		}
+	}
+	s.states = states
+}
+
+// Allows reports whether the rule's required state, if any, is the current state of its scenario
+func (s *ScenarioStoreImpl) Allows(rule *models.MockRule) bool {
+	if rule.Scenario == "" || rule.RequiredState == "" {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(rule.Scenario) == rule.RequiredState
+}
+
+// Advance moves the rule's scenario to its new state if the rule is still allowed
+// The check and the transition are atomic, so of two concurrent requests expecting
+// the same state only one moves the scenario on
+func (s *ScenarioStoreImpl) Advance(rule *models.MockRule) (string, bool) {
+	if rule.Scenario == "" {
+		return "", true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.state(rule.Scenario)
+	if rule.RequiredState != "" && state != rule.RequiredState {
+		return state, false
+	}
+	if rule.NewState != "" {
+		state = rule.NewState
+		s.states[rule.Scenario] = state
+	}
+	return state, true
+}
+
+// States returns the current state of every scenario, sorted by name
+func (s *ScenarioStoreImpl) States() []models.ScenarioState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]models.ScenarioState, 0, len(s.states))
+	for name, state := range s.states {
+		states = append(states, models.ScenarioState{Name: name, State: state})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Reset moves a scenario back to its initial state; returns false for an unknown scenario
+func (s *ScenarioStoreImpl) Reset(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.states[name]; !ok {
+		return false
+	}
+	s.states[name] = models.ScenarioStateStarted
+	return true
+}
+
+// ResetAll moves every scenario back to its initial state
+func (s *ScenarioStoreImpl) ResetAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name := range s.states {
+		s.states[name] = models.ScenarioStateStarted
+	}
+}
+
+// Restore sets the state of the known scenarios, e.g. from a saved snapshot
+// Scenarios no longer declared by the rules are ignored
+func (s *ScenarioStoreImpl) Restore(states []models.ScenarioState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, saved := range states {
+		if _, ok := s.states[saved.Name]; ok && saved.State != "" {
+			s.states[saved.Name] = saved.State
+		}
+	}
+}
+
+// state returns the current state of a scenario; the caller must hold the lock
+// Scenarios that were not registered are in their initial state
+func (s *ScenarioStoreImpl) state(name string) string {
+	if state, ok := s.states[name]; ok {
+		return state
+	}
+	return models.ScenarioStateStarted
+}
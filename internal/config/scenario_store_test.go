@@ -0,0 +1,124 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// cartRules returns rules modelling an empty cart, an item being added and a checkout
+func cartRules() []models.MockRule {
+	return []models.MockRule{
+		{ID: "empty", Path: "/cart", Scenario: "cart", RequiredState: models.ScenarioStateStarted},
+		{ID: "add", Path: "/cart/items", Scenario: "cart", NewState: "item-added"},
+		{ID: "full", Path: "/cart", Scenario: "cart", RequiredState: "item-added"},
+		{ID: "checkout", Path: "/checkout", Scenario: "cart", RequiredState: "item-added", NewState: "checked-out"},
+		{ID: "other", Path: "/other"},
+	}
+}
+
+// TestScenarioStoreTransitions tests that rules are allowed according to the state and move it on
+func TestScenarioStoreTransitions(t *testing.T) {
+	store := NewScenarioStore()
+	rules := cartRules()
+	store.Register(rules)
+
+	if !store.Allows(&rules[0]) || store.Allows(&rules[2]) {
+		t.Error("Expected only the empty cart rule to be allowed at the start")
+	}
+	if !store.Allows(&rules[4]) {
+		t.Error("Expected rules without a scenario to always be allowed")
+	}
+
+	if state, ok := store.Advance(&rules[1]); !ok || state != "item-added" {
+		t.Errorf("Expected the add rule to move the cart to item-added, got %q, %v", state, ok)
+	}
+	if store.Allows(&rules[0]) || !store.Allows(&rules[2]) {
+		t.Error("Expected only the full cart rule to be allowed after adding an item")
+	}
+
+	if state, ok := store.Advance(&rules[3]); !ok || state != "checked-out" {
+		t.Errorf("Expected checkout to succeed, got %q, %v", state, ok)
+	}
+	if state, ok := store.Advance(&rules[3]); ok || state != "checked-out" {
+		t.Errorf("Expected a second checkout to be refused, got %q, %v", state, ok)
+	}
+}
+
+// TestScenarioStoreReset tests inspecting and resetting scenario states
+func TestScenarioStoreReset(t *testing.T) {
+	store := NewScenarioStore()
+	rules := append(cartRules(), models.MockRule{Path: "/login", Scenario: "auth", NewState: "logged-in"})
+	store.Register(rules)
+
+	store.Advance(&rules[1])
+	store.Advance(&rules[5])
+
+	want := []models.ScenarioState{{Name: "auth", State: "logged-in"}, {Name: "cart", State: "item-added"}}
+	if got := store.States(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	if !store.Reset("cart") || store.Reset("unknown") {
+		t.Error("Expected Reset to succeed for known scenarios only")
+	}
+	want = []models.ScenarioState{{Name: "auth", State: "logged-in"}, {Name: "cart", State: models.ScenarioStateStarted}}
+	if got := store.States(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	store.ResetAll()
+	for _, state := range store.States() {
+		if state.State != models.ScenarioStateStarted {
+			t.Errorf("Expected %s to be reset, got %q", state.Name, state.State)
+		}
+	}
+}
+
+// TestScenarioStoreRestore tests that saved states are restored for known scenarios only
+func TestScenarioStoreRestore(t *testing.T) {
+	store := NewScenarioStore()
+	rules := cartRules()
+	store.Register(rules)
+
+	store.Restore([]models.ScenarioState{{Name: "cart", State: "item-added"}, {Name: "unknown", State: "x"}})
+
+	want := []models.ScenarioState{{Name: "cart", State: "item-added"}}
+	if got := store.States(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if !store.Allows(&rules[2]) {
+		t.Error("Expected the restored state to apply to the rules")
+	}
+}
+
+// TestScenarioStoreConcurrentAdvance tests that only one of several concurrent requests wins a transition
+func TestScenarioStoreConcurrentAdvance(t *testing.T) {
+	store := NewScenarioStore()
+	rules := cartRules()
+	store.Register(rules)
+	store.Advance(&rules[1])
+
+	var mu sync.Mutex
+	wins := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := store.Advance(&rules[3]); ok {
+				mu.Lock()
+				wins++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if wins != 1 {
+		t.Errorf("Expected exactly one checkout to succeed, got %d", wins)
+	}
+}
@@ -0,0 +1,134 @@
+// Package delay samples and applies response delays
+package delay
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"mock-service/internal/models"
+)
+
+const (
+	// z99 is the 99th percentile of the standard normal distribution
+	z99 = 2.3263478740408408
+	// MaxDelay is the longest delay a specification may configure
+	// It keeps uniform ranges and lognormal samples within the range of time.Duration
+	MaxDelay = 24 * time.Hour
+)
+
+// Validate checks that a delay specification uses exactly one well-formed form
+// A nil specification is valid and means no delay
+func Validate(spec *models.DelaySpec) error {
+	if spec == nil {
+		return nil
+	}
+
+	fixed := spec.Fixed != nil
+	uniform := spec.Min != nil || spec.Max != nil
+	lognormal := spec.P50 != nil || spec.P99 != nil
+
+	forms := 0
+	for _, set := range []bool{fixed, uniform, lognormal} {
+		if set {
+			forms++
+		}
+	}
+
+	switch {
+	case forms != 1:
+		return fmt.Errorf("delay must set exactly one of fixed, min/max or p50/p99")
+	case exceeds(spec.Fixed, spec.Max, spec.P99):
+		return fmt.Errorf("delay must not exceed %v", MaxDelay)
+	case fixed:
+		if *spec.Fixed < 0 {
+			return fmt.Errorf("delay fixed must not be negative")
+		}
+	case uniform:
+		if spec.Min == nil || spec.Max == nil {
+			return fmt.Errorf("delay min and max must be set together")
+		}
+		if *spec.Min < 0 || *spec.Max < *spec.Min {
+			return fmt.Errorf("delay must satisfy 0 <= min <= max")
+		}
+	case lognormal:
+		if spec.P50 == nil || spec.P99 == nil {
+			return fmt.Errorf("delay p50 and p99 must be set together")
+		}
+		if *spec.P50 <= 0 || *spec.P99 < *spec.P50 {
+			return fmt.Errorf("delay must satisfy 0 < p50 <= p99")
+		}
+	}
+	return nil
+}
+
+// exceeds reports whether any of the set durations is longer than MaxDelay
+func exceeds(durations ...*models.Duration) bool {
+	for _, d := range durations {
+		if d != nil && time.Duration(*d) > MaxDelay {
+			return true
+		}
+	}
+	return false
+}
+
+// Sampler draws delays from delay specifications
+// It is safe for concurrent use
+type Sampler struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewSampler creates a Sampler whose random sequence is determined by seed
+func NewSampler(seed int64) *Sampler {
+	return &Sampler{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Sample returns a delay drawn from the specification; a nil specification yields no delay
+func (s *Sampler) Sample(spec *models.DelaySpec) time.Duration {
+	switch {
+	case spec == nil:
+		return 0
+	case spec.Fixed != nil:
+		return time.Duration(*spec.Fixed)
+	case spec.Min != nil && spec.Max != nil:
+		minDelay, maxDelay := int64(*spec.Min), int64(*spec.Max)
+		if maxDelay <= minDelay {
+			return time.Duration(minDelay)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return time.Duration(minDelay + s.rng.Int63n(maxDelay-minDelay+1))
+	case spec.P50 != nil && spec.P99 != nil:
+		// A lognormal distribution with median p50 has mu = ln(p50); sigma follows from p99 = exp(mu + z99*sigma)
+		// The rare samples beyond MaxDelay are cut off there
+		mu := math.Log(float64(*spec.P50))
+		sigma := (math.Log(float64(*spec.P99)) - mu) / z99
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return time.Duration(math.Min(math.Exp(mu+sigma*s.rng.NormFloat64()), float64(MaxDelay)))
+	default:
+		return 0
+	}
+}
+
+// Wait blocks for the given duration or until the context is done
+// It returns the context error when the wait was cut short
+func Wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,134 @@
+package delay
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"mock-service/internal/models"
+)
+
+// spec parses a delay specification written as in the configuration
+func spec(t *testing.T, text string) *models.DelaySpec {
+	t.Helper()
+	var parsed *models.DelaySpec
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		t.Fatalf("Invalid test delay %s: %v", text, err)
+	}
+	return parsed
+}
+
+// TestValidate tests validation of the delay forms
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"nil", `null`, false},
+		{"fixed", `"1s"`, false},
+		{"uniform", `{"min": "10ms", "max": "1s"}`, false},
+		{"lognormal", `{"p50": "100ms", "p99": "2s"}`, false},
+		{"empty", `{}`, true},
+		{"negative fixed", `"-1s"`, true},
+		{"min only", `{"min": "1s"}`, true},
+		{"max below min", `{"min": "1s", "max": "1ms"}`, true},
+		{"p99 only", `{"p99": "1s"}`, true},
+		{"p99 below p50", `{"p50": "1s", "p99": "1ms"}`, true},
+		{"two forms", `{"fixed": "1s", "p50": "1s", "p99": "1s"}`, true},
+		{"fixed too long", `"24h0m0.000000001s"`, true},
+		{"max too long", `{"min": 0, "max": "2562047h"}`, true},
+		{"p99 too long", `{"p50": "1s", "p99": "25h"}`, true},
+		{"longest", `{"min": 0, "max": "24h"}`, false},
+	}
+
+	for _, tt := range tests {
+		if err := Validate(spec(t, tt.spec)); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestSampleFixedAndUniform tests fixed delays and the bounds of uniform delays
+func TestSampleFixedAndUniform(t *testing.T) {
+	s := NewSampler(1)
+
+	if got := s.Sample(nil); got != 0 {
+		t.Errorf("Expected no delay for a nil spec, got %v", got)
+	}
+	if got := s.Sample(spec(t, `"250ms"`)); got != 250*time.Millisecond {
+		t.Errorf("Expected 250ms, got %v", got)
+	}
+
+	uniform := spec(t, `{"min": "100ms", "max": "200ms"}`)
+	for i := 0; i < 1000; i++ {
+		if got := s.Sample(uniform); got < 100*time.Millisecond || got > 200*time.Millisecond {
+			t.Fatalf("Expected a delay between 100ms and 200ms, got %v", got)
+		}
+	}
+}
+
+// TestSampleLongestDelays tests that samples of the widest allowed specifications stay within MaxDelay
+func TestSampleLongestDelays(t *testing.T) {
+	s := NewSampler(3)
+	for _, widest := range []string{`{"min": 0, "max": "24h"}`, `{"p50": "1ns", "p99": "24h"}`} {
+		longest := spec(t, widest)
+		for i := 0; i < 1000; i++ {
+			if got := s.Sample(longest); got < 0 || got > MaxDelay {
+				t.Fatalf("Expected a delay between 0 and %v, got %v", MaxDelay, got)
+			}
+		}
+	}
+}
+
+// TestSampleLognormalPercentiles tests that lognormal samples follow the configured percentiles
+func TestSampleLognormalPercentiles(t *testing.T) {
+	s := NewSampler(42)
+	lognormal := spec(t, `{"p50": "100ms", "p99": "1s"}`)
+
+	samples := make([]time.Duration, 20000)
+	for i := range samples {
+		samples[i] = s.Sample(lognormal)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	p50 := samples[len(samples)/2]
+	p99 := samples[len(samples)*99/100]
+	if p50 < 90*time.Millisecond || p50 > 110*time.Millisecond {
+		t.Errorf("Expected a median near 100ms, got %v", p50)
+	}
+	if p99 < 800*time.Millisecond || p99 > 1250*time.Millisecond {
+		t.Errorf("Expected a 99th percentile near 1s, got %v", p99)
+	}
+}
+
+// TestSamplerIsReproducible tests that the same seed yields the same delays
+func TestSamplerIsReproducible(t *testing.T) {
+	uniform := spec(t, `{"min": 0, "max": "1s"}`)
+	first, second := NewSampler(7), NewSampler(7)
+
+	for i := 0; i < 10; i++ {
+		if a, b := first.Sample(uniform), second.Sample(uniform); a != b {
+			t.Fatalf("Expected identical samples, got %v and %v", a, b)
+		}
+	}
+}
+
+// TestWait tests that waiting completes or stops when the context is done
+func TestWait(t *testing.T) {
+	if err := Wait(context.Background(), 5*time.Millisecond); err != nil {
+		t.Errorf("Expected the wait to complete, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := Wait(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected a cancelled wait to return immediately")
+	}
+}
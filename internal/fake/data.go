@@ -0,0 +1,45 @@
+package fake
+
+// Word lists used to generate fake values
+var (
+	firstNames = []string{
+		"Alice", "Bob", "Carol", "David", "Emma", "Frank", "Grace", "Henry", "Isabel", "Jack",
+		"Karen", "Liam", "Maria", "Noah", "Olivia", "Paul", "Quinn", "Rachel", "Samuel", "Tara",
+		"Umar", "Victoria", "William", "Xena", "Yusuf", "Zoe", "Aiden", "Chloe", "Ethan", "Mia",
+	}
+
+	lastNames = []string{
+		"Anderson", "Brown", "Clark", "Davis", "Evans", "Fischer", "Garcia", "Harris", "Ivanova", "Johnson",
+		"Kim", "Lopez", "Martin", "Nguyen", "Olsen", "Patel", "Quinn", "Rossi", "Smith", "Taylor",
+		"Ueda", "Virtanen", "Walker", "Xu", "Young", "Zimmerman", "Baker", "Cohen", "Dubois", "Moreau",
+	}
+
+	emailDomains = []string{"example.com", "example.org", "example.net", "mail.test", "corp.test"}
+
+	streetNames = []string{
+		"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Lake", "Hill", "Park", "River",
+		"Sunset", "Washington", "Church", "Highland", "Mill", "Forest", "Meadow", "Spring",
+	}
+
+	streetSuffixes = []string{"Street", "Avenue", "Road", "Lane", "Boulevard", "Drive", "Court", "Way"}
+
+	cities = []string{
+		"Springfield", "Riverside", "Fairview", "Franklin", "Greenville", "Bristol", "Clinton", "Madison",
+		"Georgetown", "Salem", "Arlington", "Ashland", "Dover", "Oxford", "Milton", "Newport",
+	}
+
+	countries = []string{
+		"United States", "Canada", "United Kingdom", "Germany", "France", "Spain", "Italy", "Netherlands",
+		"Sweden", "Japan", "Australia", "Brazil", "India", "Mexico", "Poland", "Ireland",
+	}
+
+	companySuffixes = []string{"Inc", "LLC", "Group", "Labs", "Systems", "Partners", "Holdings", "Co"}
+
+	loremWords = []string{
+		"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+		"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+		"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+		"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+		"velit", "esse", "cillum", "fugiat", "nulla", "pariatur", "excepteur", "sint", "occaecat", "cupidatat",
+	}
+)
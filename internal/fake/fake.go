@@ -0,0 +1,258 @@
+// Package fake generates deterministic fake data for response templates
+package fake
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout is the layout of the dates accepted and returned by Date
+const dateLayout = "2006-01-02"
+
+// Faker generates fake values derived from a seed
+// A Faker describes a single entity: person and address values (names, email, city...) are the
+// same every time they are requested, so Name and Email stay consistent with FirstName.
+// Other values (numbers, words, dates, UUIDs...) change on every call, in a sequence that only
+// depends on the seed, so the same seed always produces the same output
+type Faker struct {
+	seed  string
+	calls map[string]int
+}
+
+// New creates a Faker seeded with the given values, e.g. New("user", 42)
+// Without values the Faker is seeded from the current time and is not reproducible
+func New(seed ...interface{}) *Faker {
+	if len(seed) == 0 {
+		return &Faker{seed: strconv.FormatInt(time.Now().UnixNano(), 10), calls: make(map[string]int)}
+	}
+
+	parts := make([]string, len(seed))
+	for i, value := range seed {
+		parts[i] = fmt.Sprint(value)
+	}
+	return &Faker{seed: strings.Join(parts, "\x00"), calls: make(map[string]int)}
+}
+
+// stable returns the random source of a value that is the same on every call
+func (f *Faker) stable(key string) *rand.Rand {
+	return f.source(key, 0)
+}
+
+// next returns the random source of the next call of a value that changes on every call
+func (f *Faker) next(key string) *rand.Rand {
+	n := f.calls[key]
+	f.calls[key]++
+	return f.source(key, n)
+}
+
+// source derives a random source from the seed, a value key and a call number
+func (f *Faker) source(key string, n int) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(f.seed))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(strconv.Itoa(n)))
+	return rand.New(rand.NewSource(int64(h.Sum64())))
+}
+
+// pick returns a random element of a list
+func pick(r *rand.Rand, list []string) string {
+	return list[r.Intn(len(list))]
+}
+
+// FirstName returns the first name of the entity
+func (f *Faker) FirstName() string {
+	return pick(f.stable("firstName"), firstNames)
+}
+
+// LastName returns the last name of the entity
+func (f *Faker) LastName() string {
+	return pick(f.stable("lastName"), lastNames)
+}
+
+// Name returns the full name of the entity
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// Username returns a user name derived from the name of the entity
+func (f *Faker) Username() string {
+	return fmt.Sprintf("%s.%s%d", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), f.stable("username").Intn(100))
+}
+
+// Email returns an email address derived from the name of the entity
+func (f *Faker) Email() string {
+	domain := pick(f.stable("email"), emailDomains)
+	return fmt.Sprintf("%s.%s@%s", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), domain)
+}
+
+// Phone returns the phone number of the entity in the reserved 555-01xx range
+func (f *Faker) Phone() string {
+	r := f.stable("phone")
+	return fmt.Sprintf("+1-%03d-555-01%02d", 200+r.Intn(800), r.Intn(100))
+}
+
+// Company returns the company name of the entity
+func (f *Faker) Company() string {
+	r := f.stable("company")
+	return pick(r, lastNames) + " " + pick(r, companySuffixes)
+}
+
+// Street returns the street address of the entity
+func (f *Faker) Street() string {
+	r := f.stable("street")
+	return fmt.Sprintf("%d %s %s", 1+r.Intn(9999), pick(r, streetNames), pick(r, streetSuffixes))
+}
+
+// City returns the city of the entity
+func (f *Faker) City() string {
+	return pick(f.stable("city"), cities)
+}
+
+// ZipCode returns the five digit postal code of the entity
+func (f *Faker) ZipCode() string {
+	return fmt.Sprintf("%05d", f.stable("zipCode").Intn(100000))
+}
+
+// Country returns the country of the entity
+func (f *Faker) Country() string {
+	return pick(f.stable("country"), countries)
+}
+
+// Address returns the full postal address of the entity
+func (f *Faker) Address() string {
+	return fmt.Sprintf("%s, %s %s, %s", f.Street(), f.City(), f.ZipCode(), f.Country())
+}
+
+// ID returns the UUID of the entity
+func (f *Faker) ID() string {
+	return uuid(f.stable("id"))
+}
+
+// UUID returns a new version 4 UUID on every call
+func (f *Faker) UUID() string {
+	return uuid(f.next("uuid"))
+}
+
+// uuid formats 16 random bytes as a version 4 UUID
+func uuid(r *rand.Rand) string {
+	var b [16]byte
+	_, _ = r.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Int returns an integer between minValue and maxValue, inclusive
+func (f *Faker) Int(minValue, maxValue int) (int, error) {
+	if maxValue < minValue {
+		return 0, fmt.Errorf("max %d is less than min %d", maxValue, minValue)
+	}
+	return minValue + f.next("int").Intn(maxValue-minValue+1), nil
+}
+
+// Float returns a number between minValue and maxValue rounded to two decimals
+func (f *Faker) Float(minValue, maxValue float64) (float64, error) {
+	if maxValue < minValue {
+		return 0, fmt.Errorf("max %g is less than min %g", maxValue, minValue)
+	}
+	value := minValue + f.next("float").Float64()*(maxValue-minValue)
+	return math.Round(value*100) / 100, nil
+}
+
+// Bool returns true or false
+func (f *Faker) Bool() bool {
+	return f.next("bool").Intn(2) == 1
+}
+
+// Pick returns one of the given options, e.g. Pick "active" "suspended" "closed"
+func (f *Faker) Pick(options ...string) (string, error) {
+	if len(options) == 0 {
+		return "", fmt.Errorf("pick requires at least one option")
+	}
+	return pick(f.next("pick"), options), nil
+}
+
+// Word returns a lorem ipsum word
+func (f *Faker) Word() string {
+	return pick(f.next("word"), loremWords)
+}
+
+// Words returns count lorem ipsum words separated by spaces
+func (f *Faker) Words(count int) string {
+	r := f.next("words")
+	words := make([]string, count)
+	for i := range words {
+		words[i] = pick(r, loremWords)
+	}
+	return strings.Join(words, " ")
+}
+
+// Sentence returns a capitalized lorem ipsum sentence of 6 to 12 words
+func (f *Faker) Sentence() string {
+	r := f.next("sentence")
+	return sentence(r)
+}
+
+// Paragraph returns 3 to 5 lorem ipsum sentences
+func (f *Faker) Paragraph() string {
+	r := f.next("paragraph")
+	sentences := make([]string, 3+r.Intn(3))
+	for i := range sentences {
+		sentences[i] = sentence(r)
+	}
+	return strings.Join(sentences, " ")
+}
+
+// sentence builds a lorem ipsum sentence from a random source
+func sentence(r *rand.Rand) string {
+	words := make([]string, 6+r.Intn(7))
+	for i := range words {
+		words[i] = pick(r, loremWords)
+	}
+	text := strings.Join(words, " ")
+	return strings.ToUpper(text[:1]) + text[1:] + "."
+}
+
+// Date returns a date between from and to (YYYY-MM-DD, inclusive) formatted as YYYY-MM-DD
+func (f *Faker) Date(from, to string) (string, error) {
+	t, err := f.between("date", from, to)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(dateLayout), nil
+}
+
+// DateTime returns a time between from and to (YYYY-MM-DD, inclusive) formatted as RFC 3339
+func (f *Faker) DateTime(from, to string) (string, error) {
+	t, err := f.between("dateTime", from, to)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(time.RFC3339), nil
+}
+
+// between returns a time between the start of from and the end of to
+func (f *Faker) between(key, from, to string) (time.Time, error) {
+	start, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %w", from, err)
+	}
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: %w", to, err)
+	}
+	end = end.Add(24*time.Hour - time.Second)
+	if end.Before(start) {
+		return time.Time{}, fmt.Errorf("date %s is before %s", to, from)
+	}
+
+	span := int64(end.Sub(start) / time.Second)
+	return start.Add(time.Duration(f.next(key).Int63n(span+1)) * time.Second), nil
+}
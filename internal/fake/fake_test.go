@@ -0,0 +1,120 @@
+package fake
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFakerIsDeterministic tests that the same seed always produces the same values
+func TestFakerIsDeterministic(t *testing.T) {
+	generate := func(f *Faker) []string {
+		n, _ := f.Int(1, 1000)
+		date, _ := f.Date("2020-01-01", "2024-12-31")
+		status, _ := f.Pick("active", "suspended", "closed")
+		return []string{
+			f.Name(), f.Email(), f.Username(), f.Phone(), f.Address(), f.Company(), f.ID(), f.UUID(),
+			f.Sentence(), f.Words(3), date, status, strconv.Itoa(n),
+		}
+	}
+
+	first := generate(New("user", 42))
+	second := generate(New("user", 42))
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("Value %d differs between fakers with the same seed: %q vs %q", i, first[i], second[i])
+		}
+	}
+
+	other := generate(New("user", 43))
+	if strings.Join(first, "|") == strings.Join(other, "|") {
+		t.Error("Expected different seeds to produce different values")
+	}
+}
+
+// TestFakerEntityIsConsistent tests that person values describe the same entity
+func TestFakerEntityIsConsistent(t *testing.T) {
+	f := New(7)
+
+	if f.Name() != f.FirstName()+" "+f.LastName() {
+		t.Errorf("Expected Name to combine FirstName and LastName, got %q", f.Name())
+	}
+	if !strings.HasPrefix(f.Email(), strings.ToLower(f.FirstName()+"."+f.LastName())+"@") {
+		t.Errorf("Expected Email to derive from the name, got %q", f.Email())
+	}
+	if f.ID() != f.ID() {
+		t.Error("Expected ID to be stable")
+	}
+	if f.UUID() == f.UUID() {
+		t.Error("Expected UUID to change on every call")
+	}
+}
+
+// TestFakerFormats tests the format of generated values
+func TestFakerFormats(t *testing.T) {
+	f := New("formats")
+
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := f.UUID(); !uuidPattern.MatchString(id) {
+		t.Errorf("Expected a version 4 UUID, got %q", id)
+	}
+	if zip := f.ZipCode(); !regexp.MustCompile(`^\d{5}$`).MatchString(zip) {
+		t.Errorf("Expected a five digit zip code, got %q", zip)
+	}
+	if words := strings.Fields(f.Words(4)); len(words) != 4 {
+		t.Errorf("Expected 4 words, got %v", words)
+	}
+	if s := f.Sentence(); !strings.HasSuffix(s, ".") || strings.ToUpper(s[:1]) != s[:1] {
+		t.Errorf("Expected a capitalized sentence, got %q", s)
+	}
+}
+
+// TestFakerRanges tests that numbers and dates stay within their bounds
+func TestFakerRanges(t *testing.T) {
+	f := New("ranges")
+
+	for i := 0; i < 200; i++ {
+		n, err := f.Int(-3, 3)
+		if err != nil || n < -3 || n > 3 {
+			t.Fatalf("Int(-3, 3) = %d (%v)", n, err)
+		}
+
+		x, err := f.Float(1.5, 2.5)
+		if err != nil || x < 1.5 || x > 2.5 {
+			t.Fatalf("Float(1.5, 2.5) = %g (%v)", x, err)
+		}
+
+		date, err := f.Date("2024-02-27", "2024-03-02")
+		if err != nil || date < "2024-02-27" || date > "2024-03-02" {
+			t.Fatalf("Date = %s (%v)", date, err)
+		}
+
+		moment, err := f.DateTime("2024-01-01", "2024-01-01")
+		if err != nil {
+			t.Fatalf("DateTime failed: %v", err)
+		}
+		if parsed, _ := time.Parse(time.RFC3339, moment); parsed.Format("2006-01-02") != "2024-01-01" {
+			t.Fatalf("DateTime = %s, expected a time on 2024-01-01", moment)
+		}
+	}
+}
+
+// TestFakerErrors tests that invalid arguments are reported
+func TestFakerErrors(t *testing.T) {
+	f := New("errors")
+
+	if _, err := f.Int(5, 1); err == nil {
+		t.Error("Expected an error when max is less than min")
+	}
+	if _, err := f.Pick(); err == nil {
+		t.Error("Expected an error for Pick without options")
+	}
+	if _, err := f.Date("2024-13-01", "2024-12-31"); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+	if _, err := f.Date("2024-12-31", "2024-01-01"); err == nil {
+		t.Error("Expected an error for a reversed date range")
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"bufio"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"mock-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// garbageSize is the number of random bytes sent by the garbage fault
+const garbageSize = 512
+
+// writeFault hijacks the client connection and produces a network-level fault instead of a proper response
+// statusCode, headers and body are the response the rule would have sent; some faults send part of it
+func writeFault(c *gin.Context, fault string, statusCode int, headers http.Header, body []byte) error {
+	// Gin's Hijack panics when the underlying writer cannot be hijacked, so check it first
+	if unwrapper, ok := c.Writer.(interface{ Unwrap() http.ResponseWriter }); ok {
+		if _, hijackable := unwrapper.Unwrap().(http.Hijacker); !hijackable {
+			return fmt.Errorf("failed to hijack connection for fault %s: %w", fault, http.ErrNotSupported)
+		}
+	}
+
+	conn, rw, err := c.Writer.Hijack()
+	if err != nil {
+		return fmt.Errorf("failed to hijack connection for fault %s: %w", fault, err)
+	}
+	defer conn.Close()
+
+	switch fault {
+	case models.FaultConnectionReset:
+		// A zero linger time makes Close send a RST instead of a FIN
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			if err = tcpConn.SetLinger(0); err != nil {
+				return fmt.Errorf("failed to reset connection: %w", err)
+			}
+		}
+		return nil
+	case models.FaultEmptyResponse:
+		return nil
+	case models.FaultGarbage:
+		garbage := make([]byte, garbageSize)
+		if _, err = rand.Read(garbage); err != nil {
+			return fmt.Errorf("failed to generate garbage: %w", err)
+		}
+		return writeAndFlush(rw, garbage)
+	case models.FaultTruncatedJSON:
+		// Without a Content-Length the body ends when the connection closes, so the client sees a clean but partial body
+		headers = headers.Clone()
+		headers.Del("Content-Length")
+		headers.Set("Connection", "close")
+		return writeRaw(rw, statusCode, headers, body[:len(body)/2])
+	case models.FaultContentLengthMismatch:
+		headers = headers.Clone()
+		headers.Set("Content-Length", strconv.Itoa(len(body)+garbageSize))
+		return writeRaw(rw, statusCode, headers, body)
+	default:
+		return fmt.Errorf("unknown fault %q", fault)
+	}
+}
+
+// writeRaw writes an HTTP/1.1 response by hand, bypassing net/http framing checks
+func writeRaw(rw *bufio.ReadWriter, statusCode int, headers http.Header, body []byte) error {
+	if _, err := fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", statusCode, http.StatusText(statusCode)); err != nil {
+		return fmt.Errorf("failed to write status line: %w", err)
+	}
+	if err := headers.Write(rw); err != nil {
+		return fmt.Errorf("failed to write headers: %w", err)
+	}
+	if _, err := rw.WriteString("\r\n"); err != nil {
+		return fmt.Errorf("failed to write headers: %w", err)
+	}
+	return writeAndFlush(rw, body)
+}
+
+// writeAndFlush writes data to the hijacked connection and flushes it
+func writeAndFlush(rw *bufio.ReadWriter, data []byte) error {
+	if _, err := rw.Write(data); err != nil {
+		return fmt.Errorf("failed to write fault payload: %w", err)
+	}
+	if err := rw.Flush(); err != nil {
+		return fmt.Errorf("failed to write fault payload: %w", err)
+	}
+	return nil
+}
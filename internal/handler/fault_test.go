@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mock-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newFaultServer starts a real HTTP server whose only rule injects the given fault
+func newFaultServer(t *testing.T, fault string, logger *mockLogger) *httptest.Server {
+	t.Helper()
+
+	rule := &models.MockRule{
+		Path:     "/api/fault",
+		Code:     200,
+		Response: map[string]interface{}{"message": "this response should never arrive intact"},
+		Fault:    fault,
+	}
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}},
+		&mockPathMatcher{shouldMatch: true, ruleToReturn: rule}, &mockResponseBuilder{}, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sendRawRequest sends a GET request over a plain TCP connection and returns everything the server wrote
+func sendRawRequest(t *testing.T, server *httptest.Server) ([]byte, error) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatalf("Failed to set deadline: %v", err)
+	}
+
+	if _, err := io.WriteString(conn, "GET /api/fault HTTP/1.1\r\nHost: test\r\n\r\n"); err != nil {
+		t.Fatalf("Failed to send request: %v", err)
+	}
+	return io.ReadAll(conn)
+}
+
+// TestFaultConnectionReset tests that the connection is reset without any data
+func TestFaultConnectionReset(t *testing.T) {
+	logger := &mockLogger{}
+	server := newFaultServer(t, models.FaultConnectionReset, logger)
+
+	data, err := sendRawRequest(t, server)
+	if err == nil || !strings.Contains(err.Error(), "reset") {
+		t.Errorf("Expected a connection reset, got error %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected no data, got %q", data)
+	}
+	if faults := logger.faults(); len(faults) != 1 || faults[0] != models.FaultConnectionReset {
+		t.Errorf("Expected the fault to be logged, got %v", faults)
+	}
+}
+
+// TestFaultEmptyResponse tests that the connection is closed without any data
+func TestFaultEmptyResponse(t *testing.T) {
+	server := newFaultServer(t, models.FaultEmptyResponse, &mockLogger{})
+
+	data, err := sendRawRequest(t, server)
+	if err != nil {
+		t.Errorf("Expected a clean close, got error %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected no data, got %q", data)
+	}
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/api/fault", http.NoBody)
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected the HTTP client to fail on an empty reply")
+	}
+}
+
+// TestFaultGarbage tests that random bytes are sent instead of an HTTP response
+func TestFaultGarbage(t *testing.T) {
+	server := newFaultServer(t, models.FaultGarbage, &mockLogger{})
+
+	data, err := sendRawRequest(t, server)
+	if err != nil {
+		t.Fatalf("Expected a clean close, got error %v", err)
+	}
+	if len(data) != garbageSize {
+		t.Errorf("Expected %d bytes of garbage, got %d", garbageSize, len(data))
+	}
+	if strings.HasPrefix(string(data), "HTTP/") {
+		t.Errorf("Expected garbage, got an HTTP response: %q", data)
+	}
+}
+
+// TestFaultTruncatedJSON tests that a well-formed response carries only half of the JSON body
+func TestFaultTruncatedJSON(t *testing.T) {
+	server := newFaultServer(t, models.FaultTruncatedJSON, &mockLogger{})
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", server.URL+"/api/fault", http.NoBody)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Expected a response, got error %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Expected the body to end cleanly, got error %v", err)
+	}
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") == "" {
+		t.Errorf("Expected the rule status and headers, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if len(body) == 0 || !strings.HasPrefix(`{"message":"this response should never arrive intact"}`, string(body)) {
+		t.Errorf("Expected a prefix of the JSON body, got %q", body)
+	}
+	if strings.HasSuffix(string(body), "}") {
+		t.Errorf("Expected the JSON body to be truncated, got %q", body)
+	}
+}
+
+// TestFaultContentLengthMismatch tests that the declared Content-Length exceeds the body
+func TestFaultContentLengthMismatch(t *testing.T) {
+	server := newFaultServer(t, models.FaultContentLengthMismatch, &mockLogger{})
+
+	data, err := sendRawRequest(t, server)
+	if err != nil {
+		t.Fatalf("Expected a clean close, got error %v", err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(strings.NewReader(string(data))), nil)
+	if err != nil {
+		t.Fatalf("Expected a parseable response head, got error %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected an unexpected EOF, got error %v", err)
+	}
+	if resp.ContentLength != int64(len(body)+garbageSize) {
+		t.Errorf("Expected Content-Length %d, got %d", len(body)+garbageSize, resp.ContentLength)
+	}
+}
+
+// TestFaultWithoutHijack tests that a fault falls back to an error response when the connection cannot be hijacked
+func TestFaultWithoutHijack(t *testing.T) {
+	rule := &models.MockRule{Path: "/api/fault", Code: 200, Fault: models.FaultEmptyResponse}
+	logger := &mockLogger{}
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}},
+		&mockPathMatcher{shouldMatch: true, ruleToReturn: rule}, &mockResponseBuilder{}, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/fault", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", w.Code)
+	}
+	if len(logger.loggedResponses) != 1 || logger.loggedResponses[0].StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected the error response to be logged, got %+v", logger.loggedResponses)
+	}
+}
@@ -1,7 +1,18 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"mock-service/internal/chaos"
+	"mock-service/internal/delay"
 	"mock-service/internal/interfaces"
+	"mock-service/internal/journal"
+	"mock-service/internal/models"
+	"mock-service/internal/resource"
+	"mock-service/internal/sequence"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +23,65 @@ type UniversalHandler struct {
 	pathMatcher     interfaces.PathMatcher
 	responseBuilder interfaces.ResponseBuilder
 	logger          interfaces.Logger
+	delays          *delay.Sampler
+	defaultDelay    *models.DelaySpec
+	chaos           *chaos.Engine
+	sequences       *sequence.Counters
+	scenarios       interfaces.ScenarioStore
+	resources       *resource.Store
+	journal         *journal.Journal
+}
+
+// Option configures optional behavior of UniversalHandler
+type Option func(*UniversalHandler)
+
+// WithDefaultDelay sets the delay applied to responses whose rule does not set one
+func WithDefaultDelay(spec *models.DelaySpec) Option {
+	return func(uh *UniversalHandler) {
+		uh.defaultDelay = spec
+	}
+}
+
+// WithDelaySampler sets the sampler used to draw randomized delays, e.g. a seeded one for reproducible runs
+func WithDelaySampler(sampler *delay.Sampler) Option {
+	return func(uh *UniversalHandler) {
+		uh.delays = sampler
+	}
+}
+
+// WithChaos sets the engine that randomly disrupts matched requests
+func WithChaos(engine *chaos.Engine) Option {
+	return func(uh *UniversalHandler) {
+		uh.chaos = engine
+	}
+}
+
+// WithSequenceCounters sets the counters tracking response sequences, e.g. to share them with the admin API
+func WithSequenceCounters(counters *sequence.Counters) Option {
+	return func(uh *UniversalHandler) {
+		uh.sequences = counters
+	}
+}
+
+// WithScenarioStore sets the store holding scenario states; without it rules ignore their scenario
+func WithScenarioStore(store interfaces.ScenarioStore) Option {
+	return func(uh *UniversalHandler) {
+		uh.scenarios = store
+	}
+}
+
+// WithResources sets the in-memory REST collections that answer requests no rule matches
+func WithResources(store *resource.Store) Option {
+	return func(uh *UniversalHandler) {
+		uh.resources = store
+	}
+}
+
+// WithJournal records every request and how it was answered in the journal
+func WithJournal(j *journal.Journal) Option {
+	return func(uh *UniversalHandler) {
+		uh.journal = j
+	}
 }
 
 // NewUniversalHandler creates a new instance of UniversalHandler
@@ -20,54 +90,215 @@ func NewUniversalHandler(
 	pathMatcher interfaces.PathMatcher,
 	responseBuilder interfaces.ResponseBuilder,
 	logger interfaces.Logger,
+	options ...Option,
 ) *UniversalHandler {
-	return &UniversalHandler{
+	uh := &UniversalHandler{
 		configManager:   configManager,
 		pathMatcher:     pathMatcher,
 		responseBuilder: responseBuilder,
 		logger:          logger,
+		delays:          delay.NewSampler(time.Now().UnixNano()),
+		sequences:       sequence.NewCounters(),
 	}
+	for _, option := range options {
+		option(uh)
+	}
+	return uh
 }
 
 // HandleRequest handles all HTTP requests for any path and method
 func (uh *UniversalHandler) HandleRequest(c *gin.Context) {
-	// Extract request information
-	method := c.Request.Method
-	path := c.Request.URL.Path
+	received := time.Now()
+
+	// Extract request information; the body is buffered once for logging and matching
+	req, err := models.NewRequest(c.Request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Record the request in the journal once it has been answered
+	outcome := &journal.Outcome{}
+	defer uh.record(req, received, outcome)
 
-	// Parse query parameters
+	// Parse query parameters; the matcher receives every value, the log the first one
 	params := make(map[string]string)
-	for key, values := range c.Request.URL.Query() {
+	for key, values := range req.Query {
 		if len(values) > 0 {
 			params[key] = values[0] // Take first value if multiple exist
 		}
 	}
 
 	// Log the incoming request
-	uh.logger.LogRequest(method, path, params)
+	uh.logger.LogRequest(req.Method, req.Path, params, req.Body)
 
 	// Get current configuration rules
 	rules := uh.configManager.GetConfig()
 
 	// Try to find a matching rule
-	rule, found := uh.pathMatcher.FindMatch(path, rules)
+	match, found := uh.findMatch(req, rules)
+
+	// Let chaos disrupt matched requests: replace the response with an error, add a delay or inject a fault
+	var rule *models.MockRule
+	var decision *models.ChaosDecision
+	if found {
+		rule = match.Rule
+		decision = uh.decideChaos(rule, req.Path)
+	}
+
+	// Wait before building the response, so a request that is cancelled during its delay changes no resource
+	if err := uh.wait(c.Request.Context(), rule, decision); err != nil {
+		outcome.Cancelled = true
+		c.Abort()
+		return
+	}
 
 	var statusCode int
-	var body interface{}
+	var headers http.Header
+	var body []byte
 
 	if found {
 		// Rule matched - build response from rule
-		uh.logger.LogMatch(rule)
-		statusCode, body = uh.responseBuilder.BuildResponse(rule)
+		uh.logger.LogMatch(match)
+		outcome.RuleID = match.Rule.ID
+		statusCode, headers, body = uh.responseBuilder.BuildResponse(match)
+	} else if name, code, resourceHeaders, resourceBody, ok := uh.handleResource(req); ok {
+		// No rule matched - an in-memory resource owns the path
+		uh.logger.LogResource(name)
+		outcome.Resource = name
+		statusCode, headers, body = code, resourceHeaders, resourceBody
 	} else {
 		// No rule matched - use default response
 		uh.logger.LogDefault()
-		statusCode, body = uh.responseBuilder.BuildDefaultResponse()
+		statusCode, headers, body = uh.responseBuilder.BuildDefaultResponse()
+	}
+
+	// Apply the chaos error or fault, if any
+	fault := ""
+	if found {
+		fault = match.Rule.Fault
+	}
+	if decision != nil {
+		switch decision.Action {
+		case models.ChaosActionError:
+			statusCode, headers, body = chaosErrorResponse(decision.StatusCode)
+		case models.ChaosActionFault:
+			fault = decision.Fault
+		}
+	}
+
+	// Replace the response with a network-level fault when the rule or chaos asks for one
+	// A connection that cannot be hijacked (e.g. HTTP/2) gets an error response instead
+	if fault != "" {
+		uh.logger.LogFault(fault)
+		outcome.Fault = fault
+		if err := writeFault(c, fault, statusCode, headers, body); err != nil && !c.Writer.Written() {
+			uh.logger.LogResponse(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			outcome.StatusCode = http.StatusInternalServerError
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		c.Abort()
+		return
 	}
 
 	// Log the response
 	uh.logger.LogResponse(statusCode, body)
+	outcome.StatusCode = statusCode
+
+	// Send the response headers and the encoded body as-is
+	for name, values := range headers {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Data(statusCode, headers.Get("Content-Type"), body)
+}
+
+// wait sleeps for the delay of the matched rule, or the default delay, plus any chaos delay
+// It returns the context error when the client goes away first; rule and decision may be nil
+func (uh *UniversalHandler) wait(ctx context.Context, rule *models.MockRule, decision *models.ChaosDecision) error {
+	spec := uh.defaultDelay
+	if rule != nil && rule.Delay != nil {
+		spec = rule.Delay
+	}
+	wait := uh.delays.Sample(spec)
+	if decision != nil && decision.Action == models.ChaosActionDelay {
+		wait += decision.Delay
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	err := delay.Wait(ctx, wait)
+	uh.logger.LogDelay(wait, err != nil)
+	return err
+}
+
+// record adds the request and its outcome to the journal, if any
+func (uh *UniversalHandler) record(req *models.Request, received time.Time, outcome *journal.Outcome) {
+	if uh.journal != nil {
+		uh.journal.Record(req, received, outcome)
+	}
+}
+
+// findMatch finds the matching rule, claims the next step of its response sequence and moves its scenario on
+// A sequence that ends with fallthrough can run out, and a scenario can leave the required state,
+// between matching and claiming when requests race; matching again then skips the rule
+// The step is claimed and the scenario moved on together, so a skipped rule changes neither
+func (uh *UniversalHandler) findMatch(req *models.Request, rules []models.MockRule) (*models.MatchResult, bool) {
+	for attempt := 0; attempt <= len(rules); attempt++ {
+		match, found := uh.pathMatcher.FindMatch(req, rules)
+		if !found {
+			return nil, false
+		}
+		index, ok := uh.sequences.NextIf(match.Rule, uh.advanceScenario(match))
+		if ok {
+			match.SequenceIndex = index
+			return match, true
+		}
+	}
+	return nil, false
+}
+
+// advanceScenario returns a function moving the scenario of the matched rule on and recording its state
+// Returns nil when no scenario store is set
+func (uh *UniversalHandler) advanceScenario(match *models.MatchResult) func() bool {
+	if uh.scenarios == nil {
+		return nil
+	}
+	return func() bool {
+		state, ok := uh.scenarios.Advance(match.Rule)
+		if ok {
+			match.ScenarioState = state
+		}
+		return ok
+	}
+}
+
+// handleResource lets the in-memory resources answer a request no rule matched
+// Returns false when no resources are set or none owns the request path
+func (uh *UniversalHandler) handleResource(req *models.Request) (string, int, http.Header, []byte, bool) {
+	if uh.resources == nil {
+		return "", 0, nil, nil, false
+	}
+	return uh.resources.Handle(req)
+}
+
+// decideChaos rolls chaos for a matched request and logs the decision
+// Returns nil when no chaos engine is set or chaos does not apply to the request
+func (uh *UniversalHandler) decideChaos(rule *models.MockRule, path string) *models.ChaosDecision {
+	if uh.chaos == nil {
+		return nil
+	}
+	decision := uh.chaos.Decide(rule, path)
+	if decision != nil {
+		uh.logger.LogChaos(decision)
+	}
+	return decision
+}
 
-	// Send the response
-	c.JSON(statusCode, body)
+// chaosErrorResponse builds the JSON error response sent by the chaos error action
+func chaosErrorResponse(statusCode int) (int, http.Header, []byte) {
+	body, _ := json.Marshal(map[string]string{"error": http.StatusText(statusCode), "chaos": "injected"})
+	return statusCode, http.Header{"Content-Type": {"application/json; charset=utf-8"}}, body
 }
@@ -3,11 +3,24 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"mock-service/internal/chaos"
+	"mock-service/internal/config"
+	"mock-service/internal/journal"
+	"mock-service/internal/logger"
+	"mock-service/internal/matcher"
 	"mock-service/internal/models"
+	"mock-service/internal/resource"
+	"mock-service/internal/response"
+	"mock-service/internal/sequence"
+	"mock-service/internal/testutil"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,36 +38,83 @@ func (m *mockConfigManager) GetConfig() []models.MockRule {
 	return m.rules
 }
 
+func (m *mockConfigManager) GetRule(id string) (models.MockRule, bool) {
+	return models.MockRule{}, false
+}
+
+func (m *mockConfigManager) AddRule(rule models.MockRule, position int) (models.MockRule, error) {
+	return rule, nil
+}
+
+func (m *mockConfigManager) UpdateRule(id string, rule models.MockRule) (models.MockRule, error) {
+	return rule, nil
+}
+
+func (m *mockConfigManager) DeleteRule(id string) error {
+	return nil
+}
+
+func (m *mockConfigManager) ReorderRules(ids []string) error {
+	return nil
+}
+
 type mockPathMatcher struct {
-	shouldMatch  bool
-	ruleToReturn *models.MockRule
+	shouldMatch    bool
+	ruleToReturn   *models.MockRule
+	paramsToReturn map[string]string
+	lastRequest    *models.Request
 }
 
-func (m *mockPathMatcher) FindMatch(requestPath string, rules []models.MockRule) (*models.MockRule, bool) {
-	return m.ruleToReturn, m.shouldMatch
+func (m *mockPathMatcher) FindMatch(req *models.Request, rules []models.MockRule) (*models.MatchResult, bool) {
+	m.lastRequest = req
+	if !m.shouldMatch {
+		return nil, false
+	}
+	return &models.MatchResult{Rule: m.ruleToReturn, PathParams: m.paramsToReturn, Request: req}, true
 }
 
 type mockResponseBuilder struct{}
 
-func (m *mockResponseBuilder) BuildResponse(rule *models.MockRule) (statusCode int, body interface{}) {
-	return rule.Code, rule.Response
+func (m *mockResponseBuilder) BuildResponse(match *models.MatchResult) (statusCode int, headers http.Header, body []byte) {
+	headers = http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	for name, values := range match.Rule.ResponseHeaders {
+		headers[http.CanonicalHeaderKey(name)] = values
+	}
+	if match.Rule.BodyText != nil {
+		return match.Rule.Code, headers, []byte(*match.Rule.BodyText)
+	}
+	body, _ = json.Marshal(match.Rule.Response)
+	return match.Rule.Code, headers, body
 }
 
-func (m *mockResponseBuilder) BuildDefaultResponse() (statusCode int, body interface{}) {
-	return 200, map[string]interface{}{}
+func (m *mockResponseBuilder) BuildDefaultResponse() (statusCode int, headers http.Header, body []byte) {
+	return 200, http.Header{"Content-Type": {"application/json; charset=utf-8"}}, []byte("{}")
 }
 
 type mockLogger struct {
 	loggedRequests  []LoggedRequest
 	loggedResponses []LoggedResponse
-	loggedMatches   []*models.MockRule
+	loggedMatches   []*models.MatchResult
+	loggedDelays    []LoggedDelay
+	loggedChaos     []*models.ChaosDecision
+	loggedResources []string
 	defaultLogged   bool
+
+	// Faults are logged while the client is reading from a real connection, so they are guarded
+	faultsMu     sync.Mutex
+	loggedFaults []string
+}
+
+type LoggedDelay struct {
+	Delay     time.Duration
+	Cancelled bool
 }
 
 type LoggedRequest struct {
 	Method string
 	Path   string
 	Params map[string]string
+	Body   []byte
 }
 
 type LoggedResponse struct {
@@ -62,11 +122,12 @@ type LoggedResponse struct {
 	Body       interface{}
 }
 
-func (m *mockLogger) LogRequest(method, path string, params map[string]string) {
+func (m *mockLogger) LogRequest(method, path string, params map[string]string, body []byte) {
 	m.loggedRequests = append(m.loggedRequests, LoggedRequest{
 		Method: method,
 		Path:   path,
 		Params: params,
+		Body:   body,
 	})
 }
 
@@ -77,14 +138,38 @@ func (m *mockLogger) LogResponse(statusCode int, body interface{}) {
 	})
 }
 
-func (m *mockLogger) LogMatch(rule *models.MockRule) {
-	m.loggedMatches = append(m.loggedMatches, rule)
+func (m *mockLogger) LogMatch(match *models.MatchResult) {
+	m.loggedMatches = append(m.loggedMatches, match)
 }
 
 func (m *mockLogger) LogDefault() {
 	m.defaultLogged = true
 }
 
+func (m *mockLogger) LogDelay(delay time.Duration, cancelled bool) {
+	m.loggedDelays = append(m.loggedDelays, LoggedDelay{Delay: delay, Cancelled: cancelled})
+}
+
+func (m *mockLogger) LogFault(fault string) {
+	m.faultsMu.Lock()
+	defer m.faultsMu.Unlock()
+	m.loggedFaults = append(m.loggedFaults, fault)
+}
+
+func (m *mockLogger) LogChaos(decision *models.ChaosDecision) {
+	m.loggedChaos = append(m.loggedChaos, decision)
+}
+
+func (m *mockLogger) LogResource(name string) {
+	m.loggedResources = append(m.loggedResources, name)
+}
+
+func (m *mockLogger) faults() []string {
+	m.faultsMu.Lock()
+	defer m.faultsMu.Unlock()
+	return append([]string(nil), m.loggedFaults...)
+}
+
 // TestNewUniversalHandler tests the creation of a new UniversalHandler instance
 func TestNewUniversalHandler(t *testing.T) {
 	configManager := &mockConfigManager{}
@@ -337,3 +422,670 @@ func TestHandleRequestWithMultipleQueryValues(t *testing.T) {
 		t.Errorf("Expected tags=tag1 (first value), got %s", params["tags"])
 	}
 }
+
+// TestHandleRequestPassesMethodToMatcher tests that the request method reaches the path matcher
+func TestHandleRequestPassesMethodToMatcher(t *testing.T) {
+	configManager := &mockConfigManager{}
+	pathMatcher := &mockPathMatcher{shouldMatch: false}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "DELETE", "/api/users/1", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if pathMatcher.lastRequest.Method != "DELETE" {
+		t.Errorf("Expected matcher to receive method DELETE, got %s", pathMatcher.lastRequest.Method)
+	}
+
+	if pathMatcher.lastRequest.Path != "/api/users/1" {
+		t.Errorf("Expected matcher to receive path /api/users/1, got %s", pathMatcher.lastRequest.Path)
+	}
+}
+
+// TestHandleRequestLogsPathParams tests that captured path parameters reach the logger
+func TestHandleRequestLogsPathParams(t *testing.T) {
+	rule := &models.MockRule{
+		Path:     "/api/users/{id}",
+		Response: map[string]interface{}{"message": "User"},
+		Code:     200,
+	}
+
+	configManager := &mockConfigManager{rules: []models.MockRule{*rule}}
+	pathMatcher := &mockPathMatcher{
+		shouldMatch:    true,
+		ruleToReturn:   rule,
+		paramsToReturn: map[string]string{"id": "42"},
+	}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/users/42", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if len(logger.loggedMatches) != 1 {
+		t.Fatalf("Expected 1 logged match, got %d", len(logger.loggedMatches))
+	}
+
+	if logger.loggedMatches[0].PathParams["id"] != "42" {
+		t.Errorf("Expected logged path param id=42, got %v", logger.loggedMatches[0].PathParams)
+	}
+}
+
+// TestHandleRequestPassesAllQueryValuesToMatcher tests that repeated query keys reach the matcher intact
+func TestHandleRequestPassesAllQueryValuesToMatcher(t *testing.T) {
+	configManager := &mockConfigManager{}
+	pathMatcher := &mockPathMatcher{shouldMatch: false}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(
+		context.Background(),
+		"GET",
+		"/api/test?tags=tag1&tags=tag2&status=active",
+		http.NoBody,
+	)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	tags := pathMatcher.lastRequest.Query["tags"]
+	if len(tags) != 2 || tags[0] != "tag1" || tags[1] != "tag2" {
+		t.Errorf("Expected matcher to receive tags [tag1 tag2], got %v", tags)
+	}
+
+	if pathMatcher.lastRequest.Query.Get("status") != "active" {
+		t.Errorf("Expected matcher to receive status=active, got %v", pathMatcher.lastRequest.Query["status"])
+	}
+}
+
+// TestHandleRequestPassesHeadersToMatcher tests that request headers reach the matcher
+func TestHandleRequestPassesHeadersToMatcher(t *testing.T) {
+	configManager := &mockConfigManager{}
+	pathMatcher := &mockPathMatcher{shouldMatch: false}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/test", http.NoBody)
+	req.Header.Set("X-Api-Version", "2")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if got := pathMatcher.lastRequest.Header.Get("x-api-version"); got != "2" {
+		t.Errorf("Expected matcher to receive X-Api-Version=2, got %q", got)
+	}
+}
+
+// TestHandleRequestBuffersBody tests that the body is read once and shared by logging and matching
+func TestHandleRequestBuffersBody(t *testing.T) {
+	configManager := &mockConfigManager{}
+	pathMatcher := &mockPathMatcher{shouldMatch: false}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	var bodyAfterHandler []byte
+	router.Any("/*path", handler.HandleRequest, func(c *gin.Context) {
+		bodyAfterHandler, _ = io.ReadAll(c.Request.Body)
+	})
+
+	payload := `{"user":{"type":"admin"}}`
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", "/api/users", strings.NewReader(payload))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if string(logger.loggedRequests[0].Body) != payload {
+		t.Errorf("Expected logged body %s, got %s", payload, logger.loggedRequests[0].Body)
+	}
+
+	if string(pathMatcher.lastRequest.Body) != payload {
+		t.Errorf("Expected matcher body %s, got %s", payload, pathMatcher.lastRequest.Body)
+	}
+
+	if string(bodyAfterHandler) != payload {
+		t.Errorf("Expected body to remain readable, got %s", bodyAfterHandler)
+	}
+}
+
+// TestHandleRequestWritesResponseHeaders tests that rule headers are sent, including a custom Content-Type
+func TestHandleRequestWritesResponseHeaders(t *testing.T) {
+	rule := &models.MockRule{
+		Path: "/api/login",
+		Code: 201,
+		ResponseHeaders: map[string]models.HeaderValues{
+			"Content-Type": {"application/vnd.api+json"},
+			"Set-Cookie":   {"a=1", "b=2"},
+		},
+		Response: map[string]interface{}{"ok": true},
+	}
+
+	configManager := &mockConfigManager{rules: []models.MockRule{*rule}}
+	pathMatcher := &mockPathMatcher{shouldMatch: true, ruleToReturn: rule}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", "/api/login", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != 201 {
+		t.Errorf("Expected status code 201, got %d", w.Code)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != "application/vnd.api+json" {
+		t.Errorf("Expected custom Content-Type, got %q", got)
+	}
+
+	if got := w.Header().Values("Set-Cookie"); len(got) != 2 {
+		t.Errorf("Expected two Set-Cookie headers, got %v", got)
+	}
+}
+
+// TestHandleRequestWritesRawBody tests that non-JSON bodies are written verbatim
+func TestHandleRequestWritesRawBody(t *testing.T) {
+	xml := `<user id="1"/>`
+	rule := &models.MockRule{
+		Path:            "/api/user.xml",
+		Code:            200,
+		BodyText:        &xml,
+		ResponseHeaders: map[string]models.HeaderValues{"Content-Type": {"application/xml"}},
+	}
+
+	configManager := &mockConfigManager{rules: []models.MockRule{*rule}}
+	pathMatcher := &mockPathMatcher{shouldMatch: true, ruleToReturn: rule}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/user.xml", http.NoBody)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Body.String() != xml {
+		t.Errorf("Expected body %q, got %q", xml, w.Body.String())
+	}
+
+	if got := w.Header().Get("Content-Type"); got != "application/xml" {
+		t.Errorf("Expected Content-Type 'application/xml', got %q", got)
+	}
+}
+
+// TestHandleRequestAppliesDelay tests that the rule delay wins over the default delay and is logged
+func TestHandleRequestAppliesDelay(t *testing.T) {
+	rule := &models.MockRule{
+		Path:     "/api/slow",
+		Code:     200,
+		Response: map[string]interface{}{},
+		Delay:    &models.DelaySpec{Fixed: testutil.Ptr(models.Duration(30 * time.Millisecond))},
+	}
+
+	configManager := &mockConfigManager{rules: []models.MockRule{*rule}}
+	pathMatcher := &mockPathMatcher{shouldMatch: true, ruleToReturn: rule}
+	responseBuilder := &mockResponseBuilder{}
+	logger := &mockLogger{}
+
+	handler := NewUniversalHandler(configManager, pathMatcher, responseBuilder, logger,
+		WithDefaultDelay(&models.DelaySpec{Fixed: testutil.Ptr(models.Duration(time.Hour))}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/slow", http.NoBody)
+	w := httptest.NewRecorder()
+
+	start := time.Now()
+	router.ServeHTTP(w, req)
+	elapsed := time.Since(start)
+
+	if elapsed < 30*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("Expected a delay of about 30ms, took %v", elapsed)
+	}
+	if len(logger.loggedDelays) != 1 || logger.loggedDelays[0].Delay != 30*time.Millisecond || logger.loggedDelays[0].Cancelled {
+		t.Errorf("Expected a completed 30ms delay to be logged, got %+v", logger.loggedDelays)
+	}
+	if w.Code != 200 || len(logger.loggedResponses) != 1 {
+		t.Errorf("Expected the response to be sent after the delay, got code %d", w.Code)
+	}
+}
+
+// TestHandleRequestDefaultDelayForUnmatched tests that the default delay also applies without a matching rule
+func TestHandleRequestDefaultDelayForUnmatched(t *testing.T) {
+	logger := &mockLogger{}
+	handler := NewUniversalHandler(&mockConfigManager{}, &mockPathMatcher{}, &mockResponseBuilder{}, logger,
+		WithDefaultDelay(&models.DelaySpec{Fixed: testutil.Ptr(models.Duration(10 * time.Millisecond))}))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/unknown", http.NoBody)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(logger.loggedDelays) != 1 || logger.loggedDelays[0].Delay != 10*time.Millisecond {
+		t.Errorf("Expected the default delay to be logged, got %+v", logger.loggedDelays)
+	}
+}
+
+// TestHandleRequestDelayCancelled tests that a cancelled request stops waiting and sends nothing
+func TestHandleRequestDelayCancelled(t *testing.T) {
+	rule := &models.MockRule{
+		Path:  "/api/slow",
+		Code:  200,
+		Delay: &models.DelaySpec{Fixed: testutil.Ptr(models.Duration(time.Hour))},
+	}
+
+	logger := &mockLogger{}
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}},
+		&mockPathMatcher{shouldMatch: true, ruleToReturn: rule}, &mockResponseBuilder{}, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/api/slow", http.NoBody)
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the handler to stop waiting when the request is cancelled")
+	}
+
+	if len(logger.loggedDelays) != 1 || !logger.loggedDelays[0].Cancelled {
+		t.Errorf("Expected a cancelled delay to be logged, got %+v", logger.loggedDelays)
+	}
+	if len(logger.loggedResponses) != 0 {
+		t.Errorf("Expected no response to be logged, got %+v", logger.loggedResponses)
+	}
+}
+
+// TestHandleRequestChaosError tests that chaos replaces the response with an error and logs the decision
+func TestHandleRequestChaosError(t *testing.T) {
+	rule := &models.MockRule{Path: "/api/users", Code: 200, Response: map[string]interface{}{"users": []string{}}}
+	engine, err := chaos.NewEngine(&models.ChaosConfig{
+		Enabled:    true,
+		Percentage: 100,
+		Actions:    []string{models.ChaosActionError},
+		ErrorCodes: []int{503},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	logger := &mockLogger{}
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}},
+		&mockPathMatcher{shouldMatch: true, ruleToReturn: rule}, &mockResponseBuilder{}, logger, WithChaos(engine))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/users", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 503 {
+		t.Errorf("Expected status 503, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"chaos":"injected"`) {
+		t.Errorf("Expected a chaos error body, got %s", w.Body.String())
+	}
+	if len(logger.loggedChaos) != 1 || logger.loggedChaos[0].Action != models.ChaosActionError {
+		t.Errorf("Expected the chaos decision to be logged, got %+v", logger.loggedChaos)
+	}
+}
+
+// TestHandleRequestChaosSkipsUnmatched tests that chaos only applies to matched requests
+func TestHandleRequestChaosSkipsUnmatched(t *testing.T) {
+	engine, err := chaos.NewEngine(&models.ChaosConfig{Enabled: true, Percentage: 100})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	logger := &mockLogger{}
+	handler := NewUniversalHandler(&mockConfigManager{}, &mockPathMatcher{}, &mockResponseBuilder{}, logger, WithChaos(engine))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/unknown", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != 200 || len(logger.loggedChaos) != 0 {
+		t.Errorf("Expected the default response without chaos, got %d and %+v", w.Code, logger.loggedChaos)
+	}
+}
+
+// TestHandleRequestSequenceFallthrough tests that a sequence advances per call and then falls through to the next rule
+func TestHandleRequestSequenceFallthrough(t *testing.T) {
+	rules := []models.MockRule{
+		{
+			ID:          "job",
+			Path:        "/api/job",
+			SequenceEnd: models.SequenceEndFallthrough,
+			Responses: []models.ResponseVariant{
+				{Response: map[string]interface{}{"status": "pending"}},
+				{Response: map[string]interface{}{"status": "running"}},
+			},
+		},
+		{ID: "done", Path: "/api/job", Response: map[string]interface{}{"status": "done"}},
+	}
+	for i := range rules {
+		if err := response.CompileResponse(&rules[i], ""); err != nil {
+			t.Fatalf("CompileResponse() error = %v", err)
+		}
+	}
+
+	counters := sequence.NewCounters()
+	pathMatcher := matcher.NewPathMatcher()
+	pathMatcher.SetRuleGuard(counters.Available)
+	handler := NewUniversalHandler(&mockConfigManager{rules: rules}, pathMatcher, response.NewResponseBuilder(),
+		&mockLogger{}, WithSequenceCounters(counters))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	for _, want := range []string{"pending", "running", "done", "done"} {
+		req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/job", http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if !strings.Contains(w.Body.String(), `"status":"`+want+`"`) {
+			t.Errorf("Expected status %q, got %s", want, w.Body.String())
+		}
+	}
+
+	counters.Reset("job")
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/job", http.NoBody)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), `"status":"pending"`) {
+		t.Errorf("Expected the sequence to start over after a reset, got %s", w.Body.String())
+	}
+}
+
+// TestHandleRequestScenario tests that the same request is answered according to the scenario state
+func TestHandleRequestScenario(t *testing.T) {
+	rules := []models.MockRule{
+		{Path: "/cart", Scenario: "cart", RequiredState: models.ScenarioStateStarted,
+			Response: map[string]interface{}{"items": 0}},
+		{Path: "/cart/items", Method: models.MethodList{"POST"}, Scenario: "cart", NewState: "item-added",
+			Response: map[string]interface{}{"added": true}},
+		{Path: "/cart", Scenario: "cart", RequiredState: "item-added", Response: map[string]interface{}{"items": 1}},
+	}
+
+	store := config.NewScenarioStore()
+	store.Register(rules)
+	pathMatcher := matcher.NewPathMatcher()
+	pathMatcher.SetRuleGuard(store.Allows)
+	handler := NewUniversalHandler(&mockConfigManager{rules: rules}, pathMatcher, response.NewResponseBuilder(),
+		&mockLogger{}, WithScenarioStore(store))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	steps := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{"GET", "/cart", `{"items":0}`},
+		{"POST", "/cart/items", `{"added":true}`},
+		{"GET", "/cart", `{"items":1}`},
+	}
+
+	for _, step := range steps {
+		req, _ := http.NewRequestWithContext(context.Background(), step.method, step.path, http.NoBody)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Body.String() != step.want {
+			t.Errorf("%s %s: expected %s, got %s", step.method, step.path, step.want, w.Body.String())
+		}
+	}
+}
+
+// TestHandleRequestExhaustedSequenceKeepsScenario tests that a rule skipped because its sequence ran out
+// after matching does not move its scenario on
+func TestHandleRequestExhaustedSequenceKeepsScenario(t *testing.T) {
+	rule := &models.MockRule{
+		ID:          "job",
+		Path:        "/api/job",
+		Scenario:    "job",
+		NewState:    "finished",
+		SequenceEnd: models.SequenceEndFallthrough,
+		Responses:   []models.ResponseVariant{{Code: 202}},
+	}
+	if err := response.CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse() error = %v", err)
+	}
+
+	store := config.NewScenarioStore()
+	store.Register([]models.MockRule{*rule})
+	counters := sequence.NewCounters()
+	counters.Next(rule)
+
+	// The mock matcher ignores guards, as if another request used up the sequence after matching
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}},
+		&mockPathMatcher{shouldMatch: true, ruleToReturn: rule}, response.NewResponseBuilder(), &mockLogger{},
+		WithSequenceCounters(counters), WithScenarioStore(store))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "GET", "/api/job", http.NoBody)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got := store.States(); len(got) != 1 || got[0].State != models.ScenarioStateStarted {
+		t.Errorf("Expected the scenario to stay in %s, got %v", models.ScenarioStateStarted, got)
+	}
+}
+
+// guardlessMatcher always matches its rule, as if every request had matched before the others claimed it
+type guardlessMatcher struct {
+	rule *models.MockRule
+}
+
+func (m guardlessMatcher) FindMatch(req *models.Request, _ []models.MockRule) (*models.MatchResult, bool) {
+	return &models.MatchResult{Rule: m.rule, Request: req}, true
+}
+
+// TestHandleRequestScenarioRaceKeepsSequence tests that requests losing the scenario transition
+// do not use up steps of the rule's response sequence
+func TestHandleRequestScenarioRaceKeepsSequence(t *testing.T) {
+	rule := &models.MockRule{
+		ID:            "checkout",
+		Path:          "/checkout",
+		Scenario:      "cart",
+		RequiredState: models.ScenarioStateStarted,
+		NewState:      "paid",
+		Responses:     []models.ResponseVariant{{Code: 201}, {Code: 200}, {Code: 200}},
+	}
+	if err := response.CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse() error = %v", err)
+	}
+
+	store := config.NewScenarioStore()
+	store.Register([]models.MockRule{*rule})
+	counters := sequence.NewCounters()
+	// The real logger is safe for concurrent use, unlike mockLogger
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}}, guardlessMatcher{rule: rule},
+		response.NewResponseBuilder(), logger.NewLogger(), WithSequenceCounters(counters), WithScenarioStore(store))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequestWithContext(context.Background(), "POST", "/checkout", http.NoBody)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+	}
+	wg.Wait()
+
+	// Only the request that moved the scenario on may claim a step
+	counts := counters.Counts()
+	if len(counts) != 1 || counts[0].Count != 1 {
+		t.Errorf("Expected exactly one claimed step, got %v", counts)
+	}
+}
+
+// TestHandleRequestResources tests that requests no rule matches are served by the in-memory resources
+func TestHandleRequestResources(t *testing.T) {
+	store, err := resource.NewStore([]models.ResourceConfig{{Name: "users", BasePath: "/api/users"}})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	logger := &mockLogger{}
+	handler := NewUniversalHandler(&mockConfigManager{}, &mockPathMatcher{}, &mockResponseBuilder{}, logger,
+		WithResources(store))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", "/api/users", strings.NewReader(`{"name":"Alice"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/users/1" {
+		t.Errorf("Expected 201 with Location /api/users/1, got %d %v", w.Code, w.Header())
+	}
+	if len(logger.loggedResources) != 1 || logger.loggedResources[0] != "users" || logger.defaultLogged {
+		t.Errorf("Expected the resource to be logged instead of the default, got %v", logger.loggedResources)
+	}
+
+	req, _ = http.NewRequestWithContext(context.Background(), "GET", "/api/users/1", http.NoBody)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != `{"id":1,"name":"Alice"}` {
+		t.Errorf("Expected the created user, got %d %s", w.Code, w.Body.String())
+	}
+
+	req, _ = http.NewRequestWithContext(context.Background(), "GET", "/unknown", http.NoBody)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if !logger.defaultLogged {
+		t.Errorf("Expected paths outside the resources to get the default response")
+	}
+}
+
+// TestHandleRequestDelayCancelledKeepsResources tests that a request cancelled during its delay changes no resource
+func TestHandleRequestDelayCancelledKeepsResources(t *testing.T) {
+	store, err := resource.NewStore([]models.ResourceConfig{{Name: "users", BasePath: "/api/users"}})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	slow := gin.New()
+	slow.Any("/*path", NewUniversalHandler(&mockConfigManager{}, &mockPathMatcher{}, &mockResponseBuilder{}, &mockLogger{},
+		WithResources(store), WithDefaultDelay(&models.DelaySpec{Fixed: testutil.Ptr(models.Duration(time.Hour))})).HandleRequest)
+	fast := gin.New()
+	fast.Any("/*path", NewUniversalHandler(&mockConfigManager{}, &mockPathMatcher{}, &mockResponseBuilder{}, &mockLogger{},
+		WithResources(store)).HandleRequest)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/api/users", strings.NewReader(`{"name":"Alice"}`))
+	slow.ServeHTTP(httptest.NewRecorder(), req)
+
+	req, _ = http.NewRequestWithContext(context.Background(), "GET", "/api/users/1", http.NoBody)
+	w := httptest.NewRecorder()
+	fast.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected the cancelled request not to create a user, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+// TestHandleRequestRecordsJournal tests that requests are recorded with the rule that answered them
+func TestHandleRequestRecordsJournal(t *testing.T) {
+	rule := &models.MockRule{ID: "users", Path: "/api/users", Code: 201}
+	requests := journal.NewJournal(10)
+	handler := NewUniversalHandler(&mockConfigManager{rules: []models.MockRule{*rule}},
+		&mockPathMatcher{shouldMatch: true, ruleToReturn: rule}, &mockResponseBuilder{}, &mockLogger{}, WithJournal(requests))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Any("/*path", handler.HandleRequest)
+
+	req, _ := http.NewRequestWithContext(context.Background(), "POST", "/api/users?page=2", strings.NewReader(`{"name":"Alice"}`))
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	entries := requests.Entries(nil)
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 recorded request, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Method != "POST" || entry.Path != "/api/users" || entry.Query.Get("page") != "2" ||
+		entry.Body != `{"name":"Alice"}` {
+		t.Errorf("Expected the request to be recorded, got %+v", entry)
+	}
+	if entry.RuleID != "users" || entry.StatusCode != 201 {
+		t.Errorf("Expected rule users and status 201, got %q and %d", entry.RuleID, entry.StatusCode)
+	}
+}
@@ -1,38 +1,83 @@
 package interfaces
 
-import "mock-service/internal/models"
+import (
+	"net/http"
+	"time"
+
+	"mock-service/internal/models"
+)
 
 // ConfigManager handles loading and managing JSON configuration files
+// Rules can be changed at runtime; implementations must be safe for concurrent use
 type ConfigManager interface {
 	// LoadConfig loads configuration from the specified file path
 	LoadConfig(filePath string) error
 	// GetConfig returns the current list of mock rules
+	// The returned slice must not be modified; rule changes publish a new one
 	GetConfig() []models.MockRule
+	// GetRule returns the rule with the given ID
+	GetRule(id string) (models.MockRule, bool)
+	// AddRule validates a rule and inserts it at position, or appends it when position is out of range
+	// A rule without an ID gets a generated one; returns the compiled rule
+	AddRule(rule models.MockRule, position int) (models.MockRule, error)
+	// UpdateRule replaces the rule with the given ID, keeping its position; returns the compiled rule
+	UpdateRule(id string, rule models.MockRule) (models.MockRule, error)
+	// DeleteRule removes the rule with the given ID
+	DeleteRule(id string) error
+	// ReorderRules puts the rules in the order of the given IDs, which must list every rule exactly once
+	ReorderRules(ids []string) error
 }
 
-// PathMatcher handles matching request paths against configured rules
+// PathMatcher handles matching requests against configured rules
 type PathMatcher interface {
-	// FindMatch finds the first matching rule for the given request path
-	// Returns the matched rule and true if found, nil and false otherwise
-	FindMatch(requestPath string, rules []models.MockRule) (*models.MockRule, bool)
+	// FindMatch finds the matching rule for the given request (method, path, query and headers)
+	// Returns the match result (rule and captured path parameters) and true if found, nil and false otherwise
+	FindMatch(req *models.Request, rules []models.MockRule) (*models.MatchResult, bool)
+}
+
+// ScenarioStore tracks the current state of named scenarios
+// Implementations must be safe for concurrent use
+type ScenarioStore interface {
+	// Allows reports whether the rule's required state, if any, is the current state of its scenario
+	Allows(rule *models.MockRule) bool
+	// Advance moves the rule's scenario to its new state if the rule is still allowed
+	// Returns the state of the scenario after the call and false when the rule is no longer allowed
+	Advance(rule *models.MockRule) (string, bool)
+	// States returns the current state of every scenario, sorted by name
+	States() []models.ScenarioState
+	// Reset moves a scenario back to its initial state; returns false for an unknown scenario
+	Reset(name string) bool
+	// ResetAll moves every scenario back to its initial state
+	ResetAll()
+	// Restore sets the state of the known scenarios, e.g. from a saved snapshot; unknown scenarios are ignored
+	Restore(states []models.ScenarioState)
 }
 
 // ResponseBuilder handles building HTTP responses based on mock rules
 type ResponseBuilder interface {
-	// BuildResponse builds a response based on the provided mock rule
-	BuildResponse(rule *models.MockRule) (statusCode int, body interface{})
+	// BuildResponse builds a response based on the matched mock rule and its captured path parameters
+	// The body is returned encoded, with its Content-Type set in the headers
+	BuildResponse(match *models.MatchResult) (statusCode int, headers http.Header, body []byte)
 	// BuildDefaultResponse builds a default response when no rule matches
-	BuildDefaultResponse() (statusCode int, body interface{})
+	BuildDefaultResponse() (statusCode int, headers http.Header, body []byte)
 }
 
 // Logger provides structured logging functionality for the mock service
 type Logger interface {
-	// LogRequest logs incoming HTTP request details
-	LogRequest(method, path string, params map[string]string)
-	// LogResponse logs outgoing HTTP response details
+	// LogRequest logs incoming HTTP request details, including the request body when present
+	LogRequest(method, path string, params map[string]string, body []byte)
+	// LogResponse logs outgoing HTTP response details; an encoded []byte body is logged like a request body
 	LogResponse(statusCode int, body interface{})
-	// LogMatch logs when a rule is matched
-	LogMatch(rule *models.MockRule)
+	// LogMatch logs when a rule is matched, including any captured path parameters
+	LogMatch(match *models.MatchResult)
 	// LogDefault logs when default response is used
 	LogDefault()
+	// LogDelay logs the delay applied before a response and whether the client went away while waiting
+	LogDelay(delay time.Duration, cancelled bool)
+	// LogFault logs a fault about to be injected instead of the response
+	LogFault(fault string)
+	// LogChaos logs a chaos decision, whether or not the request was disrupted
+	LogChaos(decision *models.ChaosDecision)
+	// LogResource logs when a request no rule matches is handled by an in-memory resource
+	LogResource(name string)
 }
@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"mock-service/internal/matcher"
+	"mock-service/internal/models"
+)
+
+// Filter selects recorded requests; unset fields accept every request
+type Filter struct {
+	// Path is a path glob, as in rules with "pathMatch": "glob"; a path without wildcards must be equal
+	Path *regexp.Regexp
+	// Method is compared case-insensitively
+	Method string
+	// RuleID selects the requests matched by one rule
+	RuleID string
+	// Since and Until bound the time the request was received, inclusively
+	Since time.Time
+	Until time.Time
+	// Body applies a JSONPath predicate to the request body, as in rule body matchers
+	Body *models.BodyMatcher
+	// Limit keeps only the most recent requests accepted by the filter
+	Limit int
+}
+
+// ParseFilter builds a filter from query parameters:
+// path, method, rule, since and until (RFC 3339 times), jsonPath with an optional jsonPathValue, and limit
+func ParseFilter(query url.Values) (*Filter, error) {
+	filter := &Filter{
+		Method: strings.ToUpper(query.Get("method")),
+		RuleID: query.Get("rule"),
+	}
+
+	if path := query.Get("path"); path != "" {
+		pattern, err := matcher.CompileGlob(path)
+		if err != nil {
+			return nil, err
+		}
+		filter.Path = pattern
+	}
+
+	var err error
+	if filter.Since, err = parseTime(query, "since"); err != nil {
+		return nil, err
+	}
+	if filter.Until, err = parseTime(query, "until"); err != nil {
+		return nil, err
+	}
+
+	if expression := query.Get("jsonPath"); expression != "" {
+		var vm models.ValueMatcher
+		if query.Has("jsonPathValue") {
+			value := query.Get("jsonPathValue")
+			vm.Equals = &value
+		}
+		filter.Body = &models.BodyMatcher{JSONPath: map[string]models.ValueMatcher{expression: vm}}
+		if err := matcher.CompileBodyMatcher(filter.Body); err != nil {
+			return nil, err
+		}
+	} else if query.Has("jsonPathValue") {
+		return nil, fmt.Errorf("jsonPathValue requires jsonPath")
+	}
+
+	if text := query.Get("limit"); text != "" {
+		limit, err := strconv.Atoi(text)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("limit must be a positive integer, got %q", text)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// parseTime parses an optional RFC 3339 time query parameter
+func parseTime(query url.Values, name string) (time.Time, error) {
+	text := query.Get(name)
+	if text == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time such as 2024-01-14T15:30:45Z: %w", name, err)
+	}
+	return parsed, nil
+}
+
+// accepts reports whether the entry satisfies every set field of the filter; a nil filter accepts everything
+func (f *Filter) accepts(entry *Entry) bool {
+	if f == nil {
+		return true
+	}
+	switch {
+	case f.Path != nil && !f.Path.MatchString(entry.Path),
+		f.Method != "" && entry.Method != f.Method,
+		f.RuleID != "" && entry.RuleID != f.RuleID,
+		!f.Since.IsZero() && entry.Timestamp.Before(f.Since),
+		!f.Until.IsZero() && entry.Timestamp.After(f.Until):
+		return false
+	}
+	// Body predicates get a request of their own: the parsed body is cached on it
+	return f.Body == nil || matcher.MatchBody(f.Body, &models.Request{Body: entry.body, Header: entry.Headers})
+}
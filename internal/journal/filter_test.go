@@ -0,0 +1,70 @@
+package journal
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestFilter tests selecting recorded requests with query parameters
+func TestFilter(t *testing.T) {
+	start := time.Date(2024, 1, 14, 15, 30, 0, 0, time.UTC)
+	j := NewJournal(10)
+	j.Record(newRequest(t, "POST", "/api/users", `{"name":"Alice","role":"admin"}`), start, &Outcome{RuleID: "create"})
+	j.Record(newRequest(t, "GET", "/api/users/1", ""), start.Add(time.Minute), &Outcome{RuleID: "get"})
+	j.Record(newRequest(t, "POST", "/api/users", `{"name":"Bob"}`), start.Add(2*time.Minute), &Outcome{RuleID: "create"})
+	j.Record(newRequest(t, "GET", "/health", ""), start.Add(3*time.Minute), &Outcome{})
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"no filter", "", "/api/users,/api/users/1,/api/users,/health"},
+		{"exact path", "path=/api/users", "/api/users,/api/users"},
+		{"path glob", "path=/api/**", "/api/users,/api/users/1,/api/users"},
+		{"method is case-insensitive", "method=get", "/api/users/1,/health"},
+		{"rule", "rule=get", "/api/users/1"},
+		{"time range", "since=2024-01-14T15:31:00Z&until=2024-01-14T15:32:00Z", "/api/users/1,/api/users"},
+		{"json path exists", "jsonPath=$.role", "/api/users"},
+		{"json path value", "jsonPath=$.name&jsonPathValue=Bob", "/api/users"},
+		{"limit keeps the most recent", "method=POST&limit=1", "/api/users"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			filter, err := ParseFilter(query)
+			if err != nil {
+				t.Fatalf("ParseFilter() error = %v", err)
+			}
+			if got := paths(j.Entries(filter)); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// The limit and the body predicate pick the right requests, not just the right paths
+	query, _ := url.ParseQuery("jsonPath=$.name&jsonPathValue=Bob")
+	filter, _ := ParseFilter(query)
+	if entries := j.Entries(filter); len(entries) != 1 || entries[0].ID != 3 {
+		t.Errorf("Expected request 3, got %+v", entries)
+	}
+}
+
+// TestParseFilterErrors tests that invalid query parameters are refused
+func TestParseFilterErrors(t *testing.T) {
+	for _, raw := range []string{
+		"since=yesterday",
+		"until=2024-01-14",
+		"limit=0",
+		"limit=ten",
+		"jsonPathValue=Bob",
+		"jsonPath=name",
+	} {
+		query, _ := url.ParseQuery(raw)
+		if _, err := ParseFilter(query); err == nil {
+			t.Errorf("Expected %q to be refused", raw)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package journal records the requests received by the mock so that tests can assert on them
+package journal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"mock-service/internal/models"
+)
+
+// Entry is one request recorded in the journal, together with how it was answered
+type Entry struct {
+	// ID numbers the entries in the order they were recorded, starting at 1; it keeps growing after Clear
+	ID uint64 `json:"id"`
+	// Timestamp is when the request was received
+	Timestamp time.Time   `json:"timestamp"`
+	Method    string      `json:"method"`
+	Path      string      `json:"path"`
+	Query     url.Values  `json:"query,omitempty"`
+	Headers   http.Header `json:"headers,omitempty"`
+	// Body is the request body when it is text; BodyBase64 holds binary bodies instead
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+	// RuleID is the ID of the matched rule; empty when no rule matched
+	RuleID string `json:"ruleId,omitempty"`
+	// Resource is the name of the in-memory resource that answered a request no rule matched
+	Resource string `json:"resource,omitempty"`
+	// StatusCode is the status sent; 0 when a fault was injected or the client went away
+	StatusCode int    `json:"status,omitempty"`
+	Fault      string `json:"fault,omitempty"`
+	// Cancelled is true when the client went away during the delay
+	Cancelled bool `json:"cancelled,omitempty"`
+	// DurationMs is the time spent answering the request, including any delay
+	DurationMs float64 `json:"durationMs"`
+
+	body []byte
+}
+
+// Outcome is how a request was answered, filled in by the handler as it goes
+type Outcome struct {
+	RuleID     string
+	Resource   string
+	StatusCode int
+	Fault      string
+	Cancelled  bool
+}
+
+// Journal keeps the most recent requests in memory, up to its capacity, and optionally writes
+// every request to a sink as a JSON line. It is safe for concurrent use
+type Journal struct {
+	mu       sync.RWMutex
+	capacity int
+	entries  []Entry
+	// next is the position of the oldest entry once the journal is full, where the next entry goes
+	next   int
+	lastID uint64
+
+	sink    io.Writer
+	onError func(error)
+}
+
+// Option configures optional parts of a Journal
+type Option func(*Journal)
+
+// WithSink writes every recorded request to w as one JSON line
+// Write errors are passed to onError and do not stop recording
+func WithSink(w io.Writer, onError func(error)) Option {
+	return func(j *Journal) {
+		j.sink = w
+		j.onError = onError
+	}
+}
+
+// NewJournal creates a new instance of Journal keeping at most capacity requests in memory
+// A capacity of 0 keeps none, which is only useful with a sink
+func NewJournal(capacity int, opts ...Option) *Journal {
+	j := &Journal{capacity: max(capacity, 0)}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// Record adds a request received at the given time and its outcome to the journal
+// When the journal is full the oldest request is dropped
+func (j *Journal) Record(req *models.Request, received time.Time, outcome *Outcome) {
+	entry := Entry{
+		Timestamp:  received.UTC(),
+		Method:     req.Method,
+		Path:       req.Path,
+		Query:      req.Query,
+		Headers:    req.Header.Clone(),
+		RuleID:     outcome.RuleID,
+		Resource:   outcome.Resource,
+		StatusCode: outcome.StatusCode,
+		Fault:      outcome.Fault,
+		Cancelled:  outcome.Cancelled,
+		DurationMs: float64(time.Since(received)) / float64(time.Millisecond),
+		body:       req.Body,
+	}
+	if utf8.Valid(req.Body) {
+		entry.Body = string(req.Body)
+	} else {
+		entry.BodyBase64 = base64.StdEncoding.EncodeToString(req.Body)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.lastID++
+	entry.ID = j.lastID
+	j.store(entry)
+	j.write(&entry)
+}
+
+// store adds an entry to the ring; the caller must hold the write lock
+func (j *Journal) store(entry Entry) {
+	switch {
+	case j.capacity == 0:
+	case len(j.entries) < j.capacity:
+		j.entries = append(j.entries, entry)
+	default:
+		j.entries[j.next] = entry
+		j.next = (j.next + 1) % j.capacity
+	}
+}
+
+// write appends an entry to the sink, if any; the caller must hold the write lock so lines are in ID order
+func (j *Journal) write(entry *Entry) {
+	if j.sink == nil {
+		return
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		_, err = j.sink.Write(append(line, '\n'))
+	}
+	if err != nil && j.onError != nil {
+		j.onError(fmt.Errorf("failed to write request %d to the journal sink: %w", entry.ID, err))
+	}
+}
+
+// Entries returns the recorded requests accepted by filter, oldest first
+// A nil filter accepts every request
+func (j *Journal) Entries(filter *Filter) []Entry {
+	j.mu.RLock()
+	ordered := make([]Entry, 0, len(j.entries))
+	ordered = append(ordered, j.entries[j.next:]...)
+	ordered = append(ordered, j.entries[:j.next]...)
+	j.mu.RUnlock()
+
+	result := make([]Entry, 0, len(ordered))
+	for i := range ordered {
+		if filter.accepts(&ordered[i]) {
+			result = append(result, ordered[i])
+		}
+	}
+	if filter != nil && filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[len(result)-filter.Limit:]
+	}
+	return result
+}
+
+// Clear drops every recorded request; the sink is left as is
+func (j *Journal) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = nil
+	j.next = 0
+}
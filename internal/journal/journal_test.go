@@ -0,0 +1,151 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mock-service/internal/models"
+)
+
+// newRequest builds a buffered request as the handler does
+func newRequest(t *testing.T, method, target, body string) *models.Request {
+	t.Helper()
+	httpReq, err := http.NewRequestWithContext(context.Background(), method, target, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequestWithContext() error = %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	req, err := models.NewRequest(httpReq)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	return req
+}
+
+// paths returns the paths of entries in order
+func paths(entries []Entry) string {
+	result := make([]string, 0, len(entries))
+	for i := range entries {
+		result = append(result, entries[i].Path)
+	}
+	return strings.Join(result, ",")
+}
+
+// TestRecord tests that requests are recorded with their outcome
+func TestRecord(t *testing.T) {
+	j := NewJournal(10)
+	received := time.Now()
+	j.Record(newRequest(t, "POST", "/users?page=2", `{"name":"Alice"}`), received,
+		&Outcome{RuleID: "users", StatusCode: http.StatusCreated})
+	j.Record(newRequest(t, "PUT", "/files", "\xff\xfe"), received, &Outcome{Fault: models.FaultConnectionReset})
+
+	entries := j.Entries(nil)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.ID != 1 || first.Method != "POST" || first.Path != "/users" || first.Query.Get("page") != "2" {
+		t.Errorf("Expected the request line to be recorded, got %+v", first)
+	}
+	if first.Body != `{"name":"Alice"}` || first.Headers.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected the body and headers to be recorded, got %q %v", first.Body, first.Headers)
+	}
+	if first.RuleID != "users" || first.StatusCode != http.StatusCreated || !first.Timestamp.Equal(received) {
+		t.Errorf("Expected the outcome to be recorded, got %+v", first)
+	}
+	if second := entries[1]; second.ID != 2 || second.Body != "" || second.BodyBase64 != "//4=" || second.Fault == "" {
+		t.Errorf("Expected a base64 body and the fault, got %+v", second)
+	}
+}
+
+// TestRecordEvictsOldest tests that a full journal drops its oldest requests
+func TestRecordEvictsOldest(t *testing.T) {
+	j := NewJournal(3)
+	for i := 1; i <= 5; i++ {
+		j.Record(newRequest(t, "GET", fmt.Sprintf("/%d", i), ""), time.Now(), &Outcome{})
+	}
+	if got := paths(j.Entries(nil)); got != "/3,/4,/5" {
+		t.Errorf("Expected the 3 most recent requests, got %s", got)
+	}
+
+	j.Clear()
+	if got := j.Entries(nil); len(got) != 0 {
+		t.Errorf("Expected no requests after Clear, got %d", len(got))
+	}
+	j.Record(newRequest(t, "GET", "/6", ""), time.Now(), &Outcome{})
+	if entries := j.Entries(nil); len(entries) != 1 || entries[0].ID != 6 {
+		t.Errorf("Expected ids to keep growing after Clear, got %+v", entries)
+	}
+
+	none := NewJournal(0)
+	none.Record(newRequest(t, "GET", "/", ""), time.Now(), &Outcome{})
+	if got := none.Entries(nil); len(got) != 0 {
+		t.Errorf("Expected a journal of capacity 0 to keep nothing, got %d", len(got))
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("disk full")
+}
+
+// TestSink tests that every request is written to the sink as a JSON line
+func TestSink(t *testing.T) {
+	var sink bytes.Buffer
+	j := NewJournal(1, WithSink(&sink, nil))
+	j.Record(newRequest(t, "GET", "/a", ""), time.Now(), &Outcome{StatusCode: http.StatusOK})
+	j.Record(newRequest(t, "GET", "/b", ""), time.Now(), &Outcome{StatusCode: http.StatusOK})
+
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", sink.String())
+	}
+	var entry Entry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Invalid line %s: %v", lines[0], err)
+	}
+	if entry.ID != 1 || entry.Path != "/a" || entry.StatusCode != http.StatusOK {
+		t.Errorf("Expected the first request, got %+v", entry)
+	}
+
+	var reported error
+	failing := NewJournal(1, WithSink(failingWriter{}, func(err error) { reported = err }))
+	failing.Record(newRequest(t, "GET", "/a", ""), time.Now(), &Outcome{})
+	if reported == nil || len(failing.Entries(nil)) != 1 {
+		t.Errorf("Expected the error to be reported and the request kept, got %v", reported)
+	}
+}
+
+// TestRecordConcurrent tests recording and reading requests concurrently
+func TestRecordConcurrent(t *testing.T) {
+	j := NewJournal(50)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 10; k++ {
+				j.Record(&models.Request{Method: "GET", Path: "/"}, time.Now(), &Outcome{})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = j.Entries(nil)
+		}()
+	}
+	wg.Wait()
+
+	entries := j.Entries(nil)
+	if len(entries) != 50 || entries[49].ID != 200 {
+		t.Errorf("Expected the 50 most recent of 200 requests, got %d ending with %d", len(entries), entries[len(entries)-1].ID)
+	}
+}
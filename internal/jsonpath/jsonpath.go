@@ -0,0 +1,226 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression
+// The supported subset covers the expressions commonly used for request matching:
+//
+//	$                 the root document
+//	.name, ['name']   object member
+//	[0], [-1]         array element (negative indexes count from the end)
+//	[*], .*           every member of an object or array
+//	..name            recursive descent to every member called name
+//	.length()         length of the preceding array, object or string (must be last)
+type Path struct {
+	expression string
+	steps      []step
+	length     bool
+}
+
+// stepKind identifies the kind of a single path step
+type stepKind int
+
+const (
+	stepMember stepKind = iota
+	stepIndex
+	stepWildcard
+	stepDescend
+)
+
+// step is one navigation step of a compiled path
+type step struct {
+	kind  stepKind
+	name  string
+	index int
+}
+
+// lengthSuffix is the trailing function returning the size of the selected values
+const lengthSuffix = ".length()"
+
+// Compile parses a JSONPath expression
+func Compile(expression string) (*Path, error) {
+	expr := strings.TrimSpace(expression)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("jsonpath %q must start with $", expression)
+	}
+
+	path := &Path{expression: expression}
+	if strings.HasSuffix(expr, lengthSuffix) {
+		path.length = true
+		expr = strings.TrimSuffix(expr, lengthSuffix)
+	}
+
+	steps, err := parseSteps(expr[1:])
+	if err != nil {
+		return nil, fmt.Errorf("jsonpath %q: %w", expression, err)
+	}
+	path.steps = steps
+
+	return path, nil
+}
+
+// String returns the original expression
+func (p *Path) String() string {
+	return p.expression
+}
+
+// Evaluate returns every value selected by the path in a decoded JSON document
+// (as produced by encoding/json into interface{}); the result is empty when nothing matches
+func (p *Path) Evaluate(document interface{}) []interface{} {
+	current := []interface{}{document}
+	for _, s := range p.steps {
+		var next []interface{}
+		for _, value := range current {
+			next = append(next, s.apply(value)...)
+		}
+		current = next
+		if len(current) == 0 {
+			return nil
+		}
+	}
+
+	if !p.length {
+		return current
+	}
+
+	lengths := make([]interface{}, 0, len(current))
+	for _, value := range current {
+		switch v := value.(type) {
+		case []interface{}:
+			lengths = append(lengths, float64(len(v)))
+		case map[string]interface{}:
+			lengths = append(lengths, float64(len(v)))
+		case string:
+			lengths = append(lengths, float64(len([]rune(v))))
+		}
+	}
+	return lengths
+}
+
+// apply selects the children of value addressed by the step
+func (s step) apply(value interface{}) []interface{} {
+	switch s.kind {
+	case stepMember:
+		if obj, ok := value.(map[string]interface{}); ok {
+			if child, exists := obj[s.name]; exists {
+				return []interface{}{child}
+			}
+		}
+	case stepIndex:
+		if arr, ok := value.([]interface{}); ok {
+			index := s.index
+			if index < 0 {
+				index += len(arr)
+			}
+			if index >= 0 && index < len(arr) {
+				return []interface{}{arr[index]}
+			}
+		}
+	case stepWildcard:
+		return children(value)
+	case stepDescend:
+		return descend(value, s.name)
+	}
+	return nil
+}
+
+// children returns the direct members of an object or array
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			result = append(result, child)
+		}
+		return result
+	case []interface{}:
+		return v
+	}
+	return nil
+}
+
+// descend returns every member called name at any depth below value
+func descend(value interface{}, name string) []interface{} {
+	var result []interface{}
+	if obj, ok := value.(map[string]interface{}); ok {
+		if child, exists := obj[name]; exists {
+			result = append(result, child)
+		}
+	}
+	for _, child := range children(value) {
+		result = append(result, descend(child, name)...)
+	}
+	return result
+}
+
+// parseSteps parses the part of an expression following the leading $
+func parseSteps(expr string) ([]step, error) {
+	var steps []step
+	for len(expr) > 0 {
+		switch {
+		case strings.HasPrefix(expr, ".."):
+			name, rest := readName(expr[2:])
+			if name == "" {
+				return nil, fmt.Errorf("missing member name after ..")
+			}
+			steps = append(steps, step{kind: stepDescend, name: name})
+			expr = rest
+		case expr[0] == '.':
+			name, rest := readName(expr[1:])
+			switch name {
+			case "":
+				return nil, fmt.Errorf("missing member name after .")
+			case "*":
+				steps = append(steps, step{kind: stepWildcard})
+			default:
+				steps = append(steps, step{kind: stepMember, name: name})
+			}
+			expr = rest
+		case expr[0] == '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [")
+			}
+			s, err := parseBracket(expr[1:end])
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+			expr = expr[end+1:]
+		default:
+			return nil, fmt.Errorf("unexpected character %q", expr[0])
+		}
+	}
+	return steps, nil
+}
+
+// readName reads a member name up to the next . or [
+func readName(expr string) (name, rest string) {
+	end := strings.IndexAny(expr, ".[")
+	if end < 0 {
+		return expr, ""
+	}
+	return expr[:end], expr[end:]
+}
+
+// parseBracket parses the content of a [...] step
+func parseBracket(content string) (step, error) {
+	content = strings.TrimSpace(content)
+	if content == "*" {
+		return step{kind: stepWildcard}, nil
+	}
+
+	if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+		return step{kind: stepMember, name: content[1 : len(content)-1]}, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return step{}, fmt.Errorf("invalid bracket expression [%s]", content)
+	}
+	return step{kind: stepIndex, index: index}, nil
+}
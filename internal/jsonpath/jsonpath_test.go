@@ -0,0 +1,105 @@
+package jsonpath
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"mock-service/internal/testutil"
+)
+
+// TestEvaluate tests the supported JSONPath expressions
+func TestEvaluate(t *testing.T) {
+	document := testutil.DecodeJSON(t, `{
+		"user": {"type": "admin", "name": "Alice", "tags": ["a", "b"]},
+		"items": [{"id": 1, "price": 10}, {"id": 2, "price": 20}, {"id": 3, "price": 30}],
+		"odd key": true
+	}`)
+
+	tests := []struct {
+		expression string
+		expected   []interface{}
+	}{
+		{"$.user.type", []interface{}{"admin"}},
+		{"$['user']['name']", []interface{}{"Alice"}},
+		{`$["odd key"]`, []interface{}{true}},
+		{"$.items[0].id", []interface{}{float64(1)}},
+		{"$.items[-1].id", []interface{}{float64(3)}},
+		{"$.items[*].price", []interface{}{float64(10), float64(20), float64(30)}},
+		{"$.items.length()", []interface{}{float64(3)}},
+		{"$.user.tags.length()", []interface{}{float64(2)}},
+		{"$.user.name.length()", []interface{}{float64(5)}},
+		{"$..id", []interface{}{float64(1), float64(2), float64(3)}},
+		{"$.user.missing", nil},
+		{"$.items[5]", nil},
+		{"$.user.type.nested", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			path, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+
+			got := path.Evaluate(document)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestEvaluateWildcardObject tests wildcards over object members
+func TestEvaluateWildcardObject(t *testing.T) {
+	document := testutil.DecodeJSON(t, `{"prices": {"a": 1, "b": 2}}`)
+
+	path, err := Compile("$.prices.*")
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	got := path.Evaluate(document)
+	values := make([]float64, 0, len(got))
+	for _, value := range got {
+		values = append(values, value.(float64))
+	}
+	sort.Float64s(values)
+
+	if !reflect.DeepEqual(values, []float64{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", values)
+	}
+}
+
+// TestEvaluateRoot tests that $ selects the whole document
+func TestEvaluateRoot(t *testing.T) {
+	document := testutil.DecodeJSON(t, `[1, 2]`)
+
+	path, err := Compile("$")
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	got := path.Evaluate(document)
+	if len(got) != 1 || !reflect.DeepEqual(got[0], document) {
+		t.Errorf("Expected root document, got %v", got)
+	}
+}
+
+// TestCompileErrors tests that malformed expressions are rejected
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		"user.type",
+		"$.",
+		"$..",
+		"$.items[0",
+		"$.items[abc]",
+		"$user",
+	}
+
+	for _, expression := range tests {
+		if _, err := Compile(expression); err == nil {
+			t.Errorf("Expected Compile(%q) to fail", expression)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"time"
+	"unicode/utf8"
 
 	"mock-service/internal/models"
 )
@@ -19,7 +20,8 @@ func NewLogger() *LoggerImpl {
 }
 
 // LogRequest logs incoming HTTP request details in JSON format
-func (l *LoggerImpl) LogRequest(method, path string, params map[string]string) {
+// A JSON body is embedded as-is, a text body is logged as a string and a binary body by its size
+func (l *LoggerImpl) LogRequest(method, path string, params map[string]string, body []byte) {
 	logEntry := map[string]interface{}{
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"level":     "INFO",
@@ -28,34 +30,63 @@ func (l *LoggerImpl) LogRequest(method, path string, params map[string]string) {
 		"path":      path,
 		"params":    params,
 	}
+	if len(body) > 0 {
+		logEntry["body"] = encodedBody(body)
+	}
 
 	l.writeLog(logEntry)
 }
 
 // LogResponse logs outgoing HTTP response details in JSON format
+// An encoded []byte body is logged like a request body; empty bodies are omitted
 func (l *LoggerImpl) LogResponse(statusCode int, body interface{}) {
 	logEntry := map[string]interface{}{
 		"timestamp":   time.Now().UTC().Format(time.RFC3339),
 		"level":       "INFO",
 		"type":        "response",
 		"status_code": statusCode,
-		"body":        body,
+	}
+	if data, ok := body.([]byte); ok {
+		if len(data) > 0 {
+			logEntry["body"] = encodedBody(data)
+		}
+	} else {
+		logEntry["body"] = body
 	}
 
 	l.writeLog(logEntry)
 }
 
 // LogMatch logs when a rule is matched in JSON format
-func (l *LoggerImpl) LogMatch(rule *models.MockRule) {
+// Path parameters captured by a path template are included when present
+func (l *LoggerImpl) LogMatch(match *models.MatchResult) {
+	rule := match.Rule
+	ruleInfo := map[string]interface{}{
+		"path": rule.Path,
+		"code": rule.Code,
+	}
+	if rule.ID != "" {
+		ruleInfo["id"] = rule.ID
+	}
+	if len(rule.Method) > 0 {
+		ruleInfo["method"] = rule.Method
+	}
+
 	logEntry := map[string]interface{}{
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
 		"level":     "INFO",
 		"type":      "match",
 		"message":   "Rule matched",
-		"rule": map[string]interface{}{
-			"path": rule.Path,
-			"code": rule.Code,
-		},
+		"rule":      ruleInfo,
+	}
+	if len(match.PathParams) > 0 {
+		logEntry["path_params"] = match.PathParams
+	}
+	if len(rule.SequenceRules) > 0 {
+		logEntry["sequence_index"] = match.SequenceIndex
+	}
+	if rule.Scenario != "" {
+		logEntry["scenario"] = map[string]interface{}{"name": rule.Scenario, "state": match.ScenarioState}
 	}
 
 	l.writeLog(logEntry)
@@ -73,6 +104,101 @@ func (l *LoggerImpl) LogDefault() {
 	l.writeLog(logEntry)
 }
 
+// LogResource logs when a request is handled by an in-memory resource in JSON format
+func (l *LoggerImpl) LogResource(name string) {
+	logEntry := map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"level":     "INFO",
+		"type":      "resource",
+		"message":   "No matching rule found, handled by resource",
+		"resource":  name,
+	}
+
+	l.writeLog(logEntry)
+}
+
+// LogDelay logs the delay applied before a response in JSON format
+func (l *LoggerImpl) LogDelay(delay time.Duration, cancelled bool) {
+	logEntry := map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"level":     "INFO",
+		"type":      "delay",
+		"message":   "Response delayed",
+		"delay_ms":  float64(delay) / float64(time.Millisecond),
+		"cancelled": cancelled,
+	}
+	if cancelled {
+		logEntry["message"] = "Request cancelled while the response was delayed"
+	}
+
+	l.writeLog(logEntry)
+}
+
+// LogFault logs a fault injected instead of the response in JSON format
+// It is written before the fault since the client may see the connection drop at any time afterwards
+func (l *LoggerImpl) LogFault(fault string) {
+	logEntry := map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"level":     "WARN",
+		"type":      "fault",
+		"message":   "Fault injected instead of the response",
+		"fault":     fault,
+	}
+
+	l.writeLog(logEntry)
+}
+
+// LogChaos logs a chaos decision in JSON format
+// Spared requests are logged too, so that disrupted ones can be correlated with the roll sequence
+func (l *LoggerImpl) LogChaos(decision *models.ChaosDecision) {
+	logEntry := map[string]interface{}{
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+		"level":      "INFO",
+		"type":       "chaos",
+		"message":    "Request spared by chaos",
+		"path":       decision.Path,
+		"percentage": decision.Percentage,
+		"roll":       decision.Roll,
+		"injected":   decision.Action != "",
+	}
+	if decision.Rule != nil {
+		ruleInfo := map[string]interface{}{"path": decision.Rule.Path, "code": decision.Rule.Code}
+		if decision.Rule.ID != "" {
+			ruleInfo["id"] = decision.Rule.ID
+		}
+		logEntry["rule"] = ruleInfo
+	}
+
+	switch decision.Action {
+	case models.ChaosActionError:
+		logEntry["status_code"] = decision.StatusCode
+	case models.ChaosActionDelay:
+		logEntry["delay_ms"] = float64(decision.Delay) / float64(time.Millisecond)
+	case models.ChaosActionFault:
+		logEntry["fault"] = decision.Fault
+	}
+	if decision.Action != "" {
+		logEntry["level"] = "WARN"
+		logEntry["message"] = "Chaos injected"
+		logEntry["action"] = decision.Action
+	}
+
+	l.writeLog(logEntry)
+}
+
+// encodedBody returns the log representation of a raw body
+// JSON is embedded as-is, text is logged as a string and binary data is summarized by its size
+func encodedBody(body []byte) interface{} {
+	switch {
+	case json.Valid(body):
+		return json.RawMessage(body)
+	case utf8.Valid(body):
+		return string(body)
+	default:
+		return fmt.Sprintf("<%d bytes of binary data>", len(body))
+	}
+}
+
 // writeLog writes the log entry to stdout in JSON format
 func (l *LoggerImpl) writeLog(logEntry map[string]interface{}) {
 	jsonData, err := json.Marshal(logEntry)
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"mock-service/internal/models"
 )
@@ -45,7 +47,7 @@ func TestLogRequest(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		logger.LogRequest("GET", "/api/users", params)
+		logger.LogRequest("GET", "/api/users", params, nil)
 	})
 
 	// Verify output is valid JSON
@@ -141,7 +143,7 @@ func TestLogMatch(t *testing.T) {
 	}
 
 	output := captureOutput(func() {
-		logger.LogMatch(rule)
+		logger.LogMatch(&models.MatchResult{Rule: rule})
 	})
 
 	// Verify output is valid JSON
@@ -220,7 +222,7 @@ func TestLogRequestWithEmptyParams(t *testing.T) {
 	logger := NewLogger()
 
 	output := captureOutput(func() {
-		logger.LogRequest("POST", "/api/create", map[string]string{})
+		logger.LogRequest("POST", "/api/create", map[string]string{}, nil)
 	})
 
 	// Verify output is valid JSON
@@ -282,3 +284,290 @@ func TestLoggerWithUnmarshalableData(t *testing.T) {
 		t.Error("Expected error message about marshaling failure")
 	}
 }
+
+// TestLogMatchWithPathParams tests that captured path parameters are logged
+func TestLogMatchWithPathParams(t *testing.T) {
+	logger := NewLogger()
+
+	match := &models.MatchResult{
+		Rule: &models.MockRule{
+			Path: "/api/users/{id}",
+			Code: 200,
+		},
+		PathParams: map[string]string{"id": "42"},
+	}
+
+	output := captureOutput(func() {
+		logger.LogMatch(match)
+	})
+
+	var logEntry map[string]interface{}
+	err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry)
+	if err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+
+	params, ok := logEntry["path_params"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Expected path_params to be a map")
+	}
+
+	if params["id"] != "42" {
+		t.Errorf("Expected path param id '42', got '%v'", params["id"])
+	}
+}
+
+// TestLogMatchWithSequence tests that the rule id and the sequence step are logged
+func TestLogMatchWithSequence(t *testing.T) {
+	logger := NewLogger()
+
+	match := &models.MatchResult{
+		Rule:          &models.MockRule{ID: "job", Path: "/api/job", SequenceRules: []*models.MockRule{{}, {}}},
+		SequenceIndex: 1,
+	}
+
+	output := captureOutput(func() {
+		logger.LogMatch(match)
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+
+	ruleInfo, ok := logEntry["rule"].(map[string]interface{})
+	if !ok || ruleInfo["id"] != "job" {
+		t.Errorf("Expected rule id 'job', got '%v'", logEntry["rule"])
+	}
+	if logEntry["sequence_index"] != float64(1) {
+		t.Errorf("Expected sequence_index 1, got '%v'", logEntry["sequence_index"])
+	}
+}
+
+// TestLogMatchWithScenario tests that the scenario state after the request is logged
+func TestLogMatchWithScenario(t *testing.T) {
+	logger := NewLogger()
+
+	match := &models.MatchResult{
+		Rule:          &models.MockRule{Path: "/cart", Scenario: "cart", NewState: "item-added"},
+		ScenarioState: "item-added",
+	}
+
+	output := captureOutput(func() {
+		logger.LogMatch(match)
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+
+	scenario, ok := logEntry["scenario"].(map[string]interface{})
+	if !ok || scenario["name"] != "cart" || scenario["state"] != "item-added" {
+		t.Errorf("Expected the scenario and its new state, got '%v'", logEntry["scenario"])
+	}
+}
+
+// TestLogRequestWithBody tests that JSON bodies are embedded and other bodies logged as text
+func TestLogRequestWithBody(t *testing.T) {
+	logger := NewLogger()
+
+	tests := []struct {
+		name     string
+		body     string
+		expected interface{}
+	}{
+		{"json body", `{"name":"alice"}`, map[string]interface{}{"name": "alice"}},
+		{"text body", "name=alice", "name=alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				logger.LogRequest("POST", "/api/users", map[string]string{}, []byte(tt.body))
+			})
+
+			var logEntry map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+				t.Fatalf("Log output should be valid JSON: %v", err)
+			}
+
+			if !reflect.DeepEqual(logEntry["body"], tt.expected) {
+				t.Errorf("Expected body %v, got %v", tt.expected, logEntry["body"])
+			}
+		})
+	}
+}
+
+// TestLogRequestWithoutBody tests that an empty body is omitted from the log
+func TestLogRequestWithoutBody(t *testing.T) {
+	logger := NewLogger()
+
+	output := captureOutput(func() {
+		logger.LogRequest("GET", "/api/users", map[string]string{}, nil)
+	})
+
+	if strings.Contains(output, `"body"`) {
+		t.Errorf("Expected no body field, got %s", output)
+	}
+}
+
+// TestLogResponseWithEncodedBody tests that encoded bodies are logged as JSON, text or a size summary
+func TestLogResponseWithEncodedBody(t *testing.T) {
+	logger := NewLogger()
+
+	tests := []struct {
+		name     string
+		body     []byte
+		expected interface{}
+	}{
+		{"json body", []byte(`[1,2]`), []interface{}{float64(1), float64(2)}},
+		{"text body", []byte("<ok/>"), "<ok/>"},
+		{"binary body", []byte{0x89, 0xff, 0x00}, "<3 bytes of binary data>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := captureOutput(func() {
+				logger.LogResponse(200, tt.body)
+			})
+
+			var logEntry map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+				t.Fatalf("Log output should be valid JSON: %v", err)
+			}
+
+			if !reflect.DeepEqual(logEntry["body"], tt.expected) {
+				t.Errorf("Expected body %v, got %v", tt.expected, logEntry["body"])
+			}
+		})
+	}
+}
+
+// TestLogDelay tests logging of applied and cancelled delays
+func TestLogDelay(t *testing.T) {
+	logger := NewLogger()
+
+	output := captureOutput(func() {
+		logger.LogDelay(1500*time.Millisecond, true)
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+
+	if logEntry["type"] != "delay" {
+		t.Errorf("Expected type 'delay', got '%v'", logEntry["type"])
+	}
+	if logEntry["delay_ms"] != float64(1500) {
+		t.Errorf("Expected delay_ms 1500, got '%v'", logEntry["delay_ms"])
+	}
+	if logEntry["cancelled"] != true {
+		t.Errorf("Expected cancelled true, got '%v'", logEntry["cancelled"])
+	}
+}
+
+// TestLogFault tests logging of injected faults
+func TestLogFault(t *testing.T) {
+	logger := NewLogger()
+
+	output := captureOutput(func() {
+		logger.LogFault("connection-reset")
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+
+	if logEntry["type"] != "fault" {
+		t.Errorf("Expected type 'fault', got '%v'", logEntry["type"])
+	}
+	if logEntry["level"] != "WARN" {
+		t.Errorf("Expected level 'WARN', got '%v'", logEntry["level"])
+	}
+	if logEntry["fault"] != "connection-reset" {
+		t.Errorf("Expected fault 'connection-reset', got '%v'", logEntry["fault"])
+	}
+}
+
+// TestLogChaos tests logging of spared and disrupted requests
+func TestLogChaos(t *testing.T) {
+	logger := NewLogger()
+
+	tests := []struct {
+		name     string
+		decision models.ChaosDecision
+		wantKey  string
+	}{
+		{"spared", models.ChaosDecision{Path: "/a", Percentage: 10, Roll: 42}, ""},
+		{"error", models.ChaosDecision{Path: "/a", Percentage: 10, Roll: 3, Action: "error", StatusCode: 503}, "status_code"},
+		{"fault", models.ChaosDecision{Path: "/a", Percentage: 10, Roll: 3, Action: "fault", Fault: "garbage"}, "fault"},
+	}
+
+	for _, tt := range tests {
+		output := captureOutput(func() {
+			logger.LogChaos(&tt.decision)
+		})
+
+		var logEntry map[string]interface{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+			t.Fatalf("%s: log output should be valid JSON: %v", tt.name, err)
+		}
+
+		if logEntry["type"] != "chaos" {
+			t.Errorf("%s: expected type 'chaos', got '%v'", tt.name, logEntry["type"])
+		}
+		if logEntry["injected"] != (tt.decision.Action != "") {
+			t.Errorf("%s: expected injected %v, got '%v'", tt.name, tt.decision.Action != "", logEntry["injected"])
+		}
+		if tt.wantKey != "" && logEntry[tt.wantKey] == nil {
+			t.Errorf("%s: expected '%s' to be logged, got %v", tt.name, tt.wantKey, logEntry)
+		}
+		if tt.wantKey == "" && logEntry["action"] != nil {
+			t.Errorf("%s: expected no action for a spared request, got '%v'", tt.name, logEntry["action"])
+		}
+	}
+}
+
+// TestLogChaosRule tests that the disrupted rule is identified by its id
+func TestLogChaosRule(t *testing.T) {
+	logger := NewLogger()
+	output := captureOutput(func() {
+		logger.LogChaos(&models.ChaosDecision{
+			Path: "/api/orders", Percentage: 10, Roll: 3, Action: "error", StatusCode: 503,
+			Rule: &models.MockRule{ID: "orders", Path: "/api/orders", Code: 200},
+		})
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+	rule, ok := logEntry["rule"].(map[string]interface{})
+	if !ok || rule["id"] != "orders" || rule["path"] != "/api/orders" {
+		t.Errorf("Expected the rule id and path to be logged, got %v", logEntry["rule"])
+	}
+}
+
+// TestLogResource tests logging of requests handled by a resource
+func TestLogResource(t *testing.T) {
+	logger := NewLogger()
+
+	output := captureOutput(func() {
+		logger.LogResource("users")
+	})
+
+	var logEntry map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &logEntry); err != nil {
+		t.Fatalf("Log output should be valid JSON: %v", err)
+	}
+
+	if logEntry["type"] != "resource" {
+		t.Errorf("Expected type 'resource', got '%v'", logEntry["type"])
+	}
+	if logEntry["resource"] != "users" {
+		t.Errorf("Expected resource 'users', got '%v'", logEntry["resource"])
+	}
+}
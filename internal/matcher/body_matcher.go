@@ -0,0 +1,287 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"mock-service/internal/jsonpath"
+	"mock-service/internal/models"
+	"mock-service/internal/xpath"
+)
+
+// CompileBodyMatcher validates a body matcher and precompiles its expressions
+func CompileBodyMatcher(bm *models.BodyMatcher) error {
+	if bm == nil {
+		return nil
+	}
+
+	bm.CompiledJSONPath = make(map[string]*jsonpath.Path, len(bm.JSONPath))
+	for expression := range bm.JSONPath {
+		path, err := jsonpath.Compile(expression)
+		if err != nil {
+			return fmt.Errorf("body matcher: %w", err)
+		}
+		bm.CompiledJSONPath[expression] = path
+	}
+
+	if err := compileValueMatchers("body jsonPath", bm.JSONPath); err != nil {
+		return err
+	}
+
+	bm.CompiledXPath = make(map[string]*xpath.Path, len(bm.XPath))
+	for expression := range bm.XPath {
+		path, err := xpath.Compile(expression)
+		if err != nil {
+			return fmt.Errorf("body matcher: %w", err)
+		}
+		bm.CompiledXPath[expression] = path
+	}
+
+	if err := compileValueMatchers("body xpath", bm.XPath); err != nil {
+		return err
+	}
+
+	if err := compileValueMatchers("body form", bm.Form); err != nil {
+		return err
+	}
+
+	return compileMultipartMatchers(bm.Multipart)
+}
+
+// compileMultipartMatchers validates multipart matchers and precompiles their regexes
+func compileMultipartMatchers(matchers []models.MultipartMatcher) error {
+	for i := range matchers {
+		mm := &matchers[i]
+		if mm.Name == "" {
+			return fmt.Errorf("body multipart matcher #%d: name is required", i+1)
+		}
+		for _, vm := range []*models.ValueMatcher{mm.Filename, mm.ContentType} {
+			if vm == nil {
+				continue
+			}
+			if err := compileValueMatcher(vm); err != nil {
+				return fmt.Errorf("body multipart matcher %q: %w", mm.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// MatchBody reports whether the request body satisfies every predicate of the body matcher
+func MatchBody(bm *models.BodyMatcher, req *models.Request) bool {
+	if bm == nil {
+		return true
+	}
+
+	return matchJSONBody(bm, req) && matchXMLBody(bm, req) && matchFormBody(bm, req)
+}
+
+// matchJSONBody applies the JSONPath and equalToJson predicates
+func matchJSONBody(bm *models.BodyMatcher, req *models.Request) bool {
+	if len(bm.JSONPath) == 0 && bm.EqualToJSON == nil {
+		return true
+	}
+
+	document, ok := req.JSONBody()
+	if !ok {
+		return false
+	}
+
+	if bm.EqualToJSON != nil && !equalJSON(bm.EqualToJSON, document, bm) {
+		return false
+	}
+
+	for expression := range bm.JSONPath {
+		path := bm.CompiledJSONPath[expression]
+		if path == nil {
+			return false
+		}
+
+		vm := bm.JSONPath[expression]
+		if !matchValues(&vm, jsonValueStrings(path.Evaluate(document))) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchXMLBody applies the XPath predicates
+func matchXMLBody(bm *models.BodyMatcher, req *models.Request) bool {
+	if len(bm.XPath) == 0 {
+		return true
+	}
+
+	document, ok := req.XMLDocument()
+	if !ok {
+		return false
+	}
+
+	for expression := range bm.XPath {
+		path := bm.CompiledXPath[expression]
+		if path == nil {
+			return false
+		}
+
+		vm := bm.XPath[expression]
+		if !matchValues(&vm, path.Evaluate(document)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchFormBody applies the form field and multipart part predicates
+func matchFormBody(bm *models.BodyMatcher, req *models.Request) bool {
+	if len(bm.Form) > 0 {
+		fields := req.FormValues()
+		for name := range bm.Form {
+			vm := bm.Form[name]
+			if !matchValues(&vm, fields[name]) {
+				return false
+			}
+		}
+	}
+
+	for i := range bm.Multipart {
+		if !matchMultipart(&bm.Multipart[i], req.MultipartParts()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchMultipart reports whether any part satisfies the multipart matcher
+func matchMultipart(mm *models.MultipartMatcher, parts []models.MultipartPart) bool {
+	for _, part := range parts {
+		if part.Name != mm.Name {
+			continue
+		}
+		if mm.Filename != nil && !matchValues(mm.Filename, presentValue(part.Filename)) {
+			continue
+		}
+		if mm.ContentType != nil && !matchValues(mm.ContentType, presentValue(part.ContentType)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// presentValue treats an empty attribute as absent so that present/absent predicates apply
+func presentValue(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+// jsonValueStrings converts values selected by a JSONPath into strings for value matching
+// Strings are used as-is, numbers and booleans use their JSON spelling and
+// objects or arrays are compared as compact JSON
+func jsonValueStrings(values []interface{}) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			result = append(result, v)
+		case float64:
+			result = append(result, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			result = append(result, strconv.FormatBool(v))
+		case nil:
+			result = append(result, "null")
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			result = append(result, string(data))
+		}
+	}
+	return result
+}
+
+// equalJSON compares an expected JSON value with the actual one using the matcher's options
+func equalJSON(expected, actual interface{}, bm *models.BodyMatcher) bool {
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if !bm.IgnoreExtraFields && len(exp) != len(act) {
+			return false
+		}
+		for key, expValue := range exp {
+			actValue, exists := act[key]
+			if !exists || !equalJSON(expValue, actValue, bm) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok || len(exp) != len(act) {
+			return false
+		}
+		if bm.IgnoreArrayOrder {
+			return equalUnordered(exp, act, bm)
+		}
+		for i := range exp {
+			if !equalJSON(exp[i], act[i], bm) {
+				return false
+			}
+		}
+		return true
+	default:
+		return expected == actual
+	}
+}
+
+// equalUnordered pairs every expected element with a distinct actual element of an array of the same length
+// Loose element matches (ignoreExtraFields) mean an element can pair with several others, so this is
+// a bipartite matching: elements are compared once each, then augmenting paths find a pairing in polynomial time
+func equalUnordered(expected, actual []interface{}, bm *models.BodyMatcher) bool {
+	candidates := make([][]int, len(expected))
+	for i := range expected {
+		for j := range actual {
+			if equalJSON(expected[i], actual[j], bm) {
+				candidates[i] = append(candidates[i], j)
+			}
+		}
+		if len(candidates[i]) == 0 {
+			return false
+		}
+	}
+
+	// pairedWith holds the expected element paired with each actual element, or -1
+	pairedWith := make([]int, len(actual))
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+	for i := range expected {
+		if !augment(i, candidates, pairedWith, make([]bool, len(actual))) {
+			return false
+		}
+	}
+	return true
+}
+
+// augment pairs expected element i with an actual element, moving earlier pairings to other candidates if needed
+func augment(i int, candidates [][]int, pairedWith []int, visited []bool) bool {
+	for _, j := range candidates[i] {
+		if visited[j] {
+			continue
+		}
+		visited[j] = true
+		if pairedWith[j] < 0 || augment(pairedWith[j], candidates, pairedWith, visited) {
+			pairedWith[j] = i
+			return true
+		}
+	}
+	return false
+}
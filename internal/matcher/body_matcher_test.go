@@ -0,0 +1,329 @@
+package matcher
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	"mock-service/internal/models"
+	"mock-service/internal/testutil"
+)
+
+// TestMatchBodyJSONPath tests JSONPath predicates against request bodies
+func TestMatchBodyJSONPath(t *testing.T) {
+	body := `{"user": {"type": "admin", "age": 41}, "items": [{"sku": "A1"}, {"sku": "B2"}, {"sku": "C3"}, {"sku": "D4"}]}`
+
+	tests := []struct {
+		name    string
+		matcher map[string]models.ValueMatcher
+		matches bool
+	}{
+		{"equals", map[string]models.ValueMatcher{"$.user.type": {Equals: testutil.Ptr("admin")}}, true},
+		{"equals mismatch", map[string]models.ValueMatcher{"$.user.type": {Equals: testutil.Ptr("guest")}}, false},
+		{"regex", map[string]models.ValueMatcher{"$.items[*].sku": {Regex: "^C"}}, true},
+		{"exists", map[string]models.ValueMatcher{"$.user.age": {Present: true}}, true},
+		{"absent", map[string]models.ValueMatcher{"$.user.email": {Absent: true}}, true},
+		{"absent but present", map[string]models.ValueMatcher{"$.user.age": {Absent: true}}, false},
+		{"length gt", map[string]models.ValueMatcher{"$.items.length()": {GreaterThan: testutil.Ptr[float64](3)}}, true},
+		{"length lt", map[string]models.ValueMatcher{"$.items.length()": {LessThan: testutil.Ptr[float64](3)}}, false},
+		{
+			"number range",
+			map[string]models.ValueMatcher{
+				"$.user.age": {GreaterThanOrEqual: testutil.Ptr[float64](18), LessThanOrEqual: testutil.Ptr[float64](65)},
+			},
+			true,
+		},
+		{"number equals", map[string]models.ValueMatcher{"$.user.age": {Equals: testutil.Ptr("41")}}, true},
+		{
+			"all predicates",
+			map[string]models.ValueMatcher{
+				"$.user.type":      {Equals: testutil.Ptr("admin")},
+				"$.items.length()": {GreaterThan: testutil.Ptr[float64](10)},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := &models.BodyMatcher{JSONPath: tt.matcher}
+			if err := CompileBodyMatcher(bm); err != nil {
+				t.Fatalf("CompileBodyMatcher failed: %v", err)
+			}
+
+			req := &models.Request{Body: []byte(body)}
+			if got := MatchBody(bm, req); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+// TestMatchBodyNotJSON tests that JSON predicates never match non-JSON bodies
+func TestMatchBodyNotJSON(t *testing.T) {
+	bm := &models.BodyMatcher{JSONPath: map[string]models.ValueMatcher{"$.user": {Absent: true}}}
+	if err := CompileBodyMatcher(bm); err != nil {
+		t.Fatalf("CompileBodyMatcher failed: %v", err)
+	}
+
+	for _, body := range []string{"", "name=alice", "{broken"} {
+		if MatchBody(bm, &models.Request{Body: []byte(body)}) {
+			t.Errorf("Expected body %q not to match", body)
+		}
+	}
+}
+
+// TestMatchBodyEqualToJSON tests the equalToJson mode and its options
+func TestMatchBodyEqualToJSON(t *testing.T) {
+	expected := `{"name": "alice", "roles": ["admin", "user"], "address": {"city": "Paris"}}`
+	withZip := `{"name": "alice", "roles": ["admin", "user"], "address": {"city": "Paris", "zip": "75"}}`
+
+	tests := []struct {
+		name              string
+		body              string
+		ignoreExtraFields bool
+		ignoreArrayOrder  bool
+		matches           bool
+	}{
+		{"identical", `{"roles": ["admin", "user"], "address": {"city": "Paris"}, "name": "alice"}`, false, false, true},
+		{"different value", `{"name": "bob", "roles": ["admin", "user"], "address": {"city": "Paris"}}`, false, false, false},
+		{"extra field", withZip, false, false, false},
+		{"extra field ignored", withZip, true, false, true},
+		{"array order", `{"name": "alice", "roles": ["user", "admin"], "address": {"city": "Paris"}}`, false, false, false},
+		{"array order ignored", `{"name": "alice", "roles": ["user", "admin"], "address": {"city": "Paris"}}`, false, true, true},
+		{"missing field", `{"name": "alice", "roles": ["admin", "user"]}`, true, true, false},
+		{"extra array element", `{"name": "alice", "roles": ["admin", "user", "x"], "address": {"city": "Paris"}}`,
+			true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := &models.BodyMatcher{
+				EqualToJSON:       testutil.DecodeJSON(t, expected),
+				IgnoreExtraFields: tt.ignoreExtraFields,
+				IgnoreArrayOrder:  tt.ignoreArrayOrder,
+			}
+
+			if got := MatchBody(bm, &models.Request{Body: []byte(tt.body)}); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+// TestMatchBodyUnorderedBacktracking tests that unordered matching finds a valid pairing
+func TestMatchBodyUnorderedBacktracking(t *testing.T) {
+	bm := &models.BodyMatcher{
+		EqualToJSON:       testutil.DecodeJSON(t, `[{"a": 1}, {"a": 1, "b": 2}]`),
+		IgnoreExtraFields: true,
+		IgnoreArrayOrder:  true,
+	}
+
+	if !MatchBody(bm, &models.Request{Body: []byte(`[{"a": 1, "b": 2}, {"a": 1}]`)}) {
+		t.Error("Expected unordered arrays with loose elements to match")
+	}
+}
+
+// TestMatchBodyUnorderedDuplicates tests that arrays with many equal elements are compared quickly
+func TestMatchBodyUnorderedDuplicates(t *testing.T) {
+	bm := &models.BodyMatcher{
+		EqualToJSON:      testutil.DecodeJSON(t, `{"ids": [1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2]}`),
+		IgnoreArrayOrder: true,
+	}
+
+	ones := `{"ids": [1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1]}`
+	done := make(chan bool, 1)
+	go func() {
+		done <- MatchBody(bm, &models.Request{Body: []byte(ones)})
+	}()
+	select {
+	case matched := <-done:
+		if matched {
+			t.Error("Expected arrays with different elements not to match")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected unordered matching not to take factorial time")
+	}
+
+	if !MatchBody(bm, &models.Request{Body: []byte(`{"ids": [2, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1]}`)}) {
+		t.Error("Expected the same elements in another order to match")
+	}
+}
+
+// TestCompileBodyMatcherErrors tests that invalid body matchers are rejected
+func TestCompileBodyMatcherErrors(t *testing.T) {
+	tests := []*models.BodyMatcher{
+		{JSONPath: map[string]models.ValueMatcher{"user.type": {Equals: testutil.Ptr("admin")}}},
+		{JSONPath: map[string]models.ValueMatcher{"$.user": {Regex: "("}}},
+		{XPath: map[string]models.ValueMatcher{"Envelope/Body": {Present: true}}},
+		{Form: map[string]models.ValueMatcher{"name": {Regex: "["}}},
+		{Multipart: []models.MultipartMatcher{{Filename: &models.ValueMatcher{Equals: testutil.Ptr("a.pdf")}}}},
+	}
+
+	for _, bm := range tests {
+		if err := CompileBodyMatcher(bm); err == nil {
+			t.Errorf("Expected CompileBodyMatcher to fail for %+v", bm)
+		}
+	}
+}
+
+// multipartBody builds a multipart/form-data body with a text field and a file part
+func multipartBody(t *testing.T) (contentType string, body []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("title", "Quarterly report"); err != nil {
+		t.Fatalf("Failed to write field: %v", err)
+	}
+	part, err := writer.CreateFormFile("attachment", "report.pdf")
+	if err != nil {
+		t.Fatalf("Failed to create file part: %v", err)
+	}
+	if _, err := part.Write([]byte("%PDF-1.4")); err != nil {
+		t.Fatalf("Failed to write file part: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	return writer.FormDataContentType(), buf.Bytes()
+}
+
+// TestMatchBodyForm tests form field matchers on url-encoded bodies
+func TestMatchBodyForm(t *testing.T) {
+	bm := &models.BodyMatcher{Form: map[string]models.ValueMatcher{
+		"grant_type": {Equals: testutil.Ptr("password")},
+		"username":   {Regex: "^[a-z]+$"},
+		"otp":        {Absent: true},
+	}}
+	if err := CompileBodyMatcher(bm); err != nil {
+		t.Fatalf("CompileBodyMatcher failed: %v", err)
+	}
+
+	formHeader := http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		body    string
+		matches bool
+	}{
+		{"matching form", formHeader, "grant_type=password&username=alice", true},
+		{"wrong value", formHeader, "grant_type=client_credentials&username=alice", false},
+		{"absent field present", formHeader, "grant_type=password&username=alice&otp=123", false},
+		{"not a form", http.Header{"Content-Type": {"text/plain"}}, "grant_type=password&username=alice", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.Request{Header: tt.header, Body: []byte(tt.body)}
+			if got := MatchBody(bm, req); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+// TestMatchBodyMultipart tests multipart part and field matchers
+func TestMatchBodyMultipart(t *testing.T) {
+	contentType, body := multipartBody(t)
+	header := http.Header{"Content-Type": {contentType}}
+	pdf := &models.ValueMatcher{Regex: `\.pdf$`}
+	csv := &models.ValueMatcher{Regex: `\.csv$`}
+
+	tests := []struct {
+		name    string
+		matcher models.BodyMatcher
+		matches bool
+	}{
+		{
+			"part by name",
+			models.BodyMatcher{Multipart: []models.MultipartMatcher{{Name: "attachment"}}},
+			true,
+		},
+		{
+			"part by filename",
+			models.BodyMatcher{Multipart: []models.MultipartMatcher{{Name: "attachment", Filename: pdf}}},
+			true,
+		},
+		{
+			"wrong filename",
+			models.BodyMatcher{Multipart: []models.MultipartMatcher{{Name: "attachment", Filename: csv}}},
+			false,
+		},
+		{
+			"text field has no filename",
+			models.BodyMatcher{
+				Multipart: []models.MultipartMatcher{{Name: "title", Filename: &models.ValueMatcher{Absent: true}}},
+			},
+			true,
+		},
+		{
+			"missing part",
+			models.BodyMatcher{Multipart: []models.MultipartMatcher{{Name: "signature"}}},
+			false,
+		},
+		{
+			"form field from multipart",
+			models.BodyMatcher{Form: map[string]models.ValueMatcher{"title": {Contains: "report"}}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := tt.matcher
+			if err := CompileBodyMatcher(&bm); err != nil {
+				t.Fatalf("CompileBodyMatcher failed: %v", err)
+			}
+
+			req := &models.Request{Header: header, Body: body}
+			if got := MatchBody(&bm, req); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+// TestMatchBodyXPath tests XPath predicates against SOAP bodies
+func TestMatchBodyXPath(t *testing.T) {
+	body := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+		<soap:Body><GetUser><UserId>42</UserId><Item/><Item/></GetUser></soap:Body>
+	</soap:Envelope>`
+
+	tests := []struct {
+		name    string
+		matcher map[string]models.ValueMatcher
+		matches bool
+	}{
+		{"equals", map[string]models.ValueMatcher{"//GetUser/UserId": {Equals: testutil.Ptr("42")}}, true},
+		{"namespaced path", map[string]models.ValueMatcher{"/soap:Envelope/soap:Body/GetUser": {Present: true}}, true},
+		{"count", map[string]models.ValueMatcher{"count(//Item)": {GreaterThanOrEqual: testutil.Ptr[float64](2)}}, true},
+		{"missing", map[string]models.ValueMatcher{"//DeleteUser": {Present: true}}, false},
+		{"absent", map[string]models.ValueMatcher{"//DeleteUser": {Absent: true}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bm := &models.BodyMatcher{XPath: tt.matcher}
+			if err := CompileBodyMatcher(bm); err != nil {
+				t.Fatalf("CompileBodyMatcher failed: %v", err)
+			}
+
+			if got := MatchBody(bm, &models.Request{Body: []byte(body)}); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+
+	bm := &models.BodyMatcher{XPath: map[string]models.ValueMatcher{"//DeleteUser": {Absent: true}}}
+	if err := CompileBodyMatcher(bm); err != nil {
+		t.Fatalf("CompileBodyMatcher failed: %v", err)
+	}
+	if MatchBody(bm, &models.Request{Body: []byte(`{"json": true}`)}) {
+		t.Error("Expected XPath predicates never to match non-XML bodies")
+	}
+}
@@ -0,0 +1,120 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mock-service/internal/models"
+)
+
+// CompileRule validates a rule and precompiles its path pattern, path template and matcher regexes
+// It must be called for every rule before it is passed to FindMatch;
+// ConfigManager does this while loading the configuration so that broken
+// patterns are reported at startup instead of at request time
+func CompileRule(rule *models.MockRule) error {
+	rule.PathTemplate = nil
+	rule.PathLiteralLength = literalLength(rule)
+
+	switch rule.PathMatch {
+	case "", models.PathMatchExact:
+		rule.PathPattern = nil
+		rule.PathTemplate = compileTemplate(rule.Path)
+	case models.PathMatchPrefix:
+		rule.PathPattern = nil
+	case models.PathMatchGlob:
+		pattern, err := CompileGlob(rule.Path)
+		if err != nil {
+			return err
+		}
+		rule.PathPattern = pattern
+	case models.PathMatchRegex:
+		pattern, err := regexp.Compile(rule.Path)
+		if err != nil {
+			return fmt.Errorf("invalid regex path %q: %w", rule.Path, err)
+		}
+		rule.PathPattern = pattern
+	default:
+		return fmt.Errorf("unknown pathMatch mode %q", rule.PathMatch)
+	}
+
+	if err := compileValueMatchers("query", rule.Query); err != nil {
+		return err
+	}
+
+	if err := compileValueMatchers("header", rule.Headers); err != nil {
+		return err
+	}
+
+	return CompileBodyMatcher(rule.Body)
+}
+
+// compileTemplate splits a path with {name} segments into its segments
+// Returns nil for a path without any, which is matched literally
+func compileTemplate(path string) []string {
+	segments := strings.Split(path, "/")
+	for _, segment := range segments {
+		if isParamSegment(segment) {
+			return segments
+		}
+	}
+	return nil
+}
+
+// literalLength measures the literal part of a path so that longer prefixes and templates
+// with more fixed segments rank above shorter ones in the same tier
+func literalLength(rule *models.MockRule) int {
+	if rule.PathMatch == models.PathMatchRegex {
+		return 0
+	}
+
+	length := 0
+	for _, segment := range strings.Split(rule.Path, "/") {
+		if isParamSegment(segment) {
+			continue
+		}
+		length += len(strings.NewReplacer("*", "", "?", "").Replace(segment))
+	}
+	return length
+}
+
+// CompileGlob compiles a path glob into an anchored regular expression
+func CompileGlob(glob string) (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(globToRegexp(glob))
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob path %q: %w", glob, err)
+	}
+	return pattern, nil
+}
+
+// globToRegexp converts a glob pattern into an anchored regular expression
+// "*" matches within a single path segment, "**" matches across segments
+// and "?" matches a single non-separator character
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		ch := glob[i]
+		switch {
+		case ch == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			i++
+			// "**/" also matches zero directories
+			if i+1 < len(glob) && glob[i+1] == '/' {
+				i++
+				sb.WriteString("(?:.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
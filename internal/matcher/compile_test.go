@@ -0,0 +1,66 @@
+package matcher
+
+import (
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// TestGlobToRegexp tests glob pattern semantics
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob    string
+		path    string
+		matches bool
+	}{
+		{"/static/**", "/static/css/site.css", true},
+		{"/static/**", "/static/", true},
+		{"/static/**", "/assets/site.css", false},
+		{"/api/*/health", "/api/orders/health", true},
+		{"/api/*/health", "/api/orders/v1/health", false},
+		{"/api/**/health", "/api/health", true},
+		{"/api/**/health", "/api/orders/v1/health", true},
+		{"/files/report-?.csv", "/files/report-1.csv", true},
+		{"/files/report-?.csv", "/files/report-10.csv", false},
+		{"/files/*.json", "/files/data.json", true},
+		{"/files/*.json", "/files/dataxjson", false},
+	}
+
+	for _, tt := range tests {
+		rule := &models.MockRule{Path: tt.glob, PathMatch: models.PathMatchGlob}
+		if err := CompileRule(rule); err != nil {
+			t.Fatalf("CompileRule(%q) failed: %v", tt.glob, err)
+		}
+
+		if got := rule.PathPattern.MatchString(tt.path); got != tt.matches {
+			t.Errorf("glob %q against %q: expected %v, got %v", tt.glob, tt.path, tt.matches, got)
+		}
+	}
+}
+
+// TestCompileRuleErrors tests that invalid rules are rejected
+func TestCompileRuleErrors(t *testing.T) {
+	tests := []models.MockRule{
+		{Path: "/items/(\\d+", PathMatch: models.PathMatchRegex},
+		{Path: "/items", PathMatch: "fuzzy"},
+	}
+
+	for i := range tests {
+		if err := CompileRule(&tests[i]); err == nil {
+			t.Errorf("Expected CompileRule to fail for %+v", tests[i])
+		}
+	}
+}
+
+// TestCompileRuleExactAndPrefix tests that exact and prefix rules need no compiled pattern
+func TestCompileRuleExactAndPrefix(t *testing.T) {
+	for _, mode := range []string{"", models.PathMatchExact, models.PathMatchPrefix} {
+		rule := &models.MockRule{Path: "/api/users", PathMatch: mode}
+		if err := CompileRule(rule); err != nil {
+			t.Errorf("CompileRule failed for mode %q: %v", mode, err)
+		}
+		if rule.PathPattern != nil {
+			t.Errorf("Expected no compiled pattern for mode %q", mode)
+		}
+	}
+}
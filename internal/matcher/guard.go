@@ -0,0 +1,24 @@
+package matcher
+
+import "mock-service/internal/models"
+
+// RuleGuard reports whether a rule may currently match
+// Rules it rejects are skipped as if their constraints did not match, e.g. exhausted response sequences
+type RuleGuard func(rule *models.MockRule) bool
+
+// accepts reports whether the request satisfies the rule's non-path constraints and the guard lets the rule match
+func accepts(guard RuleGuard, rule *models.MockRule, req *models.Request) bool {
+	return matchesConstraints(rule, req) && (guard == nil || guard(rule))
+}
+
+// AllGuards returns a guard that lets a rule match only when every guard does
+func AllGuards(guards ...RuleGuard) RuleGuard {
+	return func(rule *models.MockRule) bool {
+		for _, guard := range guards {
+			if !guard(rule) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,34 @@
+package matcher
+
+import (
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// TestRuleGuard tests that both matchers skip rules rejected by the guard, for every strategy
+func TestRuleGuard(t *testing.T) {
+	rules := compileRules(t, []models.MockRule{
+		{ID: "blocked", Path: "/api/jobs/1", Code: 202, Priority: 10},
+		{ID: "open", Path: "/api/jobs/{id}", Code: 200},
+	})
+	guard := func(rule *models.MockRule) bool { return rule.ID != "blocked" }
+
+	pathMatcher := NewPathMatcher()
+	pathMatcher.SetRuleGuard(guard)
+	indexedMatcher := NewIndexedMatcher()
+	indexedMatcher.SetRuleGuard(guard)
+
+	for _, strategy := range []string{models.SelectionFirst, models.SelectionPriority, models.SelectionMostSpecific} {
+		pathMatcher.SetSelectionStrategy(strategy)
+		indexedMatcher.SetSelectionStrategy(strategy)
+
+		req := &models.Request{Method: "GET", Path: "/api/jobs/1"}
+		if match, found := pathMatcher.FindMatch(req, rules); !found || match.Rule.ID != "open" {
+			t.Errorf("PathMatcherImpl (%s): expected the open rule, got %+v", strategy, match)
+		}
+		if match, found := indexedMatcher.FindMatch(req, rules); !found || match.Rule.ID != "open" {
+			t.Errorf("IndexedMatcher (%s): expected the open rule, got %+v", strategy, match)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package matcher
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"mock-service/internal/models"
+)
+
+// IndexedMatcher implements the PathMatcher interface using a route index
+// Exact and template paths are stored in a segment trie with per-method buckets;
+// prefix, glob and regex rules are kept in a fallback list that is always evaluated.
+// It selects the same rule as PathMatcherImpl for every strategy, but only evaluates
+// rules whose path can possibly match the request
+type IndexedMatcher struct {
+	strategy string
+	guard    RuleGuard
+
+	mu    sync.RWMutex
+	index *routeIndex
+}
+
+// routeIndex is the index built for one published rule set
+type routeIndex struct {
+	// first and count identify the rule slice the index was built from
+	first *models.MockRule
+	count int
+
+	root     *trieNode
+	fallback []int
+}
+
+// trieNode is a node of the path segment trie
+type trieNode struct {
+	children map[string]*trieNode
+	param    *trieNode
+	rules    ruleBucket
+}
+
+// ruleBucket holds the indexes of rules ending at a trie node, grouped by method
+type ruleBucket struct {
+	byMethod  map[string][]int
+	anyMethod []int
+}
+
+// NewIndexedMatcher creates a new instance of IndexedMatcher using the first-match strategy
+func NewIndexedMatcher() *IndexedMatcher {
+	return &IndexedMatcher{strategy: models.SelectionFirst}
+}
+
+// SetSelectionStrategy sets the strategy used when several rules match a request
+// It is meant to be called during startup, before requests are served
+func (im *IndexedMatcher) SetSelectionStrategy(strategy string) {
+	im.strategy = strategy
+}
+
+// Rebuild builds the index for the given rules ahead of the first request
+// FindMatch rebuilds the index on its own whenever it receives a different rule set,
+// so calling Rebuild after loading the configuration only moves that cost to startup
+func (im *IndexedMatcher) Rebuild(rules []models.MockRule) {
+	index := buildRouteIndex(rules)
+
+	im.mu.Lock()
+	im.index = index
+	im.mu.Unlock()
+}
+
+// SetRuleGuard sets a guard that can stop rules from matching at request time
+// It is meant to be called during startup, before requests are served
+func (im *IndexedMatcher) SetRuleGuard(guard RuleGuard) {
+	im.guard = guard
+}
+
+// FindMatch finds the matching rule for the given request
+// Returns the match result and true if found, nil and false otherwise
+func (im *IndexedMatcher) FindMatch(req *models.Request, rules []models.MockRule) (*models.MatchResult, bool) {
+	// Handle empty rules list
+	if len(rules) == 0 {
+		return nil, false
+	}
+
+	indexes := im.indexFor(rules).lookup(req)
+
+	if im.strategy != "" && im.strategy != models.SelectionFirst {
+		var candidates []candidate
+		for _, i := range indexes {
+			if !accepts(im.guard, &rules[i], req) {
+				continue
+			}
+			if params, ok := matchPath(&rules[i], req.Path); ok {
+				candidates = append(candidates, candidate{index: i, rule: &rules[i], params: params})
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, false
+		}
+		best := selectCandidate(im.strategy, candidates)
+		return &models.MatchResult{Rule: best.rule, PathParams: best.params, Request: req}, true
+	}
+
+	// First pass: literal exact path matches win over patterns
+	for _, i := range indexes {
+		if isLiteral(&rules[i]) && accepts(im.guard, &rules[i], req) {
+			return &models.MatchResult{Rule: &rules[i], PathParams: map[string]string{}, Request: req}, true
+		}
+	}
+
+	// Second pass: pattern rules in configuration order
+	for _, i := range indexes {
+		if isLiteral(&rules[i]) || !accepts(im.guard, &rules[i], req) {
+			continue
+		}
+		if params, ok := matchPath(&rules[i], req.Path); ok {
+			return &models.MatchResult{Rule: &rules[i], PathParams: params, Request: req}, true
+		}
+	}
+
+	// No match found
+	return nil, false
+}
+
+// indexFor returns the index for the rule set, rebuilding it when the rules changed
+// Rule sets are identified by their backing array; ConfigManager publishes a new
+// slice whenever the configuration loads, which triggers a rebuild here
+func (im *IndexedMatcher) indexFor(rules []models.MockRule) *routeIndex {
+	im.mu.RLock()
+	index := im.index
+	im.mu.RUnlock()
+
+	if index != nil && index.first == &rules[0] && index.count == len(rules) {
+		return index
+	}
+
+	// Keep the index built here: a concurrent rebuild for another rule set may replace im.index
+	index = buildRouteIndex(rules)
+	im.mu.Lock()
+	im.index = index
+	im.mu.Unlock()
+	return index
+}
+
+// buildRouteIndex indexes every rule by path kind
+func buildRouteIndex(rules []models.MockRule) *routeIndex {
+	index := &routeIndex{count: len(rules), root: newTrieNode()}
+	if len(rules) > 0 {
+		index.first = &rules[0]
+	}
+
+	for i := range rules {
+		rule := &rules[i]
+		if rule.PathMatch != "" && rule.PathMatch != models.PathMatchExact {
+			index.fallback = append(index.fallback, i)
+			continue
+		}
+
+		node := index.root
+		for _, segment := range strings.Split(rule.Path, "/") {
+			node = node.child(segment)
+		}
+		node.rules.add(rule.Method, i)
+	}
+
+	return index
+}
+
+// lookup returns the indexes of every rule whose path may match the request, in configuration order
+func (index *routeIndex) lookup(req *models.Request) []int {
+	var indexes []int
+	method := strings.ToUpper(req.Method)
+
+	var walk func(node *trieNode, segments []string)
+	walk = func(node *trieNode, segments []string) {
+		if len(segments) == 0 {
+			indexes = append(indexes, node.rules.byMethod[method]...)
+			indexes = append(indexes, node.rules.anyMethod...)
+			return
+		}
+		if next, ok := node.children[segments[0]]; ok {
+			walk(next, segments[1:])
+		}
+		if node.param != nil && segments[0] != "" {
+			walk(node.param, segments[1:])
+		}
+	}
+	walk(index.root, strings.Split(req.Path, "/"))
+
+	indexes = append(indexes, index.fallback...)
+	sort.Ints(indexes)
+	return indexes
+}
+
+// newTrieNode creates an empty trie node
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[string]*trieNode)}
+}
+
+// child returns the node for a path segment, creating it if needed
+// All {name} segments share the same parameter node regardless of their name
+func (n *trieNode) child(segment string) *trieNode {
+	if isParamSegment(segment) {
+		if n.param == nil {
+			n.param = newTrieNode()
+		}
+		return n.param
+	}
+
+	next, ok := n.children[segment]
+	if !ok {
+		next = newTrieNode()
+		n.children[segment] = next
+	}
+	return next
+}
+
+// add registers a rule index under each of its methods, or under any method when unrestricted
+func (b *ruleBucket) add(methods models.MethodList, index int) {
+	if len(methods) == 0 {
+		b.anyMethod = append(b.anyMethod, index)
+		return
+	}
+
+	if b.byMethod == nil {
+		b.byMethod = make(map[string][]int)
+	}
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		b.byMethod[method] = append(b.byMethod[method], index)
+	}
+}
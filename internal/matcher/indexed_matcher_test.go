@@ -0,0 +1,206 @@
+package matcher
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"mock-service/internal/models"
+	"mock-service/internal/testutil"
+)
+
+// equivalenceRules returns a rule set mixing every path kind, method lists and constraints
+func equivalenceRules(t testing.TB) []models.MockRule {
+	rules := []models.MockRule{
+		{Path: "/api/users/{id}", Method: models.MethodList{"GET"}, Code: 1},
+		{Path: "/api/users/me", Code: 2},
+		{Path: "/api/users/{id}", Method: models.MethodList{"DELETE", "PUT"}, Code: 3},
+		{Path: "/api/**", PathMatch: models.PathMatchGlob, Code: 4, Priority: 5},
+		{Path: "/api/users", Method: models.MethodList{"POST"}, Code: 5},
+		{Path: "/api/users", Query: map[string]models.ValueMatcher{"status": {Equals: testutil.Ptr("active")}}, Code: 6},
+		{Path: "/api/users", Code: 7},
+		{Path: `^/api/users/(?P<id>\d+)$`, PathMatch: models.PathMatchRegex, Code: 8, Priority: 1},
+		{Path: "/api/users/{id}/orders/{orderId}", Code: 9},
+		{Path: "/static/", PathMatch: models.PathMatchPrefix, Code: 10},
+		{Path: "/api/{resource}/{id}", Code: 11, Priority: 2},
+		{Path: "/", Code: 12},
+		{Path: "/api/users/", Code: 13},
+	}
+
+	compiled := make([]models.MockRule, len(rules))
+	copy(compiled, rules)
+	for i := range compiled {
+		if err := CompileRule(&compiled[i]); err != nil {
+			t.Fatalf("Failed to compile rule %d: %v", i, err)
+		}
+	}
+	return compiled
+}
+
+// TestIndexedMatcherEquivalence tests that the indexed matcher selects the same rule as the linear matcher
+func TestIndexedMatcherEquivalence(t *testing.T) {
+	rules := equivalenceRules(t)
+
+	requests := []*models.Request{
+		{Method: "GET", Path: "/api/users/42"},
+		{Method: "get", Path: "/api/users/42"},
+		{Method: "DELETE", Path: "/api/users/42"},
+		{Method: "PATCH", Path: "/api/users/42"},
+		{Method: "GET", Path: "/api/users/me"},
+		{Method: "POST", Path: "/api/users"},
+		{Method: "GET", Path: "/api/users", Query: map[string][]string{"status": {"active"}}},
+		{Method: "GET", Path: "/api/users"},
+		{Method: "GET", Path: "/api/users/"},
+		{Method: "GET", Path: "/api/users/42/orders/7"},
+		{Method: "GET", Path: "/api/products/9"},
+		{Method: "GET", Path: "/api//9"},
+		{Method: "GET", Path: "/static/app.js"},
+		{Method: "GET", Path: "/"},
+		{Method: "GET", Path: "/unknown"},
+	}
+
+	for _, strategy := range []string{models.SelectionFirst, models.SelectionPriority, models.SelectionMostSpecific} {
+		linear := NewPathMatcher()
+		linear.SetSelectionStrategy(strategy)
+		indexed := NewIndexedMatcher()
+		indexed.SetSelectionStrategy(strategy)
+
+		for _, req := range requests {
+			expected, expectedFound := linear.FindMatch(req, rules)
+			actual, actualFound := indexed.FindMatch(req, rules)
+
+			if expectedFound != actualFound {
+				t.Errorf("%s %s %s: expected found=%v, got %v", strategy, req.Method, req.Path, expectedFound, actualFound)
+				continue
+			}
+			if !expectedFound {
+				continue
+			}
+			if expected.Rule != actual.Rule {
+				t.Errorf("%s %s %s: expected rule %d, got %d",
+					strategy, req.Method, req.Path, expected.Rule.Code, actual.Rule.Code)
+			}
+			if fmt.Sprint(expected.PathParams) != fmt.Sprint(actual.PathParams) {
+				t.Errorf("%s %s %s: expected params %v, got %v",
+					strategy, req.Method, req.Path, expected.PathParams, actual.PathParams)
+			}
+		}
+	}
+}
+
+// TestIndexedMatcherRebuildsOnNewRules tests that a new rule slice replaces the previous index
+func TestIndexedMatcherRebuildsOnNewRules(t *testing.T) {
+	im := NewIndexedMatcher()
+	req := &models.Request{Method: "GET", Path: "/api/users"}
+
+	first := []models.MockRule{{Path: "/api/users", Code: 200}}
+	im.Rebuild(first)
+	if match, found := im.FindMatch(req, first); !found || match.Rule.Code != 200 {
+		t.Fatalf("Expected code 200 from the first rule set, got %v", match)
+	}
+
+	second := []models.MockRule{{Path: "/api/products", Code: 201}, {Path: "/api/users", Code: 202}}
+	if match, found := im.FindMatch(req, second); !found || match.Rule.Code != 202 {
+		t.Fatalf("Expected code 202 from the second rule set, got %v", match)
+	}
+}
+
+// TestIndexedMatcherConcurrentRuleSets tests that each request is matched against the index of its own rule set
+// while rule changes publish new slices
+func TestIndexedMatcherConcurrentRuleSets(t *testing.T) {
+	im := NewIndexedMatcher()
+	req := &models.Request{Method: "GET", Path: "/api/users"}
+
+	short := []models.MockRule{{Path: "/api/users", Code: 200}}
+	long := []models.MockRule{
+		{Path: "/a", Code: 1}, {Path: "/b", Code: 2}, {Path: "/c", Code: 3}, {Path: "/api/users", Code: 201},
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				rules, want := short, 200
+				if (g+i)%2 == 0 {
+					// A fresh slice each time, as every rule change publishes one
+					rules, want = append([]models.MockRule(nil), long...), 201
+				}
+				if match, found := im.FindMatch(req, rules); !found || match.Rule.Code != want {
+					t.Errorf("Expected code %d, got %v", want, match)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// TestIndexedMatcherEmptyRules tests that an empty rule set never matches
+func TestIndexedMatcherEmptyRules(t *testing.T) {
+	im := NewIndexedMatcher()
+
+	if _, found := im.FindMatch(&models.Request{Method: "GET", Path: "/api/users"}, nil); found {
+		t.Error("Expected no match for empty rules")
+	}
+}
+
+// generatedRules builds a large rule set similar to generated configurations
+// Every resource has a collection, an item template and a few method-specific rules
+func generatedRules(b *testing.B, resources int) []models.MockRule {
+	rules := make([]models.MockRule, 0, resources*4+1)
+	for i := 0; i < resources; i++ {
+		base := fmt.Sprintf("/api/v1/resource%d", i)
+		rules = append(rules,
+			models.MockRule{Path: base, Method: models.MethodList{"GET"}, Code: 200},
+			models.MockRule{Path: base, Method: models.MethodList{"POST"}, Code: 201},
+			models.MockRule{Path: base + "/{id}", Method: models.MethodList{"GET"}, Code: 200},
+			models.MockRule{Path: base + "/{id}", Method: models.MethodList{"DELETE"}, Code: 204},
+		)
+	}
+	rules = append(rules, models.MockRule{Path: "/health/**", PathMatch: models.PathMatchGlob, Code: 200})
+
+	for i := range rules {
+		if err := CompileRule(&rules[i]); err != nil {
+			b.Fatalf("Failed to compile rule %d: %v", i, err)
+		}
+	}
+	return rules
+}
+
+// benchmarkMatcher measures lookups of the last resource, the worst case for a linear scan
+func benchmarkMatcher(b *testing.B, matcher interface {
+	FindMatch(*models.Request, []models.MockRule) (*models.MatchResult, bool)
+}, resources int) {
+	rules := generatedRules(b, resources)
+	req := &models.Request{Method: "DELETE", Path: fmt.Sprintf("/api/v1/resource%d/42", resources-1)}
+
+	if _, found := matcher.FindMatch(req, rules); !found {
+		b.Fatal("Expected the benchmark request to match")
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		matcher.FindMatch(req, rules)
+	}
+}
+
+// BenchmarkLinearMatcher measures the linear matcher on large rule sets
+func BenchmarkLinearMatcher(b *testing.B) {
+	for _, resources := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("rules=%d", resources*4+1), func(b *testing.B) {
+			benchmarkMatcher(b, NewPathMatcher(), resources)
+		})
+	}
+}
+
+// BenchmarkIndexedMatcher measures the indexed matcher on large rule sets
+func BenchmarkIndexedMatcher(b *testing.B) {
+	for _, resources := range []int{100, 1000, 5000} {
+		b.Run(fmt.Sprintf("rules=%d", resources*4+1), func(b *testing.B) {
+			benchmarkMatcher(b, NewIndexedMatcher(), resources)
+		})
+	}
+}
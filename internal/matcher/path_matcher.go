@@ -1,32 +1,217 @@
 package matcher
 
-import "mock-service/internal/models"
+import (
+	"strings"
+
+	"mock-service/internal/models"
+)
 
 // PathMatcherImpl implements the PathMatcher interface
 // It handles matching request paths against configured rules
-type PathMatcherImpl struct{}
+type PathMatcherImpl struct {
+	strategy string
+	guard    RuleGuard
+}
 
-// NewPathMatcher creates a new instance of PathMatcher
+// NewPathMatcher creates a new instance of PathMatcher using the first-match strategy
 func NewPathMatcher() *PathMatcherImpl {
-	return &PathMatcherImpl{}
+	return &PathMatcherImpl{strategy: models.SelectionFirst}
+}
+
+// SetSelectionStrategy sets the strategy used when several rules match a request
+// It is meant to be called during startup, before requests are served
+func (pm *PathMatcherImpl) SetSelectionStrategy(strategy string) {
+	pm.strategy = strategy
+}
+
+// SetRuleGuard sets a guard that can stop rules from matching at request time
+// It is meant to be called during startup, before requests are served
+func (pm *PathMatcherImpl) SetRuleGuard(guard RuleGuard) {
+	pm.guard = guard
 }
 
-// FindMatch finds the first matching rule for the given request path
-// Returns the matched rule and true if found, nil and false otherwise
-// Rules are processed sequentially in the order they appear in the configuration
-func (pm *PathMatcherImpl) FindMatch(requestPath string, rules []models.MockRule) (*models.MockRule, bool) {
+// FindMatch finds the matching rule for the given request
+// Returns the match result and true if found, nil and false otherwise
+// With the first strategy, rules with a literal exact path take precedence over
+// every other rule; the remaining rules (templates, prefix, glob and regex) are
+// processed sequentially in the order they appear in the configuration and the
+// first match wins. The priority and most-specific strategies rank every matching rule
+func (pm *PathMatcherImpl) FindMatch(req *models.Request, rules []models.MockRule) (*models.MatchResult, bool) {
 	// Handle empty rules list
 	if len(rules) == 0 {
 		return nil, false
 	}
 
-	// Iterate through rules sequentially to find exact match
+	if pm.strategy != "" && pm.strategy != models.SelectionFirst {
+		return pm.findBest(req, rules)
+	}
+
+	// First pass: literal exact path matches win over patterns
 	for i := range rules {
-		if rules[i].Path == requestPath {
-			return &rules[i], true
+		if !isLiteral(&rules[i]) {
+			continue
+		}
+		if rules[i].Path == req.Path && accepts(pm.guard, &rules[i], req) {
+			return &models.MatchResult{Rule: &rules[i], PathParams: map[string]string{}, Request: req}, true
+		}
+	}
+
+	// Second pass: pattern rules in configuration order
+	for i := range rules {
+		if isLiteral(&rules[i]) || !accepts(pm.guard, &rules[i], req) {
+			continue
+		}
+		if params, ok := matchPath(&rules[i], req.Path); ok {
+			return &models.MatchResult{Rule: &rules[i], PathParams: params, Request: req}, true
 		}
 	}
 
 	// No match found
 	return nil, false
 }
+
+// findBest evaluates every rule and picks the winner with the configured strategy
+func (pm *PathMatcherImpl) findBest(req *models.Request, rules []models.MockRule) (*models.MatchResult, bool) {
+	var candidates []candidate
+	for i := range rules {
+		if !accepts(pm.guard, &rules[i], req) {
+			continue
+		}
+		if params, ok := matchPath(&rules[i], req.Path); ok {
+			candidates = append(candidates, candidate{index: i, rule: &rules[i], params: params})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, false
+	}
+
+	best := selectCandidate(pm.strategy, candidates)
+	return &models.MatchResult{Rule: best.rule, PathParams: best.params, Request: req}, true
+}
+
+// isLiteral reports whether the rule matches a single literal path
+func isLiteral(rule *models.MockRule) bool {
+	return (rule.PathMatch == "" || rule.PathMatch == models.PathMatchExact) && !isTemplate(rule)
+}
+
+// matchPath matches the request path according to the rule's path match mode
+// Returns the captured path parameters (template segments or named regex groups)
+func matchPath(rule *models.MockRule, requestPath string) (map[string]string, bool) {
+	switch rule.PathMatch {
+	case models.PathMatchPrefix:
+		if strings.HasPrefix(requestPath, rule.Path) {
+			return map[string]string{}, true
+		}
+		return nil, false
+	case models.PathMatchGlob, models.PathMatchRegex:
+		return matchPattern(rule, requestPath)
+	default:
+		if isTemplate(rule) {
+			return matchTemplate(rule.PathTemplate, requestPath)
+		}
+		if rule.Path == requestPath {
+			return map[string]string{}, true
+		}
+		return nil, false
+	}
+}
+
+// matchPattern matches the request path against the rule's compiled glob or regex
+// Named capture groups of a regex are returned as path parameters
+// Rules that were not compiled with CompileRule never match
+func matchPattern(rule *models.MockRule, requestPath string) (map[string]string, bool) {
+	if rule.PathPattern == nil {
+		return nil, false
+	}
+
+	submatches := rule.PathPattern.FindStringSubmatch(requestPath)
+	if submatches == nil {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, name := range rule.PathPattern.SubexpNames() {
+		if i > 0 && name != "" {
+			params[name] = submatches[i]
+		}
+	}
+
+	return params, true
+}
+
+// matchesConstraints reports whether the request satisfies the rule's non-path constraints
+func matchesConstraints(rule *models.MockRule, req *models.Request) bool {
+	if !matchesMethod(rule.Method, req.Method) {
+		return false
+	}
+
+	for name := range rule.Query {
+		vm := rule.Query[name]
+		if !matchValues(&vm, req.Query[name]) {
+			return false
+		}
+	}
+
+	// Header.Values canonicalizes the name, making header matching case-insensitive
+	for name := range rule.Headers {
+		vm := rule.Headers[name]
+		if !matchValues(&vm, req.Header.Values(name)) {
+			return false
+		}
+	}
+
+	return MatchBody(rule.Body, req)
+}
+
+// matchesMethod reports whether the request method is allowed by the rule
+// Rules without any method restriction match every method
+func matchesMethod(allowed models.MethodList, method string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, candidate := range allowed {
+		if strings.EqualFold(candidate, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTemplate reports whether the rule path contains at least one {name} segment
+// Rules that were not compiled with CompileRule match their path literally
+func isTemplate(rule *models.MockRule) bool {
+	return rule.PathTemplate != nil
+}
+
+// isParamSegment reports whether a single path segment is a {name} placeholder
+func isParamSegment(segment string) bool {
+	return len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// matchTemplate matches the request path against the segments of a path template one by one
+// Placeholder segments match any non-empty segment and their values are returned by name
+func matchTemplate(templateSegments []string, requestPath string) (map[string]string, bool) {
+	pathSegments := strings.Split(requestPath, "/")
+	if len(templateSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range templateSegments {
+		if isParamSegment(segment) {
+			if pathSegments[i] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
@@ -1,9 +1,12 @@
 package matcher
 
 import (
+	"net/http"
+	"net/url"
 	"testing"
 
 	"mock-service/internal/models"
+	"mock-service/internal/testutil"
 )
 
 // TestNewPathMatcher tests the creation of a new PathMatcher instance
@@ -32,27 +35,27 @@ func TestFindMatchExactPath(t *testing.T) {
 	}
 
 	// Test exact match for first rule
-	rule, found := pm.FindMatch("/api/users", rules)
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users"}, rules)
 	if !found {
 		t.Error("Expected to find match for '/api/users'")
 	}
-	if rule == nil {
+	if match == nil {
 		t.Fatal("Expected non-nil rule")
 	}
-	if rule.Path != "/api/users" {
-		t.Errorf("Expected path '/api/users', got '%s'", rule.Path)
+	if match.Rule.Path != "/api/users" {
+		t.Errorf("Expected path '/api/users', got '%s'", match.Rule.Path)
 	}
 
 	// Test exact match for second rule
-	rule, found = pm.FindMatch("/api/products", rules)
+	match, found = pm.FindMatch(&models.Request{Method: "GET", Path: "/api/products"}, rules)
 	if !found {
 		t.Error("Expected to find match for '/api/products'")
 	}
-	if rule == nil {
+	if match == nil {
 		t.Fatal("Expected non-nil rule")
 	}
-	if rule.Path != "/api/products" {
-		t.Errorf("Expected path '/api/products', got '%s'", rule.Path)
+	if match.Rule.Path != "/api/products" {
+		t.Errorf("Expected path '/api/products', got '%s'", match.Rule.Path)
 	}
 }
 
@@ -69,11 +72,11 @@ func TestFindMatchNoMatch(t *testing.T) {
 	}
 
 	// Test no match scenario
-	rule, found := pm.FindMatch("/api/orders", rules)
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/orders"}, rules)
 	if found {
 		t.Error("Expected no match for '/api/orders'")
 	}
-	if rule != nil {
+	if match != nil {
 		t.Error("Expected nil rule when no match found")
 	}
 }
@@ -85,11 +88,11 @@ func TestFindMatchEmptyRules(t *testing.T) {
 	var rules []models.MockRule
 
 	// Test with empty rules
-	rule, found := pm.FindMatch("/any/path", rules)
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/any/path"}, rules)
 	if found {
 		t.Error("Expected no match with empty rules")
 	}
-	if rule != nil {
+	if match != nil {
 		t.Error("Expected nil rule with empty rules")
 	}
 }
@@ -112,20 +115,20 @@ func TestFindMatchSequentialMatching(t *testing.T) {
 	}
 
 	// Should match the first rule (sequential processing)
-	rule, found := pm.FindMatch("/api/test", rules)
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/test"}, rules)
 	if !found {
 		t.Error("Expected to find match for '/api/test'")
 	}
-	if rule == nil {
+	if match == nil {
 		t.Fatal("Expected non-nil rule")
 	}
 
 	// Verify it matched the first rule, not the second
-	if rule.Code != 200 {
-		t.Errorf("Expected first rule (code 200), got code %d", rule.Code)
+	if match.Rule.Code != 200 {
+		t.Errorf("Expected first rule (code 200), got code %d", match.Rule.Code)
 	}
 
-	response, ok := rule.Response["message"].(string)
+	response, ok := match.Rule.Response["message"].(string)
 	if !ok {
 		t.Fatal("Expected string message in response")
 	}
@@ -147,20 +150,475 @@ func TestFindMatchCaseSensitive(t *testing.T) {
 	}
 
 	// Test case sensitivity - should not match
-	rule, found := pm.FindMatch("/api/users", rules)
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users"}, rules)
 	if found {
 		t.Error("Expected no match for case-different path '/api/users'")
 	}
-	if rule != nil {
+	if match != nil {
 		t.Error("Expected nil rule for case-different path")
 	}
 
 	// Test exact case - should match
-	rule, found = pm.FindMatch("/api/Users", rules)
+	match, found = pm.FindMatch(&models.Request{Method: "GET", Path: "/api/Users"}, rules)
 	if !found {
 		t.Error("Expected match for exact case '/api/Users'")
 	}
-	if rule == nil {
+	if match == nil {
 		t.Error("Expected non-nil rule for exact case match")
 	}
 }
+
+// TestFindMatchByMethod tests that rules restricted to a method only match that method
+func TestFindMatchByMethod(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := []models.MockRule{
+		{
+			Path:     "/api/users",
+			Method:   models.MethodList{"GET"},
+			Response: map[string]interface{}{"message": "List users"},
+			Code:     200,
+		},
+		{
+			Path:     "/api/users",
+			Method:   models.MethodList{"DELETE"},
+			Response: map[string]interface{}{"message": "Deleted"},
+			Code:     204,
+		},
+	}
+
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected to find match for GET '/api/users'")
+	}
+	if match.Rule.Code != 200 {
+		t.Errorf("Expected GET rule (code 200), got code %d", match.Rule.Code)
+	}
+
+	match, found = pm.FindMatch(&models.Request{Method: "DELETE", Path: "/api/users"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected to find match for DELETE '/api/users'")
+	}
+	if match.Rule.Code != 204 {
+		t.Errorf("Expected DELETE rule (code 204), got code %d", match.Rule.Code)
+	}
+
+	// Method not listed by any rule should not match
+	match, found = pm.FindMatch(&models.Request{Method: "POST", Path: "/api/users"}, rules)
+	if found {
+		t.Error("Expected no match for POST '/api/users'")
+	}
+	if match != nil {
+		t.Error("Expected nil rule when method does not match")
+	}
+}
+
+// TestFindMatchMethodList tests rules that accept several methods
+func TestFindMatchMethodList(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := []models.MockRule{
+		{
+			Path:     "/api/items",
+			Method:   models.MethodList{"PUT", "PATCH"},
+			Response: map[string]interface{}{"message": "Updated"},
+			Code:     200,
+		},
+	}
+
+	for _, method := range []string{"PUT", "PATCH", "patch"} {
+		if _, found := pm.FindMatch(&models.Request{Method: method, Path: "/api/items"}, rules); !found {
+			t.Errorf("Expected match for %s '/api/items'", method)
+		}
+	}
+
+	if _, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/items"}, rules); found {
+		t.Error("Expected no match for GET '/api/items'")
+	}
+}
+
+// TestFindMatchWithoutMethodMatchesAll tests that rules without a method match every method
+func TestFindMatchWithoutMethodMatchesAll(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := []models.MockRule{
+		{
+			Path:     "/api/any",
+			Response: map[string]interface{}{"message": "Any method"},
+			Code:     200,
+		},
+	}
+
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"} {
+		if _, found := pm.FindMatch(&models.Request{Method: method, Path: "/api/any"}, rules); !found {
+			t.Errorf("Expected match for %s '/api/any'", method)
+		}
+	}
+}
+
+// TestFindMatchPathTemplate tests that {name} segments match any value and capture it
+func TestFindMatchPathTemplate(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:     "/api/users/{id}/orders/{orderId}",
+			Response: map[string]interface{}{"message": "Order"},
+			Code:     200,
+		},
+	})
+
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users/42/orders/A-7"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected template to match '/api/users/42/orders/A-7'")
+	}
+
+	if match.PathParams["id"] != "42" {
+		t.Errorf("Expected id=42, got %s", match.PathParams["id"])
+	}
+	if match.PathParams["orderId"] != "A-7" {
+		t.Errorf("Expected orderId=A-7, got %s", match.PathParams["orderId"])
+	}
+}
+
+// TestFindMatchPathTemplateNoMatch tests template paths against non-matching request paths
+func TestFindMatchPathTemplateNoMatch(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:     "/api/users/{id}",
+			Response: map[string]interface{}{"message": "User"},
+			Code:     200,
+		},
+	})
+
+	tests := []string{
+		"/api/users",
+		"/api/users/",
+		"/api/users/42/orders",
+		"/api/accounts/42",
+	}
+
+	for _, requestPath := range tests {
+		if _, found := pm.FindMatch(&models.Request{Method: "GET", Path: requestPath}, rules); found {
+			t.Errorf("Expected no match for '%s'", requestPath)
+		}
+	}
+}
+
+// TestFindMatchExactPathWinsOverTemplate tests that exact paths take precedence over templates
+func TestFindMatchExactPathWinsOverTemplate(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:     "/api/users/{id}",
+			Response: map[string]interface{}{"message": "Any user"},
+			Code:     200,
+		},
+		{
+			Path:     "/api/users/me",
+			Response: map[string]interface{}{"message": "Current user"},
+			Code:     200,
+		},
+	})
+
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users/me"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected to find match for '/api/users/me'")
+	}
+	if match.Rule.Path != "/api/users/me" {
+		t.Errorf("Expected exact rule to win, got '%s'", match.Rule.Path)
+	}
+	if len(match.PathParams) != 0 {
+		t.Errorf("Expected no path params for exact match, got %v", match.PathParams)
+	}
+
+	match, found = pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users/7"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected template to match '/api/users/7'")
+	}
+	if match.Rule.Path != "/api/users/{id}" {
+		t.Errorf("Expected template rule, got '%s'", match.Rule.Path)
+	}
+}
+
+// TestFindMatchTemplateRespectsMethod tests that an exact rule for another method falls back to a template
+func TestFindMatchTemplateRespectsMethod(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:     "/api/users/me",
+			Method:   models.MethodList{"GET"},
+			Response: map[string]interface{}{"message": "Current user"},
+			Code:     200,
+		},
+		{
+			Path:     "/api/users/{id}",
+			Response: map[string]interface{}{"message": "Any user"},
+			Code:     200,
+		},
+	})
+
+	match, found := pm.FindMatch(&models.Request{Method: "DELETE", Path: "/api/users/me"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected template to match DELETE '/api/users/me'")
+	}
+	if match.PathParams["id"] != "me" {
+		t.Errorf("Expected id=me, got %s", match.PathParams["id"])
+	}
+}
+
+// compileRules compiles all rules the way ConfigManager does at load time
+func compileRules(t *testing.T, rules []models.MockRule) []models.MockRule {
+	t.Helper()
+	for i := range rules {
+		if err := CompileRule(&rules[i]); err != nil {
+			t.Fatalf("CompileRule failed for %q: %v", rules[i].Path, err)
+		}
+	}
+	return rules
+}
+
+// TestFindMatchPathModes tests prefix, glob and regex path matching
+func TestFindMatchPathModes(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: "/static/**", PathMatch: models.PathMatchGlob, Code: 200},
+		{Path: "/api/*/health", PathMatch: models.PathMatchGlob, Code: 201},
+		{Path: `^/v[0-9]+/items/\d+$`, PathMatch: models.PathMatchRegex, Code: 202},
+		{Path: "/legacy/", PathMatch: models.PathMatchPrefix, Code: 203},
+	})
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/static/js/app.js", 200},
+		{"/api/orders/health", 201},
+		{"/v2/items/15", 202},
+		{"/legacy/anything/goes", 203},
+	}
+
+	for _, tt := range tests {
+		match, found := pm.FindMatch(&models.Request{Method: "GET", Path: tt.path}, rules)
+		if !found || match == nil {
+			t.Errorf("Expected match for '%s'", tt.path)
+			continue
+		}
+		if match.Rule.Code != tt.code {
+			t.Errorf("Expected '%s' to match rule with code %d, got %d", tt.path, tt.code, match.Rule.Code)
+		}
+	}
+
+	for _, path := range []string{"/v2/items/abc", "/api/orders/v1/health", "/legacy"} {
+		if _, found := pm.FindMatch(&models.Request{Method: "GET", Path: path}, rules); found {
+			t.Errorf("Expected no match for '%s'", path)
+		}
+	}
+}
+
+// TestFindMatchPatternFirstMatchWins tests that pattern rules keep configuration order
+func TestFindMatchPatternFirstMatchWins(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: "/api/**", PathMatch: models.PathMatchGlob, Code: 200},
+		{Path: "/api/users/{id}", Code: 201},
+	})
+
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users/1"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected match for '/api/users/1'")
+	}
+	if match.Rule.Code != 200 {
+		t.Errorf("Expected first pattern rule (code 200), got %d", match.Rule.Code)
+	}
+}
+
+// TestFindMatchRegexNamedGroups tests that named regex groups become path parameters
+func TestFindMatchRegexNamedGroups(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: `^/v(?P<version>[0-9]+)/items/(?P<id>\d+)$`, PathMatch: models.PathMatchRegex},
+	})
+
+	match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/v3/items/99"}, rules)
+	if !found || match == nil {
+		t.Fatal("Expected match for '/v3/items/99'")
+	}
+	if match.PathParams["version"] != "3" || match.PathParams["id"] != "99" {
+		t.Errorf("Expected version=3 and id=99, got %v", match.PathParams)
+	}
+}
+
+// TestFindMatchUncompiledPattern tests that uncompiled glob rules never match
+func TestFindMatchUncompiledPattern(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := []models.MockRule{
+		{Path: "/static/**", PathMatch: models.PathMatchGlob},
+	}
+
+	if _, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/static/app.js"}, rules); found {
+		t.Error("Expected uncompiled glob rule not to match")
+	}
+}
+
+// TestFindMatchUncompiledTemplate tests that uncompiled template rules only match their path literally
+func TestFindMatchUncompiledTemplate(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := []models.MockRule{
+		{Path: "/api/users/{id}"},
+	}
+
+	if _, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users/42"}, rules); found {
+		t.Error("Expected uncompiled template rule not to match")
+	}
+	if _, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users/{id}"}, rules); !found {
+		t.Error("Expected uncompiled template rule to match its literal path")
+	}
+}
+
+// TestFindMatchQueryParameters tests that rules can require query parameters
+func TestFindMatchQueryParameters(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:  "/api/users",
+			Query: map[string]models.ValueMatcher{"status": {Equals: testutil.Ptr("active")}},
+			Code:  200,
+		},
+		{
+			Path:  "/api/users",
+			Query: map[string]models.ValueMatcher{"status": {Equals: testutil.Ptr("archived")}},
+			Code:  410,
+		},
+		{
+			Path:  "/api/users",
+			Query: map[string]models.ValueMatcher{"page": {Regex: `^\d+$`}, "debug": {Absent: true}},
+			Code:  206,
+		},
+		{
+			Path: "/api/users",
+			Code: 404,
+		},
+	})
+
+	tests := []struct {
+		query url.Values
+		code  int
+	}{
+		{url.Values{"status": {"active"}}, 200},
+		{url.Values{"status": {"archived"}}, 410},
+		{url.Values{"page": {"2"}}, 206},
+		{url.Values{"page": {"2"}, "debug": {"1"}}, 404},
+		{url.Values{"page": {"two"}}, 404},
+		{url.Values{"tag": {"a", "b"}, "status": {"deleted", "archived"}}, 410},
+		{nil, 404},
+	}
+
+	for _, tt := range tests {
+		match, found := pm.FindMatch(&models.Request{Method: "GET", Path: "/api/users", Query: tt.query}, rules)
+		if !found || match == nil {
+			t.Errorf("Expected match for query %v", tt.query)
+			continue
+		}
+		if match.Rule.Code != tt.code {
+			t.Errorf("Expected query %v to match rule with code %d, got %d", tt.query, tt.code, match.Rule.Code)
+		}
+	}
+}
+
+// TestFindMatchHeaders tests header matchers with case-insensitive header names
+func TestFindMatchHeaders(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:    "/api/report",
+			Headers: map[string]models.ValueMatcher{"accept": {Contains: "xml"}},
+			Code:    200,
+		},
+		{
+			Path:    "/api/report",
+			Headers: map[string]models.ValueMatcher{"X-Api-Version": {Regex: `^2\.`}},
+			Code:    201,
+		},
+		{
+			Path:    "/api/report",
+			Headers: map[string]models.ValueMatcher{"Authorization": {Absent: true}},
+			Code:    401,
+		},
+		{
+			Path:    "/api/report",
+			Headers: map[string]models.ValueMatcher{"x-tenant": {Equals: testutil.Ptr("acme")}, "authorization": {Present: true}},
+			Code:    202,
+		},
+	})
+
+	tests := []struct {
+		name   string
+		header http.Header
+		code   int
+	}{
+		{"contains", http.Header{"Accept": {"application/xml"}}, 200},
+		{"regex", http.Header{"X-Api-Version": {"2.1"}, "Authorization": {"Bearer t"}}, 201},
+		{"absent", http.Header{}, 401},
+		{"equals and present", http.Header{"X-Tenant": {"acme"}, "Authorization": {"Bearer t"}}, 202},
+	}
+
+	for _, tt := range tests {
+		req := &models.Request{Method: "GET", Path: "/api/report", Header: tt.header}
+		match, found := pm.FindMatch(req, rules)
+		if !found || match == nil {
+			t.Errorf("%s: expected a match", tt.name)
+			continue
+		}
+		if match.Rule.Code != tt.code {
+			t.Errorf("%s: expected rule with code %d, got %d", tt.name, tt.code, match.Rule.Code)
+		}
+	}
+
+	req := &models.Request{Method: "GET", Path: "/api/report", Header: http.Header{"Authorization": {"Bearer t"}}}
+	if _, found := pm.FindMatch(req, rules); found {
+		t.Error("Expected no match when no header matcher is satisfied")
+	}
+}
+
+// TestFindMatchBody tests that body matchers select rules by payload
+func TestFindMatchBody(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{
+			Path:   "/api/users",
+			Method: models.MethodList{"POST"},
+			Body:   &models.BodyMatcher{JSONPath: map[string]models.ValueMatcher{"$.user.type": {Equals: testutil.Ptr("admin")}}},
+			Code:   201,
+		},
+		{
+			Path:   "/api/users",
+			Method: models.MethodList{"POST"},
+			Code:   403,
+		},
+	})
+
+	req := &models.Request{Method: "POST", Path: "/api/users", Body: []byte(`{"user": {"type": "admin"}}`)}
+	match, found := pm.FindMatch(req, rules)
+	if !found || match.Rule.Code != 201 {
+		t.Errorf("Expected admin payload to match rule with code 201, got %+v", match)
+	}
+
+	req = &models.Request{Method: "POST", Path: "/api/users", Body: []byte(`{"user": {"type": "guest"}}`)}
+	match, found = pm.FindMatch(req, rules)
+	if !found || match.Rule.Code != 403 {
+		t.Errorf("Expected guest payload to match rule with code 403, got %+v", match)
+	}
+}
@@ -0,0 +1,94 @@
+package matcher
+
+import "mock-service/internal/models"
+
+// Path specificity tiers used by the most-specific strategy, from least to most specific
+const (
+	tierRegex = iota
+	tierPrefix
+	tierGlob
+	tierTemplate
+	tierExact
+)
+
+// candidate is a rule that matched a request, together with its captured path parameters
+type candidate struct {
+	index  int
+	rule   *models.MockRule
+	params map[string]string
+}
+
+// selectCandidate picks the winning candidate according to the selection strategy
+// Candidates must be given in configuration order; unknown strategies behave like first
+func selectCandidate(strategy string, candidates []candidate) candidate {
+	best := candidates[0]
+	for _, c := range candidates[1:] {
+		if ranksHigher(strategy, c, best) {
+			best = c
+		}
+	}
+	return best
+}
+
+// ranksHigher reports whether candidate a should win over candidate b
+func ranksHigher(strategy string, a, b candidate) bool {
+	switch strategy {
+	case models.SelectionPriority:
+		if a.rule.Priority != b.rule.Priority {
+			return a.rule.Priority > b.rule.Priority
+		}
+	case models.SelectionMostSpecific:
+		if tierA, tierB := pathTier(a.rule), pathTier(b.rule); tierA != tierB {
+			return tierA > tierB
+		}
+		if countA, countB := constraintCount(a.rule), constraintCount(b.rule); countA != countB {
+			return countA > countB
+		}
+		if lengthA, lengthB := a.rule.PathLiteralLength, b.rule.PathLiteralLength; lengthA != lengthB {
+			return lengthA > lengthB
+		}
+		if a.rule.Priority != b.rule.Priority {
+			return a.rule.Priority > b.rule.Priority
+		}
+	}
+
+	// First-match order: literal paths before patterns, then configuration order
+	if literalA, literalB := isLiteral(a.rule), isLiteral(b.rule); literalA != literalB {
+		return literalA
+	}
+	return a.index < b.index
+}
+
+// pathTier classifies how specific the rule's path is
+func pathTier(rule *models.MockRule) int {
+	switch rule.PathMatch {
+	case models.PathMatchRegex:
+		return tierRegex
+	case models.PathMatchPrefix:
+		return tierPrefix
+	case models.PathMatchGlob:
+		return tierGlob
+	default:
+		if isTemplate(rule) {
+			return tierTemplate
+		}
+		return tierExact
+	}
+}
+
+// constraintCount counts the non-path constraints of a rule
+func constraintCount(rule *models.MockRule) int {
+	count := len(rule.Query) + len(rule.Headers)
+	if len(rule.Method) > 0 {
+		count++
+	}
+
+	if body := rule.Body; body != nil {
+		count += len(body.JSONPath) + len(body.XPath) + len(body.Form) + len(body.Multipart)
+		if body.EqualToJSON != nil {
+			count++
+		}
+	}
+
+	return count
+}
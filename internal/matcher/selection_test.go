@@ -0,0 +1,141 @@
+package matcher
+
+import (
+	"net/http"
+	"testing"
+
+	"mock-service/internal/models"
+	"mock-service/internal/testutil"
+)
+
+// findCode returns the code of the rule selected for a GET request, or -1 when nothing matches
+func findCode(t *testing.T, pm *PathMatcherImpl, req *models.Request, rules []models.MockRule) int {
+	t.Helper()
+	match, found := pm.FindMatch(req, rules)
+	if !found {
+		return -1
+	}
+	return match.Rule.Code
+}
+
+// TestSelectionPriority tests that the priority strategy picks the highest priority rule
+func TestSelectionPriority(t *testing.T) {
+	pm := NewPathMatcher()
+	pm.SetSelectionStrategy(models.SelectionPriority)
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: "/api/**", PathMatch: models.PathMatchGlob, Code: 200},
+		{Path: "/api/users", Code: 201},
+		{Path: "/api/users/{id}", Priority: 5, Code: 202},
+		{Path: "/api/users/{id}", Priority: 5, Code: 203},
+	})
+
+	req := &models.Request{Method: "GET", Path: "/api/users/7"}
+	if code := findCode(t, pm, req, rules); code != 202 {
+		t.Errorf("Expected first rule with highest priority (202), got %d", code)
+	}
+
+	// Equal priorities fall back to first-match order: literal path wins
+	req = &models.Request{Method: "GET", Path: "/api/users"}
+	if code := findCode(t, pm, req, rules); code != 201 {
+		t.Errorf("Expected literal rule (201), got %d", code)
+	}
+}
+
+// TestSelectionMostSpecific tests the most-specific ranking
+func TestSelectionMostSpecific(t *testing.T) {
+	pm := NewPathMatcher()
+	pm.SetSelectionStrategy(models.SelectionMostSpecific)
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: `^/api/.*$`, PathMatch: models.PathMatchRegex, Code: 100},
+		{Path: "/api/", PathMatch: models.PathMatchPrefix, Code: 110},
+		{Path: "/api/users/*", PathMatch: models.PathMatchGlob, Code: 120},
+		{Path: "/api/{resource}/{id}", Code: 130},
+		{Path: "/api/users/{id}", Code: 140},
+		{Path: "/api/users/{id}", Method: models.MethodList{"GET"}, Code: 150},
+		{
+			Path:    "/api/users/{id}",
+			Method:  models.MethodList{"GET"},
+			Headers: map[string]models.ValueMatcher{"X-Tenant": {Equals: testutil.Ptr("acme")}},
+			Code:    160,
+		},
+		{Path: "/api/users/me", Code: 170},
+	})
+
+	tests := []struct {
+		name   string
+		req    *models.Request
+		code   int
+		reason string
+	}{
+		{"exact", &models.Request{Method: "GET", Path: "/api/users/me"}, 170, "exact path beats templates"},
+		{
+			"constraints",
+			&models.Request{Method: "GET", Path: "/api/users/1", Header: http.Header{"X-Tenant": {"acme"}}},
+			160,
+			"most constraints wins",
+		},
+		{"method", &models.Request{Method: "GET", Path: "/api/users/1"}, 150, "method constraint beats catch-all"},
+		{"literal segments", &models.Request{Method: "POST", Path: "/api/users/1"}, 140, "more literal segments win"},
+		{"template", &models.Request{Method: "GET", Path: "/api/orders/1"}, 130, "template beats glob, prefix and regex"},
+		{"prefix", &models.Request{Method: "GET", Path: "/api/users/1/avatar"}, 110, "prefix beats regex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := findCode(t, pm, tt.req, rules); code != tt.code {
+				t.Errorf("%s: expected %d, got %d", tt.reason, tt.code, code)
+			}
+		})
+	}
+}
+
+// TestSelectionMostSpecificGlobOverRegex tests that glob rules rank above prefix and regex rules
+func TestSelectionMostSpecificGlobOverRegex(t *testing.T) {
+	pm := NewPathMatcher()
+	pm.SetSelectionStrategy(models.SelectionMostSpecific)
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: `^/static/.*\.js$`, PathMatch: models.PathMatchRegex, Code: 100},
+		{Path: "/static/", PathMatch: models.PathMatchPrefix, Code: 110},
+		{Path: "/static/**", PathMatch: models.PathMatchGlob, Code: 120},
+	})
+
+	req := &models.Request{Method: "GET", Path: "/static/js/app.js"}
+	if code := findCode(t, pm, req, rules); code != 120 {
+		t.Errorf("Expected glob rule (120), got %d", code)
+	}
+}
+
+// TestSelectionFirstIsDefault tests that the default strategy keeps first-match semantics
+func TestSelectionFirstIsDefault(t *testing.T) {
+	pm := NewPathMatcher()
+
+	rules := compileRules(t, []models.MockRule{
+		{Path: "/api/users/{id}", Code: 200},
+		{Path: "/api/users/{id}", Priority: 10, Code: 201},
+	})
+
+	req := &models.Request{Method: "GET", Path: "/api/users/1"}
+	if code := findCode(t, pm, req, rules); code != 200 {
+		t.Errorf("Expected first rule (200), got %d", code)
+	}
+
+	pm.SetSelectionStrategy(models.SelectionFirst)
+	if code := findCode(t, pm, req, rules); code != 200 {
+		t.Errorf("Expected first rule (200) with explicit first strategy, got %d", code)
+	}
+}
+
+// TestSelectionNoMatch tests ranking strategies when no rule matches
+func TestSelectionNoMatch(t *testing.T) {
+	pm := NewPathMatcher()
+	pm.SetSelectionStrategy(models.SelectionMostSpecific)
+
+	rules := compileRules(t, []models.MockRule{{Path: "/api/users", Code: 200}})
+
+	if code := findCode(t, pm, &models.Request{Method: "GET", Path: "/api/orders"}, rules); code != -1 {
+		t.Errorf("Expected no match, got %d", code)
+	}
+}
@@ -0,0 +1,97 @@
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"mock-service/internal/models"
+)
+
+// compileValueMatcher validates a value matcher and precompiles its regex
+func compileValueMatcher(vm *models.ValueMatcher) error {
+	if vm.Absent && (vm.Present || hasValuePredicates(vm)) {
+		return fmt.Errorf("absent cannot be combined with other predicates")
+	}
+
+	vm.Pattern = nil
+	if vm.Regex != "" {
+		pattern, err := regexp.Compile(vm.Regex)
+		if err != nil {
+			return fmt.Errorf("invalid regex %q: %w", vm.Regex, err)
+		}
+		vm.Pattern = pattern
+	}
+
+	return nil
+}
+
+// compileValueMatchers compiles every matcher of a named matcher block in place
+func compileValueMatchers(block string, matchers map[string]models.ValueMatcher) error {
+	for name, vm := range matchers {
+		if err := compileValueMatcher(&vm); err != nil {
+			return fmt.Errorf("%s matcher %q: %w", block, name, err)
+		}
+		matchers[name] = vm
+	}
+	return nil
+}
+
+// matchValues reports whether the values of a request attribute satisfy the matcher
+// Attributes may be repeated (e.g. ?tag=a&tag=b or several header lines); the matcher succeeds
+// when any value satisfies it
+func matchValues(vm *models.ValueMatcher, values []string) bool {
+	if vm.Absent {
+		return len(values) == 0
+	}
+
+	for _, value := range values {
+		if matchValue(vm, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchValue reports whether a single value satisfies every predicate of the matcher
+func matchValue(vm *models.ValueMatcher, value string) bool {
+	if vm.Equals != nil && value != *vm.Equals {
+		return false
+	}
+
+	if vm.Contains != "" && !strings.Contains(value, vm.Contains) {
+		return false
+	}
+
+	if vm.Regex != "" && (vm.Pattern == nil || !vm.Pattern.MatchString(value)) {
+		return false
+	}
+
+	return matchNumber(vm, value)
+}
+
+// matchNumber applies the numeric comparisons of the matcher
+// Values that are not numbers never satisfy a numeric comparison
+func matchNumber(vm *models.ValueMatcher, value string) bool {
+	if vm.GreaterThan == nil && vm.GreaterThanOrEqual == nil && vm.LessThan == nil && vm.LessThanOrEqual == nil {
+		return true
+	}
+
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return false
+	}
+
+	return (vm.GreaterThan == nil || number > *vm.GreaterThan) &&
+		(vm.GreaterThanOrEqual == nil || number >= *vm.GreaterThanOrEqual) &&
+		(vm.LessThan == nil || number < *vm.LessThan) &&
+		(vm.LessThanOrEqual == nil || number <= *vm.LessThanOrEqual)
+}
+
+// hasValuePredicates reports whether the matcher inspects the value itself
+func hasValuePredicates(vm *models.ValueMatcher) bool {
+	return vm.Equals != nil || vm.Contains != "" || vm.Regex != "" ||
+		vm.GreaterThan != nil || vm.GreaterThanOrEqual != nil || vm.LessThan != nil || vm.LessThanOrEqual != nil
+}
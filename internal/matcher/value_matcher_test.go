@@ -0,0 +1,69 @@
+package matcher
+
+import (
+	"testing"
+
+	"mock-service/internal/models"
+	"mock-service/internal/testutil"
+)
+
+// TestMatchValues tests the value matcher predicates
+func TestMatchValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher models.ValueMatcher
+		values  []string
+		matches bool
+	}{
+		{"equals match", models.ValueMatcher{Equals: testutil.Ptr("active")}, []string{"active"}, true},
+		{"equals mismatch", models.ValueMatcher{Equals: testutil.Ptr("active")}, []string{"archived"}, false},
+		{"equals missing", models.ValueMatcher{Equals: testutil.Ptr("active")}, nil, false},
+		{"equals empty", models.ValueMatcher{Equals: testutil.Ptr("")}, []string{""}, true},
+		{"equals empty mismatch", models.ValueMatcher{Equals: testutil.Ptr("")}, []string{"active"}, false},
+		{"contains match", models.ValueMatcher{Contains: "json"}, []string{"application/json"}, true},
+		{"contains mismatch", models.ValueMatcher{Contains: "xml"}, []string{"application/json"}, false},
+		{"regex match", models.ValueMatcher{Regex: `^\d+$`}, []string{"42"}, true},
+		{"regex mismatch", models.ValueMatcher{Regex: `^\d+$`}, []string{"abc"}, false},
+		{"present", models.ValueMatcher{Present: true}, []string{""}, true},
+		{"present missing", models.ValueMatcher{Present: true}, nil, false},
+		{"absent", models.ValueMatcher{Absent: true}, nil, true},
+		{"absent but present", models.ValueMatcher{Absent: true}, []string{"x"}, false},
+		{"repeated any value", models.ValueMatcher{Equals: testutil.Ptr("b")}, []string{"a", "b", "c"}, true},
+		{"greater than", models.ValueMatcher{GreaterThan: testutil.Ptr[float64](3)}, []string{"4"}, true},
+		{"greater than equal bound", models.ValueMatcher{GreaterThan: testutil.Ptr[float64](3)}, []string{"3"}, false},
+		{"less than or equal", models.ValueMatcher{LessThanOrEqual: testutil.Ptr[float64](3)}, []string{"3"}, true},
+		{"numeric not a number", models.ValueMatcher{GreaterThan: testutil.Ptr[float64](3)}, []string{"abc"}, false},
+		{"combined predicates", models.ValueMatcher{Equals: testutil.Ptr("10"), Regex: `^1`}, []string{"10"}, true},
+		{"combined on one value", models.ValueMatcher{Equals: testutil.Ptr("20"), Regex: `^1`}, []string{"10", "20"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vm := tt.matcher
+			if err := compileValueMatcher(&vm); err != nil {
+				t.Fatalf("compileValueMatcher failed: %v", err)
+			}
+
+			if got := matchValues(&vm, tt.values); got != tt.matches {
+				t.Errorf("Expected %v, got %v", tt.matches, got)
+			}
+		})
+	}
+}
+
+// TestCompileValueMatcherErrors tests that invalid value matchers are rejected
+func TestCompileValueMatcherErrors(t *testing.T) {
+	tests := []models.ValueMatcher{
+		{Regex: "(unclosed"},
+		{Absent: true, Equals: testutil.Ptr("x")},
+		{Absent: true, Present: true},
+		{Absent: true, Contains: "x"},
+		{Absent: true, LessThan: testutil.Ptr[float64](1)},
+	}
+
+	for i := range tests {
+		if err := compileValueMatcher(&tests[i]); err == nil {
+			t.Errorf("Expected compileValueMatcher to fail for %+v", tests[i])
+		}
+	}
+}
@@ -1,14 +1,577 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"mock-service/internal/jsonpath"
+	"mock-service/internal/xpath"
+)
+
+// Path match modes supported by MockRule.PathMatch
+const (
+	// PathMatchExact matches the path literally; {name} segments act as path parameters
+	PathMatchExact = "exact"
+	// PathMatchPrefix matches any request path starting with the rule path
+	PathMatchPrefix = "prefix"
+	// PathMatchGlob matches the path against a glob pattern (*, ** and ?)
+	PathMatchGlob = "glob"
+	// PathMatchRegex matches the path against a regular expression
+	PathMatchRegex = "regex"
+)
+
+// Faults supported by MockRule.Fault
+const (
+	// FaultConnectionReset aborts the connection with a TCP reset before anything is sent
+	FaultConnectionReset = "connection-reset"
+	// FaultEmptyResponse closes the connection without sending anything
+	FaultEmptyResponse = "empty-response"
+	// FaultGarbage sends random bytes that are not an HTTP response, then closes the connection
+	FaultGarbage = "garbage"
+	// FaultTruncatedJSON sends the response with only the first half of its body, then closes the connection
+	FaultTruncatedJSON = "truncated-json"
+	// FaultContentLengthMismatch declares a Content-Length larger than the body that is sent
+	FaultContentLengthMismatch = "content-length-mismatch"
+)
+
+// Chaos actions supported by ChaosConfig.Actions
+const (
+	// ChaosActionError replaces the response with a 5xx error
+	ChaosActionError = "error"
+	// ChaosActionDelay adds an extra delay before the response
+	ChaosActionDelay = "delay"
+	// ChaosActionFault replaces the response with a network-level fault
+	ChaosActionFault = "fault"
+)
+
+// Variant selection strategies supported by MockRule.VariantSelection
+const (
+	// VariantSelectionWeighted picks variants at random in proportion to their weights
+	VariantSelectionWeighted = "weighted"
+	// VariantSelectionRoundRobin cycles through the variants in order
+	VariantSelectionRoundRobin = "round-robin"
+	// VariantSelectionRandom picks variants at random with equal chances, ignoring weights
+	VariantSelectionRandom = "random"
+)
+
+// ScenarioStateStarted is the state every scenario starts in
+const ScenarioStateStarted = "Started"
+
+// Sequence end policies supported by MockRule.SequenceEnd
+const (
+	// SequenceEndRepeatLast keeps returning the last response of the sequence
+	SequenceEndRepeatLast = "repeat-last"
+	// SequenceEndLoop starts the sequence over
+	SequenceEndLoop = "loop"
+	// SequenceEndFallthrough stops matching the rule, so that requests fall through to the next matching rule
+	SequenceEndFallthrough = "fallthrough"
+)
+
+// Selection strategies supported by Config.Selection
+const (
+	// SelectionFirst picks the first matching rule in configuration order (literal paths before patterns)
+	SelectionFirst = "first"
+	// SelectionPriority picks the matching rule with the highest priority
+	SelectionPriority = "priority"
+	// SelectionMostSpecific picks the matching rule with the most specific path and the most constraints
+	SelectionMostSpecific = "most-specific"
+)
+
 // MockRule represents a single mock rule configuration
 // It defines how the service should respond to requests matching a specific path
 type MockRule struct {
+	// ID identifies the rule in the admin API and runtime state; it defaults to "rule-N" for the Nth rule
+	ID string `json:"id,omitempty"`
 	// Path is the request path to match against (e.g., "/api/users")
+	// Segments written as {name} match any single path segment and capture its value (e.g., "/api/users/{id}")
 	Path string `json:"path"`
+	// PathMatch selects how Path is compared with the request path: exact (default), prefix, glob or regex
+	PathMatch string `json:"pathMatch,omitempty"`
+	// PathPattern is the compiled form of a glob or regex path, populated when the configuration is loaded
+	PathPattern *regexp.Regexp `json:"-"`
+	// PathTemplate holds the segments of an exact path with {name} segments, populated when the configuration is loaded
+	PathTemplate []string `json:"-"`
+	// PathLiteralLength is the length of the literal part of the path, populated when the configuration is loaded
+	PathLiteralLength int `json:"-"`
+	// Method restricts the rule to one or more HTTP methods (e.g., "GET" or ["GET", "HEAD"])
+	// An empty method list matches every HTTP method
+	Method MethodList `json:"method,omitempty"`
+	// Query lists query parameters the request must satisfy, keyed by parameter name
+	Query map[string]ValueMatcher `json:"query,omitempty"`
+	// Headers lists request headers the request must satisfy, keyed by header name (case-insensitive)
+	Headers map[string]ValueMatcher `json:"headers,omitempty"`
+	// Body lists predicates the request body must satisfy
+	Body *BodyMatcher `json:"body,omitempty"`
 	// Response is the JSON response body to return when this rule matches
 	Response map[string]interface{} `json:"response"`
+	// BodyJSON is a response body holding any JSON value, such as a top-level array, number or string
+	BodyJSON json.RawMessage `json:"bodyJson,omitempty"`
+	// BodyText is a response body sent verbatim, such as plain text, XML, HTML or CSV; it may be empty
+	BodyText *string `json:"bodyText,omitempty"`
+	// BodyBase64 is a binary response body encoded as standard base64
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+	// BodyFile is a file whose content is the response body, relative to the directory of the config file
+	BodyFile string `json:"bodyFile,omitempty"`
+	// BodyFilePath is the resolved path of BodyFile, populated when the configuration is loaded
+	BodyFilePath string `json:"-"`
+	// ContentType is the Content-Type of the response body; it defaults to a type suited to the body field used
+	ContentType string `json:"contentType,omitempty"`
+	// Template renders the response body and header values as Go text/template templates with request data
+	Template bool `json:"template,omitempty"`
+	// ResponseTemplate holds the parsed templates and directives of the rule, populated when the configuration is loaded
+	ResponseTemplate *ResponseTemplate `json:"-"`
 	// Code is the HTTP status code to return (defaults to 200 if not specified)
-	Code int `json:"code"`
+	Code int `json:"code,omitempty"`
+	// ResponseHeaders lists headers to send with the response, keyed by header name
+	// A value may be a single string or an array of strings for repeated headers such as Set-Cookie
+	ResponseHeaders map[string]HeaderValues `json:"responseHeaders,omitempty"`
+	// Variants lists candidate responses, one of which is picked for each request
+	Variants []ResponseVariant `json:"variants,omitempty"`
+	// VariantSelection is how a variant is picked: weighted (default), round-robin or random
+	VariantSelection string `json:"variantSelection,omitempty"`
+	// VariantSeed makes weighted and random picks reproducible; a random seed is used when unset
+	VariantSeed *int64 `json:"variantSeed,omitempty"`
+	// VariantRules holds each variant merged with the rule and compiled, populated when the configuration is loaded
+	VariantRules []*MockRule `json:"-"`
+	// VariantPicker picks the index into VariantRules for each request, populated when the configuration is loaded
+	VariantPicker VariantPicker `json:"-"`
+	// Responses lists responses returned in turn, one per matching request
+	Responses []ResponseVariant `json:"responses,omitempty"`
+	// SequenceEnd is what happens once every response was returned: repeat-last (default), loop or fallthrough
+	SequenceEnd string `json:"sequenceEnd,omitempty"`
+	// SequenceRules holds each response of the sequence merged with the rule and compiled,
+	// populated when the configuration is loaded
+	SequenceRules []*MockRule `json:"-"`
+	// Scenario names the state machine the rule belongs to; its state starts as ScenarioStateStarted
+	Scenario string `json:"scenario,omitempty"`
+	// RequiredState restricts the rule to requests made while its scenario is in this state
+	RequiredState string `json:"requiredState,omitempty"`
+	// NewState is the state the scenario moves to when the rule matches
+	NewState string `json:"newState,omitempty"`
+	// Fault replaces the response with a network-level failure, e.g. "connection-reset" (see the Fault constants)
+	Fault string `json:"fault,omitempty"`
+	// Delay postpones the response; it overrides the default delay of the configuration
+	Delay *DelaySpec `json:"delay,omitempty"`
+	// ChaosPercentage overrides the chaos percentage for requests matching this rule; 0 exempts the rule from chaos
+	ChaosPercentage *float64 `json:"chaosPercentage,omitempty"`
+	// Priority ranks the rule when the priority selection strategy is used; higher values win (defaults to 0)
+	Priority int `json:"priority,omitempty"`
+}
+
+// Duration is a time.Duration that is written in JSON as a Go duration string ("250ms", "1.5s")
+// or as a number of milliseconds
+type Duration time.Duration
+
+// UnmarshalJSON accepts both "250ms" and 250 forms
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		parsed, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: %w", text, err)
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+
+	var millis float64
+	if err := json.Unmarshal(data, &millis); err != nil {
+		return fmt.Errorf("duration must be a string such as \"250ms\" or a number of milliseconds: %w", err)
+	}
+	*d = Duration(millis * float64(time.Millisecond))
+	return nil
+}
+
+// MarshalJSON writes the duration as a Go duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DelaySpec describes how long to wait before sending a response
+// Exactly one form is used: a fixed duration, a uniform range (min and max)
+// or a lognormal distribution given by its median (p50) and 99th percentile (p99)
+// In JSON a plain duration is shorthand for {"fixed": ...}
+type DelaySpec struct {
+	// Fixed is a constant delay
+	Fixed *Duration `json:"fixed,omitempty"`
+	// Min is the lower bound of a uniformly distributed delay
+	Min *Duration `json:"min,omitempty"`
+	// Max is the upper bound of a uniformly distributed delay
+	Max *Duration `json:"max,omitempty"`
+	// P50 is the median of a lognormally distributed delay
+	P50 *Duration `json:"p50,omitempty"`
+	// P99 is the 99th percentile of a lognormally distributed delay
+	P99 *Duration `json:"p99,omitempty"`
+}
+
+// UnmarshalJSON accepts either a delay object or a plain duration meaning a fixed delay
+func (ds *DelaySpec) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] != '{' {
+		var fixed Duration
+		if err := json.Unmarshal(data, &fixed); err != nil {
+			return err
+		}
+		*ds = DelaySpec{Fixed: &fixed}
+		return nil
+	}
+
+	// Use an alias type to avoid recursing into this method
+	type delaySpecAlias DelaySpec
+	var alias delaySpecAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return fmt.Errorf("delay must be a duration or an object: %w", err)
+	}
+	*ds = DelaySpec(alias)
+	return nil
+}
+
+// MarshalJSON writes a fixed delay in its shorthand form and any other delay as an object
+func (ds DelaySpec) MarshalJSON() ([]byte, error) {
+	if ds.Fixed != nil && ds.Min == nil && ds.Max == nil && ds.P50 == nil && ds.P99 == nil {
+		return json.Marshal(ds.Fixed)
+	}
+
+	type delaySpecAlias DelaySpec
+	return json.Marshal(delaySpecAlias(ds))
+}
+
+// MethodList is a list of HTTP methods a rule applies to
+// In JSON it may be written either as a single string or as an array of strings
+type MethodList []string
+
+// UnmarshalJSON accepts both "GET" and ["GET", "POST"] forms
+func (ml *MethodList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*ml = nil
+			return nil
+		}
+		*ml = MethodList{strings.ToUpper(single)}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("method must be a string or an array of strings: %w", err)
+	}
+
+	methods := make(MethodList, 0, len(list))
+	for _, method := range list {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	*ml = methods
+	return nil
+}
+
+// HeaderValues is the list of values sent for a single response header
+// In JSON it may be written either as a single string or as an array of strings
+type HeaderValues []string
+
+// UnmarshalJSON accepts both "value" and ["value1", "value2"] forms
+func (hv *HeaderValues) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*hv = HeaderValues{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("header value must be a string or an array of strings: %w", err)
+	}
+	*hv = list
+	return nil
+}
+
+// ValueMatcher is a predicate applied to the values of a request attribute such as a query parameter or header
+// In JSON it may be written as a plain string, which is shorthand for {"equals": "..."}
+// When several predicates are set a single value must satisfy all of them
+type ValueMatcher struct {
+	// Equals requires a value equal to the given string, which may be empty
+	Equals *string `json:"equals,omitempty"`
+	// Contains requires a value containing the given substring
+	Contains string `json:"contains,omitempty"`
+	// Regex requires a value matching the given regular expression
+	Regex string `json:"regex,omitempty"`
+	// Present requires the attribute to be present with any value
+	Present bool `json:"present,omitempty"`
+	// Absent requires the attribute to be missing from the request
+	Absent bool `json:"absent,omitempty"`
+	// GreaterThan requires a numeric value greater than the given number
+	GreaterThan *float64 `json:"gt,omitempty"`
+	// GreaterThanOrEqual requires a numeric value greater than or equal to the given number
+	GreaterThanOrEqual *float64 `json:"gte,omitempty"`
+	// LessThan requires a numeric value less than the given number
+	LessThan *float64 `json:"lt,omitempty"`
+	// LessThanOrEqual requires a numeric value less than or equal to the given number
+	LessThanOrEqual *float64 `json:"lte,omitempty"`
+	// Pattern is the compiled form of Regex, populated when the configuration is loaded
+	Pattern *regexp.Regexp `json:"-"`
+}
+
+// UnmarshalJSON accepts either a matcher object or a plain string meaning equals
+func (vm *ValueMatcher) UnmarshalJSON(data []byte) error {
+	var equals string
+	if err := json.Unmarshal(data, &equals); err == nil {
+		*vm = ValueMatcher{Equals: &equals}
+		return nil
+	}
+
+	// Use an alias type to avoid recursing into this method
+	type valueMatcherAlias ValueMatcher
+	var alias valueMatcherAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return fmt.Errorf("matcher must be a string or an object: %w", err)
+	}
+	*vm = ValueMatcher(alias)
+	return nil
+}
+
+// BodyMatcher describes predicates applied to the request body
+// Every configured predicate must be satisfied for the rule to match
+type BodyMatcher struct {
+	// JSONPath maps JSONPath expressions (e.g. "$.user.type") to the matcher their selected values must satisfy
+	JSONPath map[string]ValueMatcher `json:"jsonPath,omitempty"`
+	// EqualToJSON requires the body to be a JSON document equal to the given value
+	EqualToJSON interface{} `json:"equalToJson,omitempty"`
+	// IgnoreExtraFields lets EqualToJSON accept object members that are not in the expected document
+	IgnoreExtraFields bool `json:"ignoreExtraFields,omitempty"`
+	// IgnoreArrayOrder lets EqualToJSON accept arrays whose elements are in a different order
+	IgnoreArrayOrder bool `json:"ignoreArrayOrder,omitempty"`
+	// Form maps form field names to matchers, for url-encoded and multipart bodies
+	Form map[string]ValueMatcher `json:"form,omitempty"`
+	// Multipart lists parts a multipart/form-data body must contain
+	Multipart []MultipartMatcher `json:"multipart,omitempty"`
+	// XPath maps XPath expressions (e.g. "//Customer/@id") to the matcher their selected values must satisfy
+	XPath map[string]ValueMatcher `json:"xpath,omitempty"`
+	// CompiledJSONPath holds the compiled JSONPath expressions, populated when the configuration is loaded
+	CompiledJSONPath map[string]*jsonpath.Path `json:"-"`
+	// CompiledXPath holds the compiled XPath expressions, populated when the configuration is loaded
+	CompiledXPath map[string]*xpath.Path `json:"-"`
+}
+
+// ResponseTemplate holds the parsed templates and collection directives of a rule
+type ResponseTemplate struct {
+	// Body is the compiled JSON body (response or bodyJson): strings holding template actions are
+	// *template.Template values and $repeat or $paginate directives are compiled by the response package
+	Body interface{}
+	// Text is the parsed bodyText template
+	Text *template.Template
+	// Headers holds the parsed templates of each response header value
+	Headers map[string][]*template.Template
+}
+
+// MultipartMatcher requires a multipart/form-data part with the given name
+type MultipartMatcher struct {
+	// Name is the form field name of the part
+	Name string `json:"name"`
+	// Filename optionally constrains the file name of the part
+	Filename *ValueMatcher `json:"filename,omitempty"`
+	// ContentType optionally constrains the content type of the part
+	ContentType *ValueMatcher `json:"contentType,omitempty"`
+}
+
+// MultipartPart describes a single part of a multipart/form-data request body
+type MultipartPart struct {
+	Name        string
+	Filename    string
+	ContentType string
+}
+
+// Request is the view of an incoming HTTP request used for rule matching
+type Request struct {
+	// Method is the HTTP method (e.g., "GET")
+	Method string
+	// Path is the URL path of the request
+	Path string
+	// Query holds every query parameter value, including repeated keys
+	Query url.Values
+	// Header holds the request headers
+	Header http.Header
+	// Body holds the raw request body
+	Body []byte
+
+	jsonParsed bool
+	jsonBody   interface{}
+	jsonValid  bool
+
+	formParsed bool
+	formValues url.Values
+	formParts  []MultipartPart
+
+	xmlParsed   bool
+	xmlDocument *xpath.Node
+}
+
+// NewRequest builds a matching request from an HTTP request
+// The body is read once and put back on the HTTP request so later readers still see it
+func NewRequest(r *http.Request) (*Request, error) {
+	var body []byte
+	if r.Body != nil {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		body = data
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	return &Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header,
+		Body:   body,
+	}, nil
+}
+
+// JSONBody returns the request body decoded as JSON
+// The body is decoded on first use; false is returned when it is not valid JSON
+func (r *Request) JSONBody() (interface{}, bool) {
+	if !r.jsonParsed {
+		r.jsonParsed = true
+		r.jsonValid = len(r.Body) > 0 && json.Unmarshal(r.Body, &r.jsonBody) == nil
+	}
+	return r.jsonBody, r.jsonValid
+}
+
+// FormValues returns the fields of a url-encoded or multipart/form-data body
+// File parts of a multipart body are not included; use MultipartParts for those
+func (r *Request) FormValues() url.Values {
+	r.parseForm()
+	return r.formValues
+}
+
+// MultipartParts returns every part of a multipart/form-data body
+func (r *Request) MultipartParts() []MultipartPart {
+	r.parseForm()
+	return r.formParts
+}
+
+// XMLDocument returns the request body parsed as XML
+// The body is parsed on first use; false is returned when it is not valid XML
+func (r *Request) XMLDocument() (*xpath.Node, bool) {
+	if !r.xmlParsed {
+		r.xmlParsed = true
+		if document, err := xpath.Parse(r.Body); err == nil {
+			r.xmlDocument = document
+		}
+	}
+	return r.xmlDocument, r.xmlDocument != nil
+}
+
+// parseForm decodes the body according to its form content type, once
+// Bodies that are not forms, or cannot be decoded, yield no fields and no parts
+func (r *Request) parseForm() {
+	if r.formParsed {
+		return
+	}
+	r.formParsed = true
+	r.formValues = url.Values{}
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if values, err := url.ParseQuery(string(r.Body)); err == nil {
+			r.formValues = values
+		}
+	case "multipart/form-data":
+		r.parseMultipart(params["boundary"])
+	}
+}
+
+// parseMultipart reads the parts of a multipart/form-data body
+func (r *Request) parseMultipart(boundary string) {
+	if boundary == "" {
+		return
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(r.Body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return
+		}
+
+		r.formParts = append(r.formParts, MultipartPart{
+			Name:        part.FormName(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+		})
+
+		if part.FileName() == "" {
+			if value, err := io.ReadAll(part); err == nil {
+				r.formValues.Add(part.FormName(), string(value))
+			}
+		}
+	}
+}
+
+// ResponseVariant is one of the candidate responses of a rule, or one step of its response sequence
+// Its headers are added to those of the rule; the status code and body are inherited from the rule when unset
+type ResponseVariant struct {
+	// Weight is the relative chance of the variant under weighted selection (defaults to 1); sequences do not use it
+	Weight int `json:"weight,omitempty"`
+	// Code is the HTTP status code to return
+	Code int `json:"code,omitempty"`
+	// ResponseHeaders lists headers to send with the response; they override rule headers of the same name
+	ResponseHeaders map[string]HeaderValues `json:"responseHeaders,omitempty"`
+	// Response is the JSON response body
+	Response map[string]interface{} `json:"response,omitempty"`
+	// BodyJSON is a response body holding any JSON value
+	BodyJSON json.RawMessage `json:"bodyJson,omitempty"`
+	// BodyText is a response body sent verbatim
+	BodyText *string `json:"bodyText,omitempty"`
+	// BodyBase64 is a binary response body encoded as standard base64
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+	// BodyFile is a file whose content is the response body, relative to the directory of the config file
+	BodyFile string `json:"bodyFile,omitempty"`
+	// ContentType is the Content-Type of the response body
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// VariantPicker chooses which variant of a rule answers a request
+// Implementations must be safe for concurrent use
+type VariantPicker interface {
+	// Pick returns the index of the variant to use
+	Pick() int
+}
+
+// MatchResult describes the outcome of a successful rule match
+type MatchResult struct {
+	// Rule is the matched mock rule
+	Rule *MockRule
+	// PathParams holds the values captured by {name} segments of a path template
+	PathParams map[string]string
+	// Request is the request that matched the rule
+	Request *Request
+	// SequenceIndex is the position in the rule's response sequence claimed by the request
+	SequenceIndex int
+	// ScenarioState is the state of the rule's scenario after the request
+	ScenarioState string
+}
+
+// ScenarioState is the current state of a named scenario
+type ScenarioState struct {
+	// Name is the name of the scenario
+	Name string `json:"name"`
+	// State is the current state of the scenario
+	State string `json:"state"`
 }
 
 // Config represents the complete configuration structure loaded from JSON file
@@ -16,4 +579,72 @@ type MockRule struct {
 type Config struct {
 	// Rules is the list of mock rules to be processed in order
 	Rules []MockRule `json:"rules"`
+	// Selection is the strategy used when several rules match a request: first (default), priority or most-specific
+	Selection string `json:"selection,omitempty"`
+	// DefaultDelay applies to every response whose rule does not set its own delay, including unmatched requests
+	DefaultDelay *DelaySpec `json:"defaultDelay,omitempty"`
+	// Chaos is the profile that randomly disrupts matched requests
+	Chaos *ChaosConfig `json:"chaos,omitempty"`
+	// Resources declares in-memory REST collections that answer requests no rule matches
+	Resources []ResourceConfig `json:"resources,omitempty"`
+}
+
+// ResourceConfig declares an in-memory REST collection served under a base path
+type ResourceConfig struct {
+	// Name identifies the collection in logs
+	Name string `json:"name"`
+	// BasePath is the path of the collection; items live under BasePath/{id}
+	BasePath string `json:"basePath"`
+	// IDField is the item field holding its id (defaults to "id")
+	IDField string `json:"idField,omitempty"`
+	// Seed lists the items the collection starts with
+	Seed []map[string]interface{} `json:"seed,omitempty"`
+}
+
+// ChaosConfig is a chaos profile: a percentage of matched requests gets an error, an extra delay or a fault
+type ChaosConfig struct {
+	// Enabled turns chaos on at startup; it can be toggled at runtime
+	Enabled bool `json:"enabled"`
+	// Percentage is the share of matched requests disrupted, from 0 to 100
+	Percentage float64 `json:"percentage"`
+	// Seed makes the chaos decisions reproducible; a random seed is used when unset
+	Seed *int64 `json:"seed,omitempty"`
+	// Paths restricts chaos to request paths matching one of the globs, optionally with their own percentage
+	Paths []ChaosPath `json:"paths,omitempty"`
+	// Actions lists the disruptions to pick from: error, delay and fault (defaults to all of them)
+	Actions []string `json:"actions,omitempty"`
+	// ErrorCodes lists the status codes used by the error action (defaults to 500, 502, 503 and 504)
+	ErrorCodes []int `json:"errorCodes,omitempty"`
+	// Delay is the extra delay added by the delay action (defaults to 1s to 5s)
+	Delay *DelaySpec `json:"delay,omitempty"`
+	// Faults lists the faults used by the fault action (defaults to all faults)
+	Faults []string `json:"faults,omitempty"`
+}
+
+// ChaosPath applies chaos to request paths matching a glob
+type ChaosPath struct {
+	// Path is a glob in the syntax of pathMatch glob
+	Path string `json:"path"`
+	// Percentage overrides the profile percentage for matching paths
+	Percentage *float64 `json:"percentage,omitempty"`
+}
+
+// ChaosDecision records the chaos roll made for a request
+type ChaosDecision struct {
+	// Path is the request path
+	Path string
+	// Rule is the matched rule
+	Rule *MockRule
+	// Percentage is the chance of disruption that applied to the request
+	Percentage float64
+	// Roll is the random number drawn from [0, 100); the request is disrupted when it is below Percentage
+	Roll float64
+	// Action is the disruption applied, or empty when the request was spared
+	Action string
+	// StatusCode is the status sent by the error action
+	StatusCode int
+	// Delay is the extra delay added by the delay action
+	Delay time.Duration
+	// Fault is the fault injected by the fault action
+	Fault string
 }
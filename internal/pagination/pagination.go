@@ -0,0 +1,69 @@
+// Package pagination selects one page of a collection for list responses
+package pagination
+
+import "strconv"
+
+// Defaults shared by generated collections and REST resources
+const (
+	// DefaultLimit is the number of items per page when the request sets no limit
+	DefaultLimit = 20
+	// MaxLimit is the largest number of items per page a request can ask for
+	MaxLimit = 100
+)
+
+// Page is one page of a collection
+type Page struct {
+	// Number is the requested page, starting at 1
+	Number int
+	// Limit is the number of items per page
+	Limit int
+	// Total is the number of items in the collection
+	Total int
+	// TotalPages is the number of pages holding items
+	TotalPages int
+	// Start and End delimit the items of the page; both are Total for pages past the end
+	Start int
+	End   int
+}
+
+// NewPage selects the page of a collection of total items asked for by the page and limit query values
+// Invalid values fall back to the first page and defaultLimit, and limits above maxLimit are capped
+func NewPage(page, limit string, defaultLimit, maxLimit, total int) Page {
+	p := Page{Number: 1, Limit: defaultLimit, Total: total}
+	if n, ok := positiveInt(page); ok {
+		p.Number = n
+	}
+	if n, ok := positiveInt(limit); ok {
+		p.Limit = min(n, maxLimit)
+	}
+
+	p.TotalPages = total / p.Limit
+	if total%p.Limit != 0 {
+		p.TotalPages++
+	}
+	// Pages past the end are empty; checking before multiplying keeps huge page numbers from overflowing
+	p.Start = total
+	if p.Number <= p.TotalPages {
+		p.Start = (p.Number - 1) * p.Limit
+	}
+	p.End = min(p.Start+p.Limit, total)
+	return p
+}
+
+// Meta returns the pagination metadata sent along with the items of the page
+func (p Page) Meta() map[string]interface{} {
+	return map[string]interface{}{
+		"page":        p.Number,
+		"limit":       p.Limit,
+		"total":       p.Total,
+		"totalPages":  p.TotalPages,
+		"hasNext":     p.Number < p.TotalPages,
+		"hasPrevious": p.Number > 1 && p.TotalPages > 0,
+	}
+}
+
+// positiveInt parses a positive integer
+func positiveInt(text string) (int, bool) {
+	n, err := strconv.Atoi(text)
+	return n, err == nil && n > 0
+}
@@ -0,0 +1,45 @@
+package pagination
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+// TestNewPage tests the selected range and the fallbacks for invalid query values
+func TestNewPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		page, limit string
+		total       int
+		want        Page
+	}{
+		{"defaults", "", "", 45, Page{Number: 1, Limit: 20, Total: 45, TotalPages: 3, Start: 0, End: 20}},
+		{"last page", "3", "", 45, Page{Number: 3, Limit: 20, Total: 45, TotalPages: 3, Start: 40, End: 45}},
+		{"capped limit", "1", "500", 45, Page{Number: 1, Limit: 100, Total: 45, TotalPages: 1, Start: 0, End: 45}},
+		{"invalid values", "-1", "x", 45, Page{Number: 1, Limit: 20, Total: 45, TotalPages: 3, Start: 0, End: 20}},
+		{"past the end", "9", "10", 45, Page{Number: 9, Limit: 10, Total: 45, TotalPages: 5, Start: 45, End: 45}},
+		{"huge page", strconv.Itoa(math.MaxInt), "10", 45,
+			Page{Number: math.MaxInt, Limit: 10, Total: 45, TotalPages: 5, Start: 45, End: 45}},
+		{"empty", "", "", 0, Page{Number: 1, Limit: 20}},
+	}
+
+	for _, tt := range tests {
+		if got := NewPage(tt.page, tt.limit, DefaultLimit, MaxLimit, tt.total); got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+// TestPageMeta tests the navigation flags of the metadata
+func TestPageMeta(t *testing.T) {
+	meta := NewPage("2", "10", DefaultLimit, MaxLimit, 25).Meta()
+	if meta["totalPages"] != 3 || meta["hasNext"] != true || meta["hasPrevious"] != true {
+		t.Errorf("Expected a middle page, got %v", meta)
+	}
+
+	meta = NewPage("2", "", DefaultLimit, MaxLimit, 0).Meta()
+	if meta["hasNext"] != false || meta["hasPrevious"] != false {
+		t.Errorf("Expected no navigation in an empty collection, got %v", meta)
+	}
+}
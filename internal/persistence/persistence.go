@@ -0,0 +1,162 @@
+// Package persistence saves the runtime state of the mock to a file and restores it on restart
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"mock-service/internal/atomicfile"
+	"mock-service/internal/interfaces"
+	"mock-service/internal/models"
+	"mock-service/internal/resource"
+	"mock-service/internal/sequence"
+)
+
+// snapshotVersion is the version of the snapshot format written by this package
+const snapshotVersion = 1
+
+// Snapshot is the content of the state file
+type Snapshot struct {
+	Version   int                        `json:"version"`
+	Scenarios []models.ScenarioState     `json:"scenarios,omitempty"`
+	Sequences []sequence.Count           `json:"sequences,omitempty"`
+	Resources []resource.CollectionState `json:"resources,omitempty"`
+}
+
+// Persister saves the scenario states, sequence counters and resource items to a JSON file
+// The file is replaced atomically, so a crash while saving leaves the previous snapshot intact
+type Persister struct {
+	path      string
+	scenarios interfaces.ScenarioStore
+	sequences *sequence.Counters
+	resources *resource.Store
+
+	// mu serializes flushes; last holds the content of the file as last written
+	mu   sync.Mutex
+	last []byte
+}
+
+// Option configures optional parts of the state saved by a Persister
+type Option func(*Persister)
+
+// WithScenarios saves and restores the state of the scenarios
+func WithScenarios(store interfaces.ScenarioStore) Option {
+	return func(p *Persister) {
+		p.scenarios = store
+	}
+}
+
+// WithSequences saves and restores the response sequence counters
+func WithSequences(counters *sequence.Counters) Option {
+	return func(p *Persister) {
+		p.sequences = counters
+	}
+}
+
+// WithResources saves and restores the items of the in-memory resources
+func WithResources(store *resource.Store) Option {
+	return func(p *Persister) {
+		p.resources = store
+	}
+}
+
+// NewPersister creates a new instance of Persister saving to the file at path
+func NewPersister(path string, opts ...Option) *Persister {
+	p := &Persister{path: path}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Load restores the state saved in the file
+// A missing file is not an error: the state is left as configured
+func (p *Persister) Load() error {
+	data, err := os.ReadFile(p.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read state file %s: %w", p.path, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse state file %s: %w", p.path, err)
+	}
+	if snapshot.Version != snapshotVersion {
+		return fmt.Errorf("unsupported state file version %d in %s", snapshot.Version, p.path)
+	}
+
+	if p.scenarios != nil {
+		p.scenarios.Restore(snapshot.Scenarios)
+	}
+	if p.sequences != nil {
+		p.sequences.Restore(snapshot.Sequences)
+	}
+	if p.resources != nil {
+		if err := p.resources.Restore(snapshot.Resources); err != nil {
+			return fmt.Errorf("failed to restore resources from state file %s: %w", p.path, err)
+		}
+	}
+	return nil
+}
+
+// Flush writes the current state to the file
+// Nothing is written when the state has not changed since the last flush
+func (p *Persister) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	data, err := json.MarshalIndent(p.snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if p.last != nil && bytes.Equal(data, p.last) {
+		return nil
+	}
+
+	if err := atomicfile.Write(p.path, data); err != nil {
+		return fmt.Errorf("failed to write state file %s: %w", p.path, err)
+	}
+	p.last = data
+	return nil
+}
+
+// Run flushes the state every interval until ctx is done
+// Flush errors are passed to onError and do not stop the loop
+func (p *Persister) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.Flush(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// snapshot collects the current state of every configured part
+func (p *Persister) snapshot() *Snapshot {
+	snapshot := &Snapshot{Version: snapshotVersion}
+	if p.scenarios != nil {
+		snapshot.Scenarios = p.scenarios.States()
+	}
+	if p.sequences != nil {
+		snapshot.Sequences = p.sequences.Counts()
+	}
+	if p.resources != nil {
+		snapshot.Resources = p.resources.Snapshot()
+	}
+	return snapshot
+}
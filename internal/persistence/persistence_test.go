@@ -0,0 +1,210 @@
+package persistence
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mock-service/internal/config"
+	"mock-service/internal/models"
+	"mock-service/internal/resource"
+	"mock-service/internal/sequence"
+)
+
+// state is one instance of every part of the state a Persister saves
+type state struct {
+	scenarios *config.ScenarioStoreImpl
+	sequences *sequence.Counters
+	resources *resource.Store
+}
+
+// scenarioRules declares the scenario used by the tests
+var scenarioRules = []models.MockRule{{Path: "/cart", Scenario: "cart", NewState: "item-added"}}
+
+// newState creates the state of a freshly started service
+func newState(t *testing.T) *state {
+	t.Helper()
+	resources, err := resource.NewStore([]models.ResourceConfig{{
+		Name: "users", BasePath: "/users", Seed: []map[string]interface{}{{"id": float64(1), "name": "Alice"}},
+	}})
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	scenarios := config.NewScenarioStore()
+	scenarios.Register(scenarioRules)
+	return &state{scenarios: scenarios, sequences: sequence.NewCounters(), resources: resources}
+}
+
+// persister creates a Persister saving every part of s to path
+func (s *state) persister(path string) *Persister {
+	return NewPersister(path, WithScenarios(s.scenarios), WithSequences(s.sequences), WithResources(s.resources))
+}
+
+// TestFlushAndLoad tests that a saved state is restored by a new service
+func TestFlushAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	before := newState(t)
+	before.scenarios.Advance(&scenarioRules[0])
+	before.sequences.Restore([]sequence.Count{{RuleID: "job", Count: 2}})
+	before.resources.Handle(&models.Request{Method: "POST", Path: "/users", Body: []byte(`{"name":"Bob"}`)})
+	before.resources.Handle(&models.Request{Method: "DELETE", Path: "/users/1"})
+
+	if err := before.persister(path).Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	after := newState(t)
+	if err := after.persister(path).Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := after.scenarios.States(); !reflect.DeepEqual(got, before.scenarios.States()) {
+		t.Errorf("Expected scenario states %v, got %v", before.scenarios.States(), got)
+	}
+	if got := after.sequences.Counts(); !reflect.DeepEqual(got, before.sequences.Counts()) {
+		t.Errorf("Expected sequence counts %v, got %v", before.sequences.Counts(), got)
+	}
+	if got := after.resources.Snapshot(); !reflect.DeepEqual(got, before.resources.Snapshot()) {
+		t.Errorf("Expected resources %v, got %v", before.resources.Snapshot(), got)
+	}
+
+	// Generated ids continue where the saved service stopped
+	_, _, _, body, _ := after.resources.Handle(&models.Request{Method: "POST", Path: "/users", Body: []byte(`{}`)})
+	if !strings.Contains(string(body), `"id":3`) {
+		t.Errorf("Expected the next id to be 3, got %s", body)
+	}
+}
+
+// TestLoadMissingFile tests that a missing state file leaves the configured state
+func TestLoadMissingFile(t *testing.T) {
+	s := newState(t)
+	if err := s.persister(filepath.Join(t.TempDir(), "missing.json")).Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := s.scenarios.States(); got[0].State != models.ScenarioStateStarted {
+		t.Errorf("Expected the initial state, got %v", got)
+	}
+}
+
+// TestLoadInvalidFile tests that unreadable snapshots are reported
+func TestLoadInvalidFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"invalid JSON", `{"version": 1`},
+		{"unknown version", `{"version": 99}`},
+		{"duplicate resource ids", `{"version": 1, "resources": [{"name": "users", "items": [{"id": 1}, {"id": 1}]}]}`},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "state.json")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to write state file: %v", err)
+		}
+		if err := newState(t).persister(path).Load(); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// TestLoadIgnoresUnknownEntries tests that saved entries no longer in the configuration are ignored
+func TestLoadIgnoresUnknownEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	content := `{"version": 1,
+		"scenarios": [{"name": "removed", "state": "done"}],
+		"resources": [{"name": "removed", "items": [{"id": 1}]}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write state file: %v", err)
+	}
+
+	s := newState(t)
+	if err := s.persister(path).Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := s.scenarios.States(); len(got) != 1 || got[0].Name != "cart" {
+		t.Errorf("Expected only the configured scenario, got %v", got)
+	}
+	if got := s.resources.Snapshot(); len(got) != 1 || len(got[0].Items) != 1 {
+		t.Errorf("Expected the configured resource to keep its seed, got %v", got)
+	}
+}
+
+// TestFlushSkipsUnchangedState tests that the file is only rewritten when the state changes
+func TestFlushSkipsUnchangedState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s := newState(t)
+	p := s.persister(path)
+
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove state file: %v", err)
+	}
+
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected an unchanged state not to be written again, got %v", err)
+	}
+
+	s.scenarios.Advance(&scenarioRules[0])
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Expected a changed state to be written, got %v", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected no temporary file to be left behind, got %v", entries)
+	}
+}
+
+// TestFlushError tests that a state file that cannot be written is reported
+func TestFlushError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing-dir", "state.json")
+	if err := newState(t).persister(path).Flush(); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+// TestRun tests that the state is flushed periodically until the context is done
+func TestRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	p := newState(t).persister(path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.Run(ctx, 10*time.Millisecond, func(err error) { t.Errorf("Run() error = %v", err) })
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the state file to be written")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return once the context is done")
+	}
+}
@@ -0,0 +1,258 @@
+package resource
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sync"
+
+	"mock-service/internal/fake"
+	"mock-service/internal/models"
+	"mock-service/internal/pagination"
+)
+
+// Query parameters paginating list responses, matching the $paginate directive
+const (
+	pageParam  = "page"
+	limitParam = "limit"
+)
+
+// errNotFound is returned by update for an item that does not exist
+var errNotFound = fmt.Errorf("not found")
+
+// Collection is one REST collection: items keyed by id, kept in insertion order
+type Collection struct {
+	name     string
+	basePath string
+	idField  string
+
+	mu     sync.RWMutex
+	order  []string
+	items  map[string]map[string]interface{}
+	nextID int64
+	uuids  bool
+	faker  *fake.Faker
+}
+
+// newCollection creates a collection holding the seed items of a resource
+// Generated ids are integers following the largest seed id, or UUIDs when a seed id is not a number
+func newCollection(res *models.ResourceConfig) (*Collection, error) {
+	c := &Collection{
+		name:     res.Name,
+		basePath: res.BasePath,
+		idField:  res.IDField,
+		items:    make(map[string]map[string]interface{}, len(res.Seed)),
+		nextID:   1,
+		faker:    fake.New(),
+	}
+	if c.idField == "" {
+		c.idField = defaultIDField
+	}
+
+	for _, item := range res.Seed {
+		id, ok := item[c.idField]
+		if !ok {
+			continue
+		}
+		number, isNumber := id.(float64)
+		if !isNumber || number != math.Trunc(number) {
+			c.uuids = true
+			continue
+		}
+		c.nextID = max(c.nextID, int64(number)+1)
+	}
+
+	for i, item := range res.Seed {
+		if _, err := c.insert(copyItem(item)); err != nil {
+			return nil, fmt.Errorf("resource %s: seed item #%d: %w", c.name, i+1, err)
+		}
+	}
+	return c, nil
+}
+
+// Name returns the name of the collection
+func (c *Collection) Name() string {
+	return c.name
+}
+
+// list returns one page of the items whose fields equal the filters in query
+func (c *Collection) list(query url.Values) map[string]interface{} {
+	c.mu.RLock()
+	matching := make([]map[string]interface{}, 0, len(c.order))
+	for _, key := range c.order {
+		if item := c.items[key]; matchesFilters(item, query) {
+			matching = append(matching, item)
+		}
+	}
+	c.mu.RUnlock()
+
+	page := pagination.NewPage(query.Get(pageParam), query.Get(limitParam),
+		pagination.DefaultLimit, pagination.MaxLimit, len(matching))
+	return map[string]interface{}{
+		"items":      matching[page.Start:page.End],
+		"pagination": page.Meta(),
+	}
+}
+
+// get returns the item with the given id
+func (c *Collection) get(id string) (map[string]interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, ok := c.items[id]
+	return item, ok
+}
+
+// insert adds an item, generating its id when it has none
+// It fails when an item with the same id exists
+func (c *Collection) insert(item map[string]interface{}) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, ok := item[c.idField]
+	if !ok || id == nil {
+		id = c.generateID()
+		item[c.idField] = id
+	}
+
+	key := idKey(id)
+	if _, exists := c.items[key]; exists {
+		return nil, fmt.Errorf("%s %s already exists", c.name, key)
+	}
+	if number, isNumber := id.(float64); isNumber && number == math.Trunc(number) {
+		c.nextID = max(c.nextID, int64(number)+1)
+	}
+
+	c.items[key] = item
+	c.order = append(c.order, key)
+	return item, nil
+}
+
+// update replaces the item with the result of change
+// The id cannot change: it is set on the result when missing and a different id is refused
+func (c *Collection) update(
+	id string, change func(current map[string]interface{}) (map[string]interface{}, error),
+) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current, ok := c.items[id]
+	if !ok {
+		return nil, errNotFound
+	}
+
+	updated, err := change(current)
+	if err != nil {
+		return nil, err
+	}
+	if newID, set := updated[c.idField]; set && newID != nil && idKey(newID) != id {
+		return nil, fmt.Errorf("%s %s: %s cannot be changed", c.name, id, c.idField)
+	}
+	updated[c.idField] = current[c.idField]
+
+	c.items[id] = updated
+	return updated, nil
+}
+
+// remove deletes the item with the given id and reports whether it existed
+func (c *Collection) remove(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	for i, key := range c.order {
+		if key == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// generateID returns the next id; the caller must hold the lock
+func (c *Collection) generateID() interface{} {
+	if c.uuids {
+		return c.faker.UUID()
+	}
+	id := c.nextID
+	c.nextID++
+	return float64(id)
+}
+
+// CollectionState is the content of one collection, as saved in a snapshot
+type CollectionState struct {
+	Name   string                   `json:"name"`
+	Items  []map[string]interface{} `json:"items"`
+	NextID int64                    `json:"nextId"`
+}
+
+// snapshot returns the items of the collection in insertion order
+// Stored items are never modified in place, so they are shared rather than copied
+func (c *Collection) snapshot() CollectionState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	items := make([]map[string]interface{}, 0, len(c.order))
+	for _, key := range c.order {
+		items = append(items, c.items[key])
+	}
+	return CollectionState{Name: c.name, Items: items, NextID: c.nextID}
+}
+
+// restore replaces the items of the collection with saved ones
+// Generated ids continue after both the saved next id and the largest saved id
+func (c *Collection) restore(state *CollectionState) error {
+	restored, err := newCollection(&models.ResourceConfig{
+		Name: c.name, BasePath: c.basePath, IDField: c.idField, Seed: state.Items,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = restored.items
+	c.order = restored.order
+	c.nextID = max(restored.nextID, state.NextID)
+	c.uuids = c.uuids || restored.uuids
+	return nil
+}
+
+// matchesFilters reports whether every filter in query, except pagination, equals the item field
+// A filter with several values matches any of them
+func matchesFilters(item map[string]interface{}, query url.Values) bool {
+	for field, values := range query {
+		if field == pageParam || field == limitParam {
+			continue
+		}
+		value, ok := item[field]
+		if !ok {
+			return false
+		}
+
+		text := textValue(value)
+		matched := false
+		for _, want := range values {
+			if text == want {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// copyItem returns a shallow copy of an item so that the configuration is not modified
+func copyItem(item map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(item))
+	for key, value := range item {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,301 @@
+// Package resource emulates REST collections held in memory
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mock-service/internal/models"
+)
+
+// defaultIDField is the item field holding the id when a resource does not set one
+const defaultIDField = "id"
+
+// contentTypeJSON is the content type of every resource response
+const contentTypeJSON = "application/json; charset=utf-8"
+
+// Validate checks resource declarations: names, base paths and seed ids must be unique
+func Validate(resources []models.ResourceConfig) error {
+	names := make(map[string]bool, len(resources))
+	basePaths := make(map[string]bool, len(resources))
+
+	for i := range resources {
+		res := &resources[i]
+		switch {
+		case res.Name == "":
+			return fmt.Errorf("resource #%d: name is required", i+1)
+		case names[res.Name]:
+			return fmt.Errorf("resource %s: duplicate name", res.Name)
+		case !strings.HasPrefix(res.BasePath, "/") || strings.HasSuffix(res.BasePath, "/") && res.BasePath != "/":
+			return fmt.Errorf("resource %s: basePath must start with / and not end with /", res.Name)
+		case basePaths[res.BasePath]:
+			return fmt.Errorf("resource %s: duplicate basePath %s", res.Name, res.BasePath)
+		}
+		names[res.Name] = true
+		basePaths[res.BasePath] = true
+
+		if _, err := newCollection(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Store serves the REST collections declared in the configuration
+// It is safe for concurrent use
+type Store struct {
+	collections []*Collection
+}
+
+// NewStore creates a Store holding the seed items of every resource
+func NewStore(resources []models.ResourceConfig) (*Store, error) {
+	if err := Validate(resources); err != nil {
+		return nil, err
+	}
+
+	store := &Store{}
+	for i := range resources {
+		collection, err := newCollection(&resources[i])
+		if err != nil {
+			return nil, err
+		}
+		store.collections = append(store.collections, collection)
+	}
+	return store, nil
+}
+
+// Collections returns the collections of the store in configuration order
+func (s *Store) Collections() []*Collection {
+	return s.collections
+}
+
+// Snapshot returns the items of every collection, e.g. to save them
+func (s *Store) Snapshot() []CollectionState {
+	states := make([]CollectionState, 0, len(s.collections))
+	for _, collection := range s.collections {
+		states = append(states, collection.snapshot())
+	}
+	return states
+}
+
+// Restore replaces the items of the collections with saved ones
+// Collections not in states keep their items and states of unknown collections are ignored
+func (s *Store) Restore(states []CollectionState) error {
+	for i := range states {
+		for _, collection := range s.collections {
+			if collection.name != states[i].Name {
+				continue
+			}
+			if err := collection.restore(&states[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Handle answers a request addressed to one of the collections
+// It returns false when the request path does not belong to any collection
+func (s *Store) Handle(req *models.Request) (name string, statusCode int, headers http.Header, body []byte, handled bool) {
+	for _, collection := range s.collections {
+		id, ok := collection.route(req.Path)
+		if !ok {
+			continue
+		}
+		statusCode, headers, body = collection.serve(req, id)
+		return collection.name, statusCode, headers, body, true
+	}
+	return "", 0, nil, nil, false
+}
+
+// route reports whether a path addresses the collection, and returns the item id it addresses if any
+func (c *Collection) route(path string) (string, bool) {
+	if path == c.basePath {
+		return "", true
+	}
+	prefix := strings.TrimSuffix(c.basePath, "/") + "/"
+	id, ok := strings.CutPrefix(path, prefix)
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
+
+// serve dispatches a request to the operation matching its method
+func (c *Collection) serve(req *models.Request, id string) (int, http.Header, []byte) {
+	if id == "" {
+		switch req.Method {
+		case http.MethodGet, http.MethodHead:
+			return jsonResponse(http.StatusOK, c.list(req.Query))
+		case http.MethodPost:
+			return c.create(req)
+		default:
+			return methodNotAllowed("GET, HEAD, POST")
+		}
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		item, ok := c.get(id)
+		if !ok {
+			return c.notFound(id)
+		}
+		return jsonResponse(http.StatusOK, item)
+	case http.MethodPut:
+		return c.replace(req, id)
+	case http.MethodPatch:
+		return c.patch(req, id)
+	case http.MethodDelete:
+		if !c.remove(id) {
+			return c.notFound(id)
+		}
+		return http.StatusNoContent, http.Header{}, nil
+	default:
+		return methodNotAllowed("GET, HEAD, PUT, PATCH, DELETE")
+	}
+}
+
+// create adds the item in the request body and answers with it and its location
+func (c *Collection) create(req *models.Request) (int, http.Header, []byte) {
+	item, err := bodyObject(req)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err.Error())
+	}
+
+	created, err := c.insert(item)
+	if err != nil {
+		return errorResponse(http.StatusConflict, err.Error())
+	}
+
+	statusCode, headers, body := jsonResponse(http.StatusCreated, created)
+	headers.Set("Location", c.basePath+"/"+idKey(created[c.idField]))
+	return statusCode, headers, body
+}
+
+// replace swaps the item with the request body
+func (c *Collection) replace(req *models.Request, id string) (int, http.Header, []byte) {
+	item, err := bodyObject(req)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err.Error())
+	}
+
+	updated, err := c.update(id, func(map[string]interface{}) (map[string]interface{}, error) { return item, nil })
+	return c.updateResponse(id, updated, err)
+}
+
+// patch applies the request body to the item as a JSON merge patch (RFC 7396)
+func (c *Collection) patch(req *models.Request, id string) (int, http.Header, []byte) {
+	changes, err := bodyObject(req)
+	if err != nil {
+		return errorResponse(http.StatusBadRequest, err.Error())
+	}
+
+	updated, err := c.update(id, func(current map[string]interface{}) (map[string]interface{}, error) {
+		merged, ok := mergePatch(current, changes).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("patch must be a JSON object")
+		}
+		return merged, nil
+	})
+	return c.updateResponse(id, updated, err)
+}
+
+// updateResponse answers an update with the updated item, 404 for a missing item or 409 for an id change
+func (c *Collection) updateResponse(id string, updated map[string]interface{}, err error) (int, http.Header, []byte) {
+	switch {
+	case err == errNotFound:
+		return c.notFound(id)
+	case err != nil:
+		return errorResponse(http.StatusConflict, err.Error())
+	default:
+		return jsonResponse(http.StatusOK, updated)
+	}
+}
+
+// notFound answers a request for a missing item
+func (c *Collection) notFound(id string) (int, http.Header, []byte) {
+	return errorResponse(http.StatusNotFound, fmt.Sprintf("%s %s not found", c.name, id))
+}
+
+// bodyObject returns the request body decoded as a JSON object
+func bodyObject(req *models.Request) (map[string]interface{}, error) {
+	body, ok := req.JSONBody()
+	if !ok {
+		return nil, fmt.Errorf("request body must be a JSON object")
+	}
+	object, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("request body must be a JSON object")
+	}
+	return object, nil
+}
+
+// mergePatch applies a JSON merge patch to a document: null removes a field and objects are merged recursively
+func mergePatch(document, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	documentObject, ok := document.(map[string]interface{})
+	if !ok {
+		documentObject = map[string]interface{}{}
+	}
+	result := make(map[string]interface{}, len(documentObject)+len(patchObject))
+	for key, value := range documentObject {
+		result[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergePatch(result[key], value)
+	}
+	return result
+}
+
+// idKey returns the string form of an id, as it appears in item paths
+func idKey(id interface{}) string {
+	return textValue(id)
+}
+
+// textValue returns the string form of a JSON value, as it appears in paths and query filters
+func textValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// jsonResponse encodes a value as a JSON response
+func jsonResponse(statusCode int, value interface{}) (int, http.Header, []byte) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return errorResponse(http.StatusInternalServerError, fmt.Sprintf("failed to encode response: %v", err))
+	}
+	return statusCode, http.Header{"Content-Type": {contentTypeJSON}}, body
+}
+
+// errorResponse builds a JSON error response
+func errorResponse(statusCode int, message string) (int, http.Header, []byte) {
+	body, _ := json.Marshal(map[string]string{"error": message})
+	return statusCode, http.Header{"Content-Type": {contentTypeJSON}}, body
+}
+
+// methodNotAllowed answers a method the collection does not support
+func methodNotAllowed(allow string) (int, http.Header, []byte) {
+	statusCode, headers, body := errorResponse(http.StatusMethodNotAllowed, "method not allowed")
+	headers.Set("Allow", allow)
+	return statusCode, headers, body
+}
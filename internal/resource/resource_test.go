@@ -0,0 +1,339 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// usersResource returns a resource seeded with two users
+func usersResource() models.ResourceConfig {
+	return models.ResourceConfig{
+		Name:     "users",
+		BasePath: "/api/users",
+		Seed: []map[string]interface{}{
+			{"id": float64(1), "name": "Alice", "role": "admin"},
+			{"id": float64(2), "name": "Bob", "role": "user"},
+		},
+	}
+}
+
+// newStore creates a store for the given resources, failing the test on error
+func newStore(t *testing.T, resources ...models.ResourceConfig) *Store {
+	t.Helper()
+	store, err := NewStore(resources)
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+	return store
+}
+
+// call sends a request to the store and decodes the JSON response body, if any
+func call(t *testing.T, store *Store, method, target, body string) (int, http.Header, map[string]interface{}) {
+	t.Helper()
+	u, err := url.Parse(target)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+
+	_, statusCode, headers, raw, handled := store.Handle(&models.Request{
+		Method: method, Path: u.Path, Query: u.Query(), Body: []byte(body),
+	})
+	if !handled {
+		t.Fatalf("%s %s: expected the request to be handled", method, target)
+	}
+
+	var decoded map[string]interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %s: %v", method, target, raw, err)
+		}
+	}
+	return statusCode, headers, decoded
+}
+
+// TestValidate tests the validation of resource declarations
+func TestValidate(t *testing.T) {
+	valid := usersResource()
+	tests := []struct {
+		name      string
+		resources []models.ResourceConfig
+		wantErr   bool
+	}{
+		{"valid", []models.ResourceConfig{valid}, false},
+		{"no resources", nil, false},
+		{"missing name", []models.ResourceConfig{{BasePath: "/api/users"}}, true},
+		{"duplicate name", []models.ResourceConfig{valid, {Name: "users", BasePath: "/api/people"}}, true},
+		{"relative base path", []models.ResourceConfig{{Name: "users", BasePath: "api/users"}}, true},
+		{"trailing slash", []models.ResourceConfig{{Name: "users", BasePath: "/api/users/"}}, true},
+		{"duplicate base path", []models.ResourceConfig{valid, {Name: "people", BasePath: "/api/users"}}, true},
+		{"duplicate seed id", []models.ResourceConfig{{
+			Name: "users", BasePath: "/api/users",
+			Seed: []map[string]interface{}{{"id": "a"}, {"id": "a"}},
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.resources)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestHandleUnknownPath tests that paths outside every collection are left to the caller
+func TestHandleUnknownPath(t *testing.T) {
+	store := newStore(t, usersResource())
+
+	for _, path := range []string{"/api/orders", "/api/users/1/orders", "/api/usersx", "/api/users/"} {
+		if _, _, _, _, handled := store.Handle(&models.Request{Method: "GET", Path: path}); handled {
+			t.Errorf("Expected %s not to be handled", path)
+		}
+	}
+}
+
+// TestCRUD tests creating, reading, replacing, patching and deleting an item
+func TestCRUD(t *testing.T) {
+	store := newStore(t, usersResource())
+
+	code, headers, created := call(t, store, "POST", "/api/users", `{"name":"Carol","role":"user"}`)
+	if code != http.StatusCreated || created["id"] != float64(3) {
+		t.Fatalf("POST: expected 201 with id 3, got %d %v", code, created)
+	}
+	if location := headers.Get("Location"); location != "/api/users/3" {
+		t.Errorf("POST: expected Location /api/users/3, got %q", location)
+	}
+
+	code, _, item := call(t, store, "GET", "/api/users/3", "")
+	if code != http.StatusOK || item["name"] != "Carol" {
+		t.Errorf("GET: expected Carol, got %d %v", code, item)
+	}
+
+	code, _, item = call(t, store, "PUT", "/api/users/3", `{"name":"Caroline"}`)
+	if code != http.StatusOK || item["name"] != "Caroline" || item["id"] != float64(3) || item["role"] != nil {
+		t.Errorf("PUT: expected the item to be replaced and keep its id, got %d %v", code, item)
+	}
+
+	code, _, item = call(t, store, "PATCH", "/api/users/3", `{"role":"admin","address":{"city":"Paris"}}`)
+	if code != http.StatusOK || item["name"] != "Caroline" || item["role"] != "admin" {
+		t.Errorf("PATCH: expected the fields to be merged, got %d %v", code, item)
+	}
+
+	code, _, item = call(t, store, "PATCH", "/api/users/3", `{"role":null,"address":{"zip":"75001"}}`)
+	address, ok := item["address"].(map[string]interface{})
+	if code != http.StatusOK || !ok || address["city"] != "Paris" || address["zip"] != "75001" {
+		t.Errorf("PATCH: expected nested objects to be merged, got %d %v", code, item)
+	}
+	if _, exists := item["role"]; exists {
+		t.Errorf("PATCH: expected null to remove the field, got %v", item)
+	}
+
+	code, _, body := call(t, store, "DELETE", "/api/users/3", "")
+	if code != http.StatusNoContent || body != nil {
+		t.Errorf("DELETE: expected 204 without body, got %d %v", code, body)
+	}
+
+	if code, _, _ = call(t, store, "GET", "/api/users/3", ""); code != http.StatusNotFound {
+		t.Errorf("GET after DELETE: expected 404, got %d", code)
+	}
+}
+
+// TestErrors tests the status codes of failing operations
+func TestErrors(t *testing.T) {
+	store := newStore(t, usersResource())
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		want   int
+	}{
+		{"GET", "/api/users/9", "", http.StatusNotFound},
+		{"PUT", "/api/users/9", `{"name":"x"}`, http.StatusNotFound},
+		{"PATCH", "/api/users/9", `{"name":"x"}`, http.StatusNotFound},
+		{"DELETE", "/api/users/9", "", http.StatusNotFound},
+		{"POST", "/api/users", `{"id":1,"name":"x"}`, http.StatusConflict},
+		{"PUT", "/api/users/1", `{"id":2}`, http.StatusConflict},
+		{"PATCH", "/api/users/1", `{"id":"other"}`, http.StatusConflict},
+		{"POST", "/api/users", `[1,2]`, http.StatusBadRequest},
+		{"POST", "/api/users", `not json`, http.StatusBadRequest},
+		{"PUT", "/api/users/1", ``, http.StatusBadRequest},
+		{"DELETE", "/api/users", "", http.StatusMethodNotAllowed},
+		{"POST", "/api/users/1", `{}`, http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		code, headers, body := call(t, store, tt.method, tt.target, tt.body)
+		if code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d %v", tt.method, tt.target, tt.want, code, body)
+		}
+		if body["error"] == nil {
+			t.Errorf("%s %s: expected an error message, got %v", tt.method, tt.target, body)
+		}
+		if code == http.StatusMethodNotAllowed && headers.Get("Allow") == "" {
+			t.Errorf("%s %s: expected an Allow header", tt.method, tt.target)
+		}
+	}
+
+	// Failed operations leave the collection unchanged
+	if _, _, item := call(t, store, "GET", "/api/users/1", ""); item["name"] != "Alice" {
+		t.Errorf("Expected user 1 to be unchanged, got %v", item)
+	}
+}
+
+// TestListFiltersAndPagination tests query filters and the pagination envelope of the list
+func TestListFiltersAndPagination(t *testing.T) {
+	res := models.ResourceConfig{Name: "items", BasePath: "/items"}
+	for i := 1; i <= 25; i++ {
+		res.Seed = append(res.Seed, map[string]interface{}{
+			"id": float64(i), "even": i%2 == 0, "group": fmt.Sprintf("g%d", i%3),
+		})
+	}
+	store := newStore(t, res)
+
+	tests := []struct {
+		target    string
+		wantCount int
+		wantFirst float64
+		wantTotal float64
+		wantNext  bool
+	}{
+		{"/items", 20, 1, 25, true},
+		{"/items?page=2", 5, 21, 25, false},
+		{"/items?limit=10&page=3", 5, 21, 25, false},
+		{"/items?limit=500", 25, 1, 25, false},
+		{"/items?even=true", 12, 2, 12, false},
+		{"/items?group=g0&group=g1", 17, 1, 17, false},
+		{"/items?even=false&group=g0", 4, 3, 4, false},
+		{"/items?missing=1", 0, 0, 0, false},
+		{"/items?page=9", 0, 0, 25, false},
+		{"/items?page=9223372036854775807", 0, 0, 25, false},
+	}
+
+	for _, tt := range tests {
+		code, _, body := call(t, store, "GET", tt.target, "")
+		items, ok := body["items"].([]interface{})
+		if code != http.StatusOK || !ok || len(items) != tt.wantCount {
+			t.Errorf("%s: expected %d items, got %d %v", tt.target, tt.wantCount, code, body["items"])
+			continue
+		}
+		if tt.wantCount > 0 {
+			first, isObject := items[0].(map[string]interface{})
+			if !isObject || first["id"] != tt.wantFirst {
+				t.Errorf("%s: expected first id %v, got %v", tt.target, tt.wantFirst, items[0])
+			}
+		}
+		pagination, ok := body["pagination"].(map[string]interface{})
+		if !ok || pagination["total"] != tt.wantTotal || pagination["hasNext"] != tt.wantNext {
+			t.Errorf("%s: expected total %v and hasNext %v, got %v", tt.target, tt.wantTotal, tt.wantNext, body["pagination"])
+		}
+	}
+}
+
+// TestGeneratedIDs tests integer ids after the largest seed id and UUIDs for non-numeric seed ids
+func TestGeneratedIDs(t *testing.T) {
+	store := newStore(t,
+		models.ResourceConfig{Name: "numbers", BasePath: "/numbers", Seed: []map[string]interface{}{{"id": float64(41)}}},
+		models.ResourceConfig{
+			Name: "codes", BasePath: "/codes", IDField: "code", Seed: []map[string]interface{}{{"code": "abc"}},
+		},
+	)
+
+	if _, _, item := call(t, store, "POST", "/numbers", `{}`); item["id"] != float64(42) {
+		t.Errorf("Expected id 42, got %v", item["id"])
+	}
+	if _, _, item := call(t, store, "POST", "/numbers", `{"id":100}`); item["id"] != float64(100) {
+		t.Errorf("Expected the given id 100, got %v", item["id"])
+	}
+	if _, _, item := call(t, store, "POST", "/numbers", `{}`); item["id"] != float64(101) {
+		t.Errorf("Expected id 101 after an explicit id, got %v", item["id"])
+	}
+
+	code, headers, item := call(t, store, "POST", "/codes", `{"name":"x"}`)
+	uuid, isString := item["code"].(string)
+	if code != http.StatusCreated || !isString || len(uuid) != 36 {
+		t.Fatalf("Expected a UUID in the code field, got %d %v", code, item)
+	}
+	if headers.Get("Location") != "/codes/"+uuid {
+		t.Errorf("Expected Location /codes/%s, got %q", uuid, headers.Get("Location"))
+	}
+	if code, _, _ = call(t, store, "GET", "/codes/"+uuid, ""); code != http.StatusOK {
+		t.Errorf("Expected the created item to be found, got %d", code)
+	}
+}
+
+// TestSeedNotModified tests that changes do not leak into the configuration
+func TestSeedNotModified(t *testing.T) {
+	res := usersResource()
+	store := newStore(t, res)
+
+	call(t, store, "PATCH", "/api/users/1", `{"name":"Alicia"}`)
+	call(t, store, "DELETE", "/api/users/2", "")
+
+	if res.Seed[0]["name"] != "Alice" || len(res.Seed) != 2 {
+		t.Errorf("Expected the seed to be unchanged, got %v", res.Seed)
+	}
+	if code, _, _ := call(t, newStore(t, res), "GET", "/api/users/2", ""); code != http.StatusOK {
+		t.Errorf("Expected a new store to start from the seed again, got %d", code)
+	}
+}
+
+// TestConcurrentCreates tests that concurrent creates get distinct ids
+func TestConcurrentCreates(t *testing.T) {
+	store := newStore(t, models.ResourceConfig{Name: "things", BasePath: "/things"})
+
+	const creates = 50
+	var wg sync.WaitGroup
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Handle(&models.Request{Method: "POST", Path: "/things", Body: []byte(`{}`)})
+		}()
+	}
+	wg.Wait()
+
+	_, _, body := call(t, store, "GET", "/things?limit=100", "")
+	items, ok := body["items"].([]interface{})
+	if !ok || len(items) != creates {
+		t.Fatalf("Expected %d items, got %v", creates, body["items"])
+	}
+	seen := make(map[interface{}]bool, creates)
+	for _, raw := range items {
+		item, isObject := raw.(map[string]interface{})
+		if !isObject || seen[item["id"]] {
+			t.Fatalf("Expected distinct ids, got %v", items)
+		}
+		seen[item["id"]] = true
+	}
+}
+
+// TestSnapshotAndRestore tests that a snapshot restores the items and the next generated id
+func TestSnapshotAndRestore(t *testing.T) {
+	store := newStore(t, usersResource())
+	call(t, store, "POST", "/api/users", `{"name":"Carol"}`)
+	call(t, store, "DELETE", "/api/users/3", "")
+	call(t, store, "PATCH", "/api/users/1", `{"name":"Alicia"}`)
+
+	restored := newStore(t, usersResource())
+	if err := restored.Restore(store.Snapshot()); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if _, _, item := call(t, restored, "GET", "/api/users/1", ""); item["name"] != "Alicia" {
+		t.Errorf("Expected the restored item, got %v", item)
+	}
+	if _, _, item := call(t, restored, "POST", "/api/users", `{}`); item["id"] != float64(4) {
+		t.Errorf("Expected ids to continue after the deleted item, got %v", item["id"])
+	}
+
+	err := restored.Restore([]CollectionState{{Name: "users", Items: []map[string]interface{}{{"id": "a"}, {"id": "a"}}}})
+	if err == nil {
+		t.Error("Expected an error for duplicate ids")
+	}
+}
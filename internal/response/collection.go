@@ -0,0 +1,289 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"mock-service/internal/pagination"
+)
+
+// Collection directives recognized in JSON bodies
+const (
+	// repeatDirective expands to an array of generated items: {"$repeat": {"count": 3, "item": {...}}}
+	repeatDirective = "$repeat"
+	// paginateDirective expands to one page of generated items with pagination metadata
+	paginateDirective = "$paginate"
+)
+
+// maxRepeatCount is the largest number of items a $repeat directive can generate
+const maxRepeatCount = 10000
+
+// repeatNode is a compiled $repeat directive
+type repeatNode struct {
+	count countValue
+	item  interface{}
+}
+
+// paginateNode is a compiled $paginate directive
+type paginateNode struct {
+	total        countValue
+	item         interface{}
+	pageParam    string
+	limitParam   string
+	defaultLimit int
+	maxLimit     int
+	itemsField   string
+	metaField    string
+}
+
+// repeatSpec is the configuration of a $repeat directive
+type repeatSpec struct {
+	Count interface{} `json:"count"`
+	Item  interface{} `json:"item"`
+}
+
+// paginateSpec is the configuration of a $paginate directive
+type paginateSpec struct {
+	Total        interface{} `json:"total"`
+	Item         interface{} `json:"item"`
+	PageParam    string      `json:"pageParam"`
+	LimitParam   string      `json:"limitParam"`
+	DefaultLimit int         `json:"defaultLimit"`
+	MaxLimit     int         `json:"maxLimit"`
+	ItemsField   string      `json:"itemsField"`
+	MetaField    string      `json:"metaField"`
+}
+
+// countValue is a non-negative number that is either fixed or rendered from a template
+type countValue struct {
+	fixed int
+	tmpl  *template.Template
+}
+
+// directive returns the specification of a directive when object consists of that directive alone
+func directive(object map[string]interface{}, name string) (interface{}, bool) {
+	if len(object) != 1 {
+		return nil, false
+	}
+	spec, ok := object[name]
+	return spec, ok
+}
+
+// hasDirectives reports whether a JSON document holds a $repeat or $paginate directive
+func hasDirectives(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := directive(v, repeatDirective); ok {
+			return true
+		}
+		if _, ok := directive(v, paginateDirective); ok {
+			return true
+		}
+		for _, item := range v {
+			if hasDirectives(item) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if hasDirectives(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeSpec decodes a directive specification into a struct, rejecting unknown fields
+func decodeSpec(spec, target interface{}) error {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+// compileRepeat compiles a $repeat directive
+func (bc bodyCompiler) compileRepeat(spec interface{}, location string) (*repeatNode, error) {
+	var rs repeatSpec
+	if err := decodeSpec(spec, &rs); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", location, err)
+	}
+	if rs.Item == nil {
+		return nil, fmt.Errorf("invalid %s: item is required", location)
+	}
+
+	count, err := bc.compileCount(rs.Count, location+".count")
+	if err != nil {
+		return nil, err
+	}
+	item, err := bc.compile(rs.Item, location+".item")
+	if err != nil {
+		return nil, err
+	}
+	return &repeatNode{count: count, item: item}, nil
+}
+
+// compilePaginate compiles a $paginate directive, applying defaults to its settings
+func (bc bodyCompiler) compilePaginate(spec interface{}, location string) (*paginateNode, error) {
+	var ps paginateSpec
+	if err := decodeSpec(spec, &ps); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", location, err)
+	}
+	if ps.Item == nil {
+		return nil, fmt.Errorf("invalid %s: item is required", location)
+	}
+	if ps.DefaultLimit < 0 || ps.MaxLimit < 0 {
+		return nil, fmt.Errorf("invalid %s: limits must not be negative", location)
+	}
+
+	total, err := bc.compileCount(ps.Total, location+".total")
+	if err != nil {
+		return nil, err
+	}
+	item, err := bc.compile(ps.Item, location+".item")
+	if err != nil {
+		return nil, err
+	}
+
+	node := &paginateNode{
+		total:        total,
+		item:         item,
+		pageParam:    valueOr(ps.PageParam, "page"),
+		limitParam:   valueOr(ps.LimitParam, "limit"),
+		defaultLimit: ps.DefaultLimit,
+		maxLimit:     ps.MaxLimit,
+		itemsField:   valueOr(ps.ItemsField, "items"),
+		metaField:    valueOr(ps.MetaField, "pagination"),
+	}
+	if node.maxLimit == 0 {
+		node.maxLimit = pagination.MaxLimit
+	}
+	if node.defaultLimit == 0 {
+		node.defaultLimit = min(pagination.DefaultLimit, node.maxLimit)
+	}
+	if node.defaultLimit > node.maxLimit {
+		return nil, fmt.Errorf("invalid %s: defaultLimit %d exceeds maxLimit %d", location, node.defaultLimit, node.maxLimit)
+	}
+	return node, nil
+}
+
+// compileCount compiles a count given as a number, a numeric string or, for templated rules, a template
+func (bc bodyCompiler) compileCount(value interface{}, location string) (countValue, error) {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 || v != float64(int(v)) {
+			return countValue{}, fmt.Errorf("invalid %s: %v is not a non-negative integer", location, v)
+		}
+		return countValue{fixed: int(v)}, nil
+	case string:
+		if isTemplate(v) {
+			if !bc.templates {
+				return countValue{}, fmt.Errorf("invalid %s: templates require \"template\": true", location)
+			}
+			tmpl, err := parseTemplate(location, v)
+			if err != nil {
+				return countValue{}, fmt.Errorf("invalid template at %s: %w", location, err)
+			}
+			return countValue{tmpl: tmpl}, nil
+		}
+		n, err := parseCount(v)
+		if err != nil {
+			return countValue{}, fmt.Errorf("invalid %s: %w", location, err)
+		}
+		return countValue{fixed: n}, nil
+	case nil:
+		return countValue{}, fmt.Errorf("invalid %s: value is required", location)
+	default:
+		return countValue{}, fmt.Errorf("invalid %s: expected a number or a string, got %T", location, v)
+	}
+}
+
+// resolve returns the value of the count for a request
+func (cv countValue) resolve(data map[string]interface{}) (int, error) {
+	if cv.tmpl == nil {
+		return cv.fixed, nil
+	}
+	text, err := executeTemplate(cv.tmpl, data)
+	if err != nil {
+		return 0, err
+	}
+	return parseCount(text)
+}
+
+// parseCount parses a non-negative integer
+func parseCount(text string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a non-negative integer", text)
+	}
+	return n, nil
+}
+
+// render expands the directive into an array of items
+func (rn *repeatNode) render(data map[string]interface{}) (interface{}, error) {
+	count, err := rn.count.resolve(data)
+	if err != nil {
+		return nil, fmt.Errorf("$repeat count: %w", err)
+	}
+	if count > maxRepeatCount {
+		return nil, fmt.Errorf("$repeat count %d exceeds the maximum of %d", count, maxRepeatCount)
+	}
+	return renderItems(rn.item, data, 0, count)
+}
+
+// render expands the directive into the items of the requested page and the pagination metadata
+// The page and limit come from the query parameters; invalid values fall back to the first page
+// and the default limit, and limits above the maximum are capped
+func (pn *paginateNode) render(data map[string]interface{}) (interface{}, error) {
+	total, err := pn.total.resolve(data)
+	if err != nil {
+		return nil, fmt.Errorf("$paginate total: %w", err)
+	}
+
+	query, _ := data["query"].(map[string]string)
+	page := pagination.NewPage(query[pn.pageParam], query[pn.limitParam], pn.defaultLimit, pn.maxLimit, total)
+
+	items, err := renderItems(pn.item, data, page.Start, page.End-page.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		pn.itemsField: items,
+		pn.metaField:  page.Meta(),
+	}, nil
+}
+
+// renderItems renders count copies of an item; each sees its position in the whole collection as .index
+func renderItems(item interface{}, data map[string]interface{}, offset, count int) ([]interface{}, error) {
+	items := make([]interface{}, count)
+	for i := range items {
+		itemData := make(map[string]interface{}, len(data)+1)
+		for key, value := range data {
+			itemData[key] = value
+		}
+		itemData["index"] = offset + i
+
+		rendered, err := renderJSONTemplate(item, itemData)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = rendered
+	}
+	return items, nil
+}
+
+// valueOr returns value, or fallback when value is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,201 @@
+package response
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// buildJSON compiles a rule with the given body and decodes the body built for a request with the given query
+func buildJSON(t *testing.T, template bool, body string, query url.Values) (int, map[string]interface{}) {
+	t.Helper()
+
+	rule := &models.MockRule{Template: template, BodyJSON: []byte(body)}
+	if err := CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+
+	match := &models.MatchResult{Rule: rule, Request: &models.Request{Method: "GET", Path: "/items", Query: query}}
+	statusCode, _, encoded := NewResponseBuilder().BuildResponse(match)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Expected a JSON object, got %s: %v", encoded, err)
+	}
+	return statusCode, decoded
+}
+
+// TestRepeatFixedCount tests that $repeat works without templates
+func TestRepeatFixedCount(t *testing.T) {
+	_, body := buildJSON(t, false, `{"items": {"$repeat": {"count": 3, "item": {"type": "static"}}}}`, nil)
+
+	items, ok := body["items"].([]interface{})
+	if !ok || len(items) != 3 {
+		t.Fatalf("Expected 3 items, got %v", body["items"])
+	}
+	if item, _ := items[2].(map[string]interface{}); item["type"] != "static" {
+		t.Errorf("Expected static items, got %v", items[2])
+	}
+}
+
+// TestRepeatTemplateCount tests a count taken from the query and the item index
+func TestRepeatTemplateCount(t *testing.T) {
+	body := `{"items": {"$repeat": {
+		"count": "{{.query.limit | default \"2\"}}",
+		"item": {
+			"id": "{{.index}}",
+			"name": "{{(faker .index).FirstName}}",
+			"tags": {"$repeat": {"count": 2, "item": "t{{.index}}"}}
+		}
+	}}}`
+
+	_, decoded := buildJSON(t, true, body, url.Values{"limit": {"4"}})
+	items, _ := decoded["items"].([]interface{})
+	if len(items) != 4 {
+		t.Fatalf("Expected 4 items, got %v", decoded["items"])
+	}
+	last, _ := items[3].(map[string]interface{})
+	if last["id"] != "3" {
+		t.Errorf("Expected the last item to have index 3, got %v", last["id"])
+	}
+	if tags, _ := last["tags"].([]interface{}); len(tags) != 2 || tags[1] != "t1" {
+		t.Errorf("Expected nested items to use their own index, got %v", last["tags"])
+	}
+
+	_, decoded = buildJSON(t, true, body, nil)
+	if items, _ := decoded["items"].([]interface{}); len(items) != 2 {
+		t.Errorf("Expected the default count of 2, got %v", decoded["items"])
+	}
+}
+
+// TestRepeatErrors tests invalid counts at load and request time
+func TestRepeatErrors(t *testing.T) {
+	invalid := []struct {
+		name     string
+		template bool
+		body     string
+	}{
+		{"template without template mode", false, `{"$repeat": {"count": "{{.query.n}}", "item": 1}}`},
+		{"negative count", false, `{"$repeat": {"count": -1, "item": 1}}`},
+		{"fractional count", false, `{"$repeat": {"count": 1.5, "item": 1}}`},
+		{"missing count", false, `{"$repeat": {"item": 1}}`},
+		{"missing item", false, `{"$repeat": {"count": 1}}`},
+		{"unknown field", false, `{"$repeat": {"count": 1, "item": 1, "size": 2}}`},
+	}
+
+	for _, tt := range invalid {
+		rule := &models.MockRule{Template: tt.template, BodyJSON: []byte(tt.body)}
+		if err := CompileResponse(rule, ""); err == nil {
+			t.Errorf("%s: expected CompileResponse to fail", tt.name)
+		}
+	}
+
+	statusCode, _ := buildJSON(t, true, `{"items": {"$repeat": {"count": "{{.query.n}}", "item": 1}}}`, url.Values{"n": {"abc"}})
+	if statusCode != 500 {
+		t.Errorf("Expected status code 500 for a non-numeric count, got %d", statusCode)
+	}
+
+	body := `{"items": {"$repeat": {"count": "{{.query.n}}", "item": 1}}}`
+	statusCode, _ = buildJSON(t, true, body, url.Values{"n": {"100000"}})
+	if statusCode != 500 {
+		t.Errorf("Expected status code 500 for a count above the maximum, got %d", statusCode)
+	}
+}
+
+// TestPaginate tests page selection and pagination metadata
+func TestPaginate(t *testing.T) {
+	body := `{"$paginate": {"total": 45, "item": {"id": "{{.index}}"}}}`
+
+	tests := []struct {
+		name      string
+		query     url.Values
+		items     int
+		firstID   string
+		page      float64
+		limit     float64
+		hasNext   bool
+		hasPrev   bool
+		pageCount float64
+	}{
+		{"defaults", nil, 20, "0", 1, 20, true, false, 3},
+		{"second page", url.Values{"page": {"2"}, "limit": {"20"}}, 20, "20", 2, 20, true, true, 3},
+		{"last page", url.Values{"page": {"3"}}, 5, "40", 3, 20, false, true, 3},
+		{"past the end", url.Values{"page": {"9"}}, 0, "", 9, 20, false, true, 3},
+		{"huge page", url.Values{"page": {"9223372036854775807"}}, 0, "", 9223372036854775807, 20, false, true, 3},
+		{"capped limit", url.Values{"limit": {"500"}}, 45, "0", 1, 100, false, false, 1},
+		{"invalid values", url.Values{"page": {"-1"}, "limit": {"x"}}, 20, "0", 1, 20, true, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, decoded := buildJSON(t, true, body, tt.query)
+
+			items, _ := decoded["items"].([]interface{})
+			if len(items) != tt.items {
+				t.Fatalf("Expected %d items, got %d", tt.items, len(items))
+			}
+			if tt.items > 0 {
+				if first, _ := items[0].(map[string]interface{}); first["id"] != tt.firstID {
+					t.Errorf("Expected first id %s, got %v", tt.firstID, first["id"])
+				}
+			}
+
+			meta, _ := decoded["pagination"].(map[string]interface{})
+			if meta["page"] != tt.page || meta["limit"] != tt.limit || meta["total"] != float64(45) {
+				t.Errorf("Unexpected page metadata %v", meta)
+			}
+			if meta["totalPages"] != tt.pageCount || meta["hasNext"] != tt.hasNext || meta["hasPrevious"] != tt.hasPrev {
+				t.Errorf("Unexpected navigation metadata %v", meta)
+			}
+		})
+	}
+}
+
+// TestPaginateCustomFields tests custom parameter names, limits and output fields
+func TestPaginateCustomFields(t *testing.T) {
+	body := `{"$paginate": {
+		"total": "{{.query.total}}", "item": "{{.index}}",
+		"pageParam": "p", "limitParam": "size", "defaultLimit": 5, "maxLimit": 10,
+		"itemsField": "data", "metaField": "meta"
+	}}`
+
+	_, decoded := buildJSON(t, true, body, url.Values{"p": {"2"}, "size": {"50"}, "total": {"12"}})
+
+	items, _ := decoded["data"].([]interface{})
+	if len(items) != 2 || items[0] != "10" {
+		t.Errorf("Expected items 10 and 11, got %v", decoded["data"])
+	}
+	if meta, _ := decoded["meta"].(map[string]interface{}); meta["limit"] != float64(10) {
+		t.Errorf("Expected the limit to be capped at 10, got %v", decoded["meta"])
+	}
+
+	rule := &models.MockRule{BodyJSON: []byte(`{"$paginate": {"total": 1, "item": 1, "defaultLimit": 50, "maxLimit": 10}}`)}
+	if err := CompileResponse(rule, ""); err == nil {
+		t.Error("Expected CompileResponse to reject a default limit above the maximum")
+	}
+}
+
+// TestHasDirectives tests detection of directives anywhere in a document
+func TestHasDirectives(t *testing.T) {
+	tests := []struct {
+		document string
+		expected bool
+	}{
+		{`{"a": [{"b": {"$repeat": {"count": 1, "item": 1}}}]}`, true},
+		{`{"$paginate": {"total": 1, "item": 1}}`, true},
+		{`{"$repeat": 1, "other": 2}`, false},
+		{`{"a": "$repeat"}`, false},
+	}
+
+	for _, tt := range tests {
+		var document interface{}
+		if err := json.Unmarshal([]byte(tt.document), &document); err != nil {
+			t.Fatalf("Invalid test document: %v", err)
+		}
+		if got := hasDirectives(document); got != tt.expected {
+			t.Errorf("hasDirectives(%s) = %v, expected %v", tt.document, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package response
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mock-service/internal/models"
+)
+
+// CompileResponse validates the response part of a rule when the configuration is loaded
+// A bodyJson value is compacted so it is sent without the indentation of the config file,
+// a bodyFile is resolved against baseDir, the directory of the config file, and must stay inside it,
+// the templates and collection directives of the body are parsed and each variant and step of
+// the response sequence is compiled as a rule of its own
+func CompileResponse(rule *models.MockRule, baseDir string) error {
+	for name := range rule.ResponseHeaders {
+		if !validHeaderName(name) {
+			return fmt.Errorf("invalid response header name %q", name)
+		}
+	}
+
+	bodies := 0
+	for _, set := range []bool{
+		rule.Response != nil, rule.BodyJSON != nil, rule.BodyText != nil, rule.BodyBase64 != "", rule.BodyFile != "",
+	} {
+		if set {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return fmt.Errorf("only one of response, bodyJson, bodyText, bodyBase64 and bodyFile may be set")
+	}
+
+	if rule.BodyJSON != nil {
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, rule.BodyJSON); err != nil {
+			return fmt.Errorf("invalid bodyJson: %w", err)
+		}
+		rule.BodyJSON = compacted.Bytes()
+	}
+
+	if rule.BodyBase64 != "" {
+		if _, err := base64.StdEncoding.DecodeString(rule.BodyBase64); err != nil {
+			return fmt.Errorf("invalid bodyBase64: %w", err)
+		}
+	}
+
+	if rule.BodyFile != "" {
+		// Rules can come from the admin API, so they must not read files outside the config directory
+		if !filepath.IsLocal(rule.BodyFile) {
+			return fmt.Errorf("invalid bodyFile: %q is not inside the directory of the config file", rule.BodyFile)
+		}
+		path := filepath.Join(baseDir, rule.BodyFile)
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("invalid bodyFile: %w", err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("invalid bodyFile: %s is a directory", path)
+		}
+		rule.BodyFilePath = path
+	}
+
+	if err := ValidateFault(rule.Fault); err != nil {
+		return err
+	}
+
+	if err := compileTemplates(rule); err != nil {
+		return err
+	}
+
+	if err := compileVariants(rule, baseDir); err != nil {
+		return err
+	}
+
+	return compileSequence(rule, baseDir)
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name (an RFC 7230 token)
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > 0x7e || r <= ' ' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateFault checks that a fault name is one of the supported faults; an empty name means no fault
+func ValidateFault(fault string) error {
+	switch fault {
+	case "", models.FaultConnectionReset, models.FaultEmptyResponse, models.FaultGarbage,
+		models.FaultTruncatedJSON, models.FaultContentLengthMismatch:
+		return nil
+	default:
+		return fmt.Errorf("invalid fault %q", fault)
+	}
+}
@@ -0,0 +1,123 @@
+package response
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// TestCompileResponseHeaderNames tests validation of response header names
+func TestCompileResponseHeaderNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"Location", false},
+		{"X-RateLimit-Remaining", false},
+		{"", true},
+		{"Bad Header", true},
+		{"Bad:Header", true},
+		{"Café", true},
+	}
+
+	for _, tt := range tests {
+		rule := &models.MockRule{ResponseHeaders: map[string]models.HeaderValues{tt.name: {"value"}}}
+		err := CompileResponse(rule, "")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CompileResponse(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestCompileResponseBodyFields tests validation of the response body fields
+func TestCompileResponseBodyFields(t *testing.T) {
+	text := "hello"
+
+	tests := []struct {
+		name    string
+		rule    models.MockRule
+		wantErr bool
+	}{
+		{"map only", models.MockRule{Response: map[string]interface{}{}}, false},
+		{"text only", models.MockRule{BodyText: &text}, false},
+		{"valid base64", models.MockRule{BodyBase64: "aGVsbG8="}, false},
+		{"invalid base64", models.MockRule{BodyBase64: "***"}, true},
+		{"two bodies", models.MockRule{Response: map[string]interface{}{}, BodyText: &text}, true},
+		{"invalid json", models.MockRule{BodyJSON: []byte(`{"a":`)}, true},
+	}
+
+	for _, tt := range tests {
+		err := CompileResponse(&tt.rule, "")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+// TestCompileResponseFault tests validation of the fault name
+func TestCompileResponseFault(t *testing.T) {
+	tests := []struct {
+		fault   string
+		wantErr bool
+	}{
+		{"", false},
+		{models.FaultConnectionReset, false},
+		{models.FaultContentLengthMismatch, false},
+		{"timeout", true},
+		{"Garbage", true},
+	}
+
+	for _, tt := range tests {
+		err := CompileResponse(&models.MockRule{Fault: tt.fault}, "")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("CompileResponse(fault %q) error = %v, wantErr %v", tt.fault, err, tt.wantErr)
+		}
+	}
+}
+
+// TestCompileResponseCompactsBodyJSON tests that bodyJson is stored without indentation
+func TestCompileResponseCompactsBodyJSON(t *testing.T) {
+	rule := &models.MockRule{BodyJSON: []byte("[\n  1,\n  {\"a\": true}\n]")}
+
+	if err := CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+
+	if string(rule.BodyJSON) != `[1,{"a":true}]` {
+		t.Errorf("Expected compacted bodyJson, got %s", rule.BodyJSON)
+	}
+}
+
+// TestCompileResponseBodyFile tests that bodyFile is resolved against the base directory, must stay inside it and must exist
+func TestCompileResponseBodyFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "fixtures"), 0755); err != nil {
+		t.Fatalf("Failed to create fixtures directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fixtures", "users.json"), []byte(`[]`), 0644); err != nil {
+		t.Fatalf("Failed to create body file: %v", err)
+	}
+
+	rule := &models.MockRule{BodyFile: "fixtures/users.json"}
+	if err := CompileResponse(rule, dir); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+	if expected := filepath.Join(dir, "fixtures", "users.json"); rule.BodyFilePath != expected {
+		t.Errorf("Expected resolved path %s, got %s", expected, rule.BodyFilePath)
+	}
+
+	outside := filepath.Join(filepath.Dir(dir), "outside.json")
+	if err := os.WriteFile(outside, []byte(`[]`), 0644); err != nil {
+		t.Fatalf("Failed to create body file: %v", err)
+	}
+	defer os.Remove(outside)
+
+	invalid := []string{"fixtures/missing.json", "fixtures", outside, "../outside.json", "fixtures/../../outside.json"}
+	for _, bodyFile := range invalid {
+		if err := CompileResponse(&models.MockRule{BodyFile: bodyFile}, dir); err == nil {
+			t.Errorf("Expected an error for bodyFile %q", bodyFile)
+		}
+	}
+}
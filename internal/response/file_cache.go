@@ -0,0 +1,52 @@
+package response
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileCache keeps the content of body files in memory
+// A cached file is re-read when its modification time or size changes
+type fileCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedFile
+}
+
+// cachedFile is the content of a file together with the metadata it was read with
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	data    []byte
+}
+
+// newFileCache creates an empty file cache
+func newFileCache() *fileCache {
+	return &fileCache{entries: make(map[string]cachedFile)}
+}
+
+// read returns the content of the file at path, from the cache when the file is unchanged
+func (fc *fileCache) read(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body file %s: %w", path, err)
+	}
+
+	fc.mu.Lock()
+	entry, ok := fc.entries[path]
+	fc.mu.Unlock()
+	if ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read body file %s: %w", path, err)
+	}
+
+	fc.mu.Lock()
+	fc.entries[path] = cachedFile{modTime: info.ModTime(), size: info.Size(), data: data}
+	fc.mu.Unlock()
+	return data, nil
+}
@@ -0,0 +1,57 @@
+package response
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFileCacheReloadsChangedFiles tests that cached content is reused until the file changes
+func TestFileCacheReloadsChangedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte(`{"v":1}`), 0644); err != nil {
+		t.Fatalf("Failed to create body file: %v", err)
+	}
+
+	fc := newFileCache()
+
+	data, err := fc.read(path)
+	if err != nil || string(data) != `{"v":1}` {
+		t.Fatalf("Expected first content, got %q (%v)", data, err)
+	}
+
+	// Same size and modification time: the cached content is returned
+	modTime := time.Now().Add(-time.Hour)
+	if err := os.WriteFile(path, []byte(`{"v":2}`), 0644); err != nil {
+		t.Fatalf("Failed to rewrite body file: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+	fc.entries[path] = cachedFile{modTime: modTime, size: 7, data: []byte(`{"v":1}`)}
+
+	data, _ = fc.read(path)
+	if string(data) != `{"v":1}` {
+		t.Errorf("Expected cached content, got %q", data)
+	}
+
+	// A new modification time invalidates the cache
+	if err := os.Chtimes(path, time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	data, _ = fc.read(path)
+	if string(data) != `{"v":2}` {
+		t.Errorf("Expected reloaded content, got %q", data)
+	}
+}
+
+// TestFileCacheMissingFile tests that a deleted file yields an error
+func TestFileCacheMissingFile(t *testing.T) {
+	fc := newFileCache()
+
+	if _, err := fc.read(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
@@ -1,34 +1,180 @@
 package response
 
-import "mock-service/internal/models"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+
+	"mock-service/internal/models"
+)
+
+// Default content types of the response body fields
+const (
+	contentTypeJSON   = "application/json; charset=utf-8"
+	contentTypeText   = "text/plain; charset=utf-8"
+	contentTypeBinary = "application/octet-stream"
+)
 
 // ResponseBuilderImpl implements the ResponseBuilder interface
 // It handles building HTTP responses based on mock rules
-type ResponseBuilderImpl struct{}
+type ResponseBuilderImpl struct {
+	files *fileCache
+}
 
 // NewResponseBuilder creates a new instance of ResponseBuilder
 func NewResponseBuilder() *ResponseBuilderImpl {
-	return &ResponseBuilderImpl{}
+	return &ResponseBuilderImpl{files: newFileCache()}
 }
 
-// BuildResponse builds a response based on the provided mock rule
-// Returns the status code and response body from the rule
-func (rb *ResponseBuilderImpl) BuildResponse(rule *models.MockRule) (statusCode int, body interface{}) {
+// BuildResponse builds a response based on the matched mock rule
+// Returns the status code, response headers (including Content-Type) and encoded body from the rule,
+// from the step of its response sequence claimed by the request, or from one of its variants
+func (rb *ResponseBuilderImpl) BuildResponse(match *models.MatchResult) (statusCode int, headers http.Header, body []byte) {
+	rule := match.Rule
+	switch {
+	case len(rule.SequenceRules) > 0:
+		rule = rule.SequenceRules[max(0, min(match.SequenceIndex, len(rule.SequenceRules)-1))]
+	case rule.VariantPicker != nil:
+		rule = rule.VariantRules[rule.VariantPicker.Pick()]
+	}
+
 	// Use the status code from the rule, default to 200 if not specified or invalid
 	statusCode = rule.Code
 	if statusCode == 0 {
 		statusCode = 200
 	}
 
-	// Return the response body from the rule
-	body = rule.Response
-	return statusCode, body
+	// Templated rules are rendered with the request data
+	var data map[string]interface{}
+	if rule.ResponseTemplate != nil {
+		data = templateData(match)
+	}
+
+	// Collect the response headers from the rule, keeping repeated values
+	headers, err := buildHeaders(rule, data)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	// Encode the response body from the rule
+	body, contentType, err := rb.encodeBody(rule, data)
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	// An explicit contentType wins over a Content-Type header, which wins over the body default
+	switch {
+	case rule.ContentType != "":
+		headers.Set("Content-Type", rule.ContentType)
+	case headers.Get("Content-Type") == "":
+		headers.Set("Content-Type", contentType)
+	}
+
+	return statusCode, headers, body
 }
 
 // BuildDefaultResponse builds a default response when no rule matches
 // Returns 200 status with an empty JSON object as per requirements
-func (rb *ResponseBuilderImpl) BuildDefaultResponse() (statusCode int, body interface{}) {
+func (rb *ResponseBuilderImpl) BuildDefaultResponse() (statusCode int, headers http.Header, body []byte) {
 	statusCode = 200
-	body = map[string]interface{}{}
-	return statusCode, body
+	headers = http.Header{"Content-Type": {contentTypeJSON}}
+	body = []byte("{}")
+	return statusCode, headers, body
+}
+
+// buildHeaders returns the response headers of a rule, rendering header templates with data
+func buildHeaders(rule *models.MockRule, data map[string]interface{}) (http.Header, error) {
+	headers := make(http.Header, len(rule.ResponseHeaders)+1)
+	if rule.ResponseTemplate != nil && rule.ResponseTemplate.Headers != nil {
+		for name, templates := range rule.ResponseTemplate.Headers {
+			for _, tmpl := range templates {
+				value, err := executeTemplate(tmpl, data)
+				if err != nil {
+					return nil, fmt.Errorf("response header %s: %w", name, err)
+				}
+				headers.Add(name, value)
+			}
+		}
+		return headers, nil
+	}
+
+	for name, values := range rule.ResponseHeaders {
+		for _, value := range values {
+			headers.Add(name, value)
+		}
+	}
+	return headers, nil
+}
+
+// encodeBody returns the body bytes of a rule and the default content type of its body field
+// Templated bodies are rendered with data first
+func (rb *ResponseBuilderImpl) encodeBody(
+	rule *models.MockRule, data map[string]interface{},
+) (body []byte, contentType string, err error) {
+	if tmpl := rule.ResponseTemplate; tmpl != nil {
+		switch {
+		case tmpl.Text != nil:
+			var text string
+			if text, err = executeTemplate(tmpl.Text, data); err != nil {
+				return nil, "", err
+			}
+			return []byte(text), contentTypeText, nil
+		case tmpl.Body != nil:
+			var document interface{}
+			if document, err = renderJSONTemplate(tmpl.Body, data); err != nil {
+				return nil, "", err
+			}
+			body, err = json.Marshal(document)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to encode response: %w", err)
+			}
+			return body, contentTypeJSON, nil
+		}
+	}
+
+	switch {
+	case rule.BodyFile != "":
+		path := rule.BodyFilePath
+		if path == "" {
+			path = rule.BodyFile
+		}
+		body, err = rb.files.read(path)
+		if err != nil {
+			return nil, "", err
+		}
+		return body, fileContentType(path, body), nil
+	case rule.BodyJSON != nil:
+		return rule.BodyJSON, contentTypeJSON, nil
+	case rule.BodyText != nil:
+		return []byte(*rule.BodyText), contentTypeText, nil
+	case rule.BodyBase64 != "":
+		body, err = base64.StdEncoding.DecodeString(rule.BodyBase64)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid bodyBase64: %w", err)
+		}
+		return body, contentTypeBinary, nil
+	default:
+		body, err = json.Marshal(rule.Response)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode response: %w", err)
+		}
+		return body, contentTypeJSON, nil
+	}
+}
+
+// fileContentType infers the content type of a body file from its extension, then from its content
+func fileContentType(path string, data []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(data)
+}
+
+// errorResponse builds a 500 response describing why the rule response could not be built
+func errorResponse(err error) (statusCode int, headers http.Header, body []byte) {
+	body, _ = json.Marshal(map[string]string{"error": err.Error()})
+	return http.StatusInternalServerError, http.Header{"Content-Type": {contentTypeJSON}}, body
 }
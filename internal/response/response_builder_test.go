@@ -1,12 +1,41 @@
 package response
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"mock-service/internal/models"
 )
 
+// assertJSONBody checks that an encoded body holds the same JSON document as expected
+func assertJSONBody(t *testing.T, body []byte, expected interface{}) {
+	t.Helper()
+
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		t.Fatalf("Expected a JSON body, got %q: %v", body, err)
+	}
+
+	encoded, err := json.Marshal(expected)
+	if err != nil {
+		t.Fatalf("Failed to encode expected body: %v", err)
+	}
+	var want interface{}
+	if err := json.Unmarshal(encoded, &want); err != nil {
+		t.Fatalf("Failed to decode expected body: %v", err)
+	}
+
+	if !reflect.DeepEqual(actual, want) {
+		t.Errorf("Expected body %s, got %s", encoded, body)
+	}
+}
+
 // TestNewResponseBuilder tests the creation of a new ResponseBuilder instance
 func TestNewResponseBuilder(t *testing.T) {
 	rb := NewResponseBuilder()
@@ -28,7 +57,7 @@ func TestBuildResponseWithValidRule(t *testing.T) {
 		Code: 200,
 	}
 
-	statusCode, body := rb.BuildResponse(rule)
+	statusCode, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
 
 	// Check status code
 	if statusCode != 200 {
@@ -41,9 +70,7 @@ func TestBuildResponseWithValidRule(t *testing.T) {
 		"count": 2,
 	}
 
-	if !reflect.DeepEqual(body, expectedBody) {
-		t.Errorf("Expected body %v, got %v", expectedBody, body)
-	}
+	assertJSONBody(t, body, expectedBody)
 }
 
 // TestBuildResponseWithCustomStatusCode tests building response with custom status code
@@ -58,7 +85,7 @@ func TestBuildResponseWithCustomStatusCode(t *testing.T) {
 		Code: 500,
 	}
 
-	statusCode, body := rb.BuildResponse(rule)
+	statusCode, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
 
 	// Check status code
 	if statusCode != 500 {
@@ -70,9 +97,7 @@ func TestBuildResponseWithCustomStatusCode(t *testing.T) {
 		"error": "Internal Server Error",
 	}
 
-	if !reflect.DeepEqual(body, expectedBody) {
-		t.Errorf("Expected body %v, got %v", expectedBody, body)
-	}
+	assertJSONBody(t, body, expectedBody)
 }
 
 // TestBuildResponseWithZeroStatusCode tests default status code when rule has zero code
@@ -87,7 +112,7 @@ func TestBuildResponseWithZeroStatusCode(t *testing.T) {
 		Code: 0, // Zero status code should default to 200
 	}
 
-	statusCode, body := rb.BuildResponse(rule)
+	statusCode, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
 
 	// Should default to 200
 	if statusCode != 200 {
@@ -99,9 +124,7 @@ func TestBuildResponseWithZeroStatusCode(t *testing.T) {
 		"message": "test response",
 	}
 
-	if !reflect.DeepEqual(body, expectedBody) {
-		t.Errorf("Expected body %v, got %v", expectedBody, body)
-	}
+	assertJSONBody(t, body, expectedBody)
 }
 
 // TestBuildResponseWithEmptyResponse tests building response with empty response body
@@ -114,7 +137,7 @@ func TestBuildResponseWithEmptyResponse(t *testing.T) {
 		Code:     204,
 	}
 
-	statusCode, body := rb.BuildResponse(rule)
+	statusCode, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
 
 	// Check status code
 	if statusCode != 204 {
@@ -124,16 +147,14 @@ func TestBuildResponseWithEmptyResponse(t *testing.T) {
 	// Check response body is empty map
 	expectedBody := map[string]interface{}{}
 
-	if !reflect.DeepEqual(body, expectedBody) {
-		t.Errorf("Expected empty body %v, got %v", expectedBody, body)
-	}
+	assertJSONBody(t, body, expectedBody)
 }
 
 // TestBuildDefaultResponse tests building default response when no rule matches
 func TestBuildDefaultResponse(t *testing.T) {
 	rb := NewResponseBuilder()
 
-	statusCode, body := rb.BuildDefaultResponse()
+	statusCode, _, body := rb.BuildDefaultResponse()
 
 	// Check status code should be 200 as per requirements
 	if statusCode != 200 {
@@ -141,9 +162,9 @@ func TestBuildDefaultResponse(t *testing.T) {
 	}
 
 	// Check response body structure - should be empty JSON object
-	bodyMap, ok := body.(map[string]interface{})
-	if !ok {
-		t.Fatal("Expected response body to be a map")
+	var bodyMap map[string]interface{}
+	if err := json.Unmarshal(body, &bodyMap); err != nil {
+		t.Fatalf("Expected response body to be a JSON object: %v", err)
 	}
 
 	// Should be empty JSON object
@@ -175,7 +196,7 @@ func TestBuildResponseWithComplexData(t *testing.T) {
 		Code: 201,
 	}
 
-	statusCode, body := rb.BuildResponse(rule)
+	statusCode, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
 
 	// Check status code
 	if statusCode != 201 {
@@ -183,7 +204,194 @@ func TestBuildResponseWithComplexData(t *testing.T) {
 	}
 
 	// Check that complex data structure is preserved
-	if !reflect.DeepEqual(body, rule.Response) {
-		t.Errorf("Expected body to match rule response exactly")
+	assertJSONBody(t, body, rule.Response)
+}
+
+// TestBuildResponseWithHeaders tests that rule headers are returned with repeated values preserved
+func TestBuildResponseWithHeaders(t *testing.T) {
+	rb := NewResponseBuilder()
+
+	rule := &models.MockRule{
+		Path: "/api/login",
+		Code: 302,
+		ResponseHeaders: map[string]models.HeaderValues{
+			"location":   {"/home"},
+			"Set-Cookie": {"session=abc", "theme=dark"},
+		},
+	}
+
+	statusCode, headers, _ := rb.BuildResponse(&models.MatchResult{Rule: rule})
+
+	if statusCode != 302 {
+		t.Errorf("Expected status code 302, got %d", statusCode)
+	}
+
+	if got := headers.Get("Location"); got != "/home" {
+		t.Errorf("Expected Location '/home', got '%s'", got)
+	}
+
+	expectedCookies := []string{"session=abc", "theme=dark"}
+	if got := headers.Values("Set-Cookie"); !reflect.DeepEqual(got, expectedCookies) {
+		t.Errorf("Expected Set-Cookie %v, got %v", expectedCookies, got)
+	}
+}
+
+// TestBuildResponseBodyFields tests the alternative body fields and their default content types
+func TestBuildResponseBodyFields(t *testing.T) {
+	rb := NewResponseBuilder()
+	text := "id,name\n1,alice\n"
+	empty := ""
+
+	tests := []struct {
+		name        string
+		rule        models.MockRule
+		body        string
+		contentType string
+	}{
+		{"json array", models.MockRule{BodyJSON: []byte(`[1,2,3]`)}, `[1,2,3]`, "application/json; charset=utf-8"},
+		{"json number", models.MockRule{BodyJSON: []byte(`42`)}, `42`, "application/json; charset=utf-8"},
+		{"text", models.MockRule{BodyText: &text}, text, "text/plain; charset=utf-8"},
+		{"empty text", models.MockRule{BodyText: &empty}, "", "text/plain; charset=utf-8"},
+		{"text with content type", models.MockRule{BodyText: &text, ContentType: "text/csv"}, text, "text/csv"},
+		{"base64", models.MockRule{BodyBase64: "iVBORw0K"}, "\x89PNG\r\n", "application/octet-stream"},
+		{
+			"content type header",
+			models.MockRule{BodyText: &text, ResponseHeaders: map[string]models.HeaderValues{"Content-Type": {"text/html"}}},
+			text,
+			"text/html",
+		},
+		{"map", models.MockRule{Response: map[string]interface{}{"ok": true}}, `{"ok":true}`, "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, headers, body := rb.BuildResponse(&models.MatchResult{Rule: &tt.rule})
+
+			if string(body) != tt.body {
+				t.Errorf("Expected body %q, got %q", tt.body, body)
+			}
+			if got := headers.Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Expected Content-Type %q, got %q", tt.contentType, got)
+			}
+		})
+	}
+}
+
+// TestBuildResponseInvalidBase64 tests that an undecodable body yields a 500 response
+func TestBuildResponseInvalidBase64(t *testing.T) {
+	rb := NewResponseBuilder()
+
+	statusCode, _, body := rb.BuildResponse(&models.MatchResult{Rule: &models.MockRule{BodyBase64: "not base64!"}})
+
+	if statusCode != 500 {
+		t.Errorf("Expected status code 500, got %d", statusCode)
+	}
+	if !strings.Contains(string(body), "bodyBase64") {
+		t.Errorf("Expected error body mentioning bodyBase64, got %s", body)
+	}
+}
+
+// TestBuildResponseBodyFile tests that body files are served with a content type inferred from the extension
+func TestBuildResponseBodyFile(t *testing.T) {
+	rb := NewResponseBuilder()
+	dir := t.TempDir()
+
+	files := map[string][]byte{
+		"users.json": []byte(`[{"id":1}]`),
+		"logo.png":   {0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'},
+		"notes":      []byte("plain notes"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatalf("Failed to create body file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		file        string
+		contentType string
+	}{
+		{"users.json", "application/json"},
+		{"logo.png", "image/png"},
+		{"notes", "text/plain; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		rule := &models.MockRule{BodyFile: tt.file, BodyFilePath: filepath.Join(dir, tt.file)}
+		statusCode, headers, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
+
+		if statusCode != 200 {
+			t.Errorf("%s: expected status code 200, got %d", tt.file, statusCode)
+		}
+		if string(body) != string(files[tt.file]) {
+			t.Errorf("%s: expected file content, got %q", tt.file, body)
+		}
+		if got := headers.Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", tt.file, tt.contentType, got)
+		}
+	}
+
+	// A file removed after the configuration loaded yields a 500 response
+	if err := os.Remove(filepath.Join(dir, "notes")); err != nil {
+		t.Fatalf("Failed to remove body file: %v", err)
+	}
+	rule := &models.MockRule{BodyFile: "notes", BodyFilePath: filepath.Join(dir, "notes")}
+	if statusCode, _, _ := rb.BuildResponse(&models.MatchResult{Rule: rule}); statusCode != 500 {
+		t.Errorf("Expected status code 500 for a missing file, got %d", statusCode)
+	}
+}
+
+// TestBuildResponseTemplate tests rendering of templated bodies and headers with request data
+func TestBuildResponseTemplate(t *testing.T) {
+	rb := NewResponseBuilder()
+
+	rule := &models.MockRule{
+		Template: true,
+		Code:     201,
+		BodyJSON: []byte(`{"id":"{{.params.id}}","status":"{{.query.status}}","tenant":"{{index .headers \"X-Tenant\"}}",` +
+			`"name":"{{.body | jsonPath \"$.user.name\"}}","items":[1,"{{.method}}"]}`),
+		ResponseHeaders: map[string]models.HeaderValues{"Location": {"/api/users/{{.params.id}}"}},
+	}
+	if err := CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+
+	match := &models.MatchResult{
+		Rule:       rule,
+		PathParams: map[string]string{"id": "42"},
+		Request: &models.Request{
+			Method: "PUT",
+			Path:   "/api/users/42",
+			Query:  url.Values{"status": {"active"}},
+			Header: http.Header{"X-Tenant": {"acme"}},
+			Body:   []byte(`{"user":{"name":"alice"}}`),
+		},
+	}
+
+	statusCode, headers, body := rb.BuildResponse(match)
+
+	if statusCode != 201 {
+		t.Errorf("Expected status code 201, got %d", statusCode)
+	}
+	if got := headers.Get("Location"); got != "/api/users/42" {
+		t.Errorf("Expected Location '/api/users/42', got %q", got)
+	}
+	assertJSONBody(t, body, map[string]interface{}{
+		"id": "42", "status": "active", "tenant": "acme", "name": "alice", "items": []interface{}{1, "PUT"},
+	})
+}
+
+// TestBuildResponseTemplateError tests that a failing template yields a 500 response
+func TestBuildResponseTemplateError(t *testing.T) {
+	rb := NewResponseBuilder()
+
+	text := `{{randomInt 9 1}}`
+	rule := &models.MockRule{Template: true, BodyText: &text}
+	if err := CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+
+	if statusCode, _, _ := rb.BuildResponse(&models.MatchResult{Rule: rule}); statusCode != 500 {
+		t.Errorf("Expected status code 500, got %d", statusCode)
 	}
 }
@@ -0,0 +1,445 @@
+package response
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"mock-service/internal/fake"
+	"mock-service/internal/jsonpath"
+	"mock-service/internal/models"
+)
+
+// templateFuncs returns the helper functions available to response templates
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"uuid":         newUUID,
+		"now":          time.Now,
+		"date":         formatDate,
+		"addDuration":  addDuration,
+		"randomInt":    randomInt,
+		"jsonPath":     selectJSONPath,
+		"base64":       encodeBase64,
+		"base64Decode": decodeBase64,
+		"toJson":       toJSON,
+		"default":      defaultValue,
+		"faker":        fake.New,
+	}
+}
+
+// parseTemplate parses a response template with the helper functions
+// Missing path parameters, query parameters and headers render as empty strings
+func parseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, defined := range tmpl.Templates() {
+		if err := compileJSONPaths(defined.Root); err != nil {
+			return nil, fmt.Errorf("template: %s: %w", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// isTemplate reports whether text holds template actions
+func isTemplate(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// compileTemplates parses the templates and collection directives of a rule
+// String templates are only parsed for templated rules, while $repeat and $paginate
+// directives are compiled for every rule with a JSON body
+func compileTemplates(rule *models.MockRule) error {
+	// Variants and sequence steps are copies of their rule; they must not keep its template
+	rule.ResponseTemplate = nil
+
+	var document interface{}
+	switch {
+	case rule.Response != nil:
+		document = rule.Response
+	case rule.BodyJSON != nil:
+		if err := json.Unmarshal(rule.BodyJSON, &document); err != nil {
+			return fmt.Errorf("invalid bodyJson: %w", err)
+		}
+	}
+
+	if !rule.Template && !hasDirectives(document) {
+		return nil
+	}
+	if rule.Template && (rule.BodyBase64 != "" || rule.BodyFile != "") {
+		return fmt.Errorf("template is not supported with bodyBase64 or bodyFile")
+	}
+
+	compiled := &models.ResponseTemplate{}
+
+	if rule.Template {
+		compiled.Headers = make(map[string][]*template.Template)
+		for name, values := range rule.ResponseHeaders {
+			for _, value := range values {
+				tmpl, err := parseTemplate(name, value)
+				if err != nil {
+					return fmt.Errorf("invalid template in response header %s: %w", name, err)
+				}
+				compiled.Headers[name] = append(compiled.Headers[name], tmpl)
+			}
+		}
+
+		if rule.BodyText != nil {
+			tmpl, err := parseTemplate("bodyText", *rule.BodyText)
+			if err != nil {
+				return fmt.Errorf("invalid template in bodyText: %w", err)
+			}
+			compiled.Text = tmpl
+		}
+	}
+
+	if document != nil {
+		body, err := bodyCompiler{templates: rule.Template}.compile(document, "$")
+		if err != nil {
+			return err
+		}
+		compiled.Body = body
+	}
+
+	rule.ResponseTemplate = compiled
+	return nil
+}
+
+// bodyCompiler compiles a JSON body into a tree of values, templates and directives
+type bodyCompiler struct {
+	// templates enables parsing of strings holding template actions
+	templates bool
+}
+
+// compile returns a copy of a JSON document where every string holding template actions is parsed
+// and every $repeat or $paginate directive is compiled
+// location is the JSONPath of the value and is used in error messages
+func (bc bodyCompiler) compile(value interface{}, location string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if spec, ok := directive(v, repeatDirective); ok {
+			return bc.compileRepeat(spec, location+"."+repeatDirective)
+		}
+		if spec, ok := directive(v, paginateDirective); ok {
+			return bc.compilePaginate(spec, location+"."+paginateDirective)
+		}
+		compiled := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			child, err := bc.compile(item, location+"."+key)
+			if err != nil {
+				return nil, err
+			}
+			compiled[key] = child
+		}
+		return compiled, nil
+	case []interface{}:
+		compiled := make([]interface{}, len(v))
+		for i, item := range v {
+			child, err := bc.compile(item, fmt.Sprintf("%s[%d]", location, i))
+			if err != nil {
+				return nil, err
+			}
+			compiled[i] = child
+		}
+		return compiled, nil
+	case string:
+		if !bc.templates || !isTemplate(v) {
+			return v, nil
+		}
+		tmpl, err := parseTemplate(location, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template at %s: %w", location, err)
+		}
+		return tmpl, nil
+	default:
+		return v, nil
+	}
+}
+
+// renderJSONTemplate renders a compiled JSON document, replacing every template with its output
+// and expanding every directive
+func renderJSONTemplate(value interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			child, err := renderJSONTemplate(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = child
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			child, err := renderJSONTemplate(item, data)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = child
+		}
+		return rendered, nil
+	case *template.Template:
+		return executeTemplate(v, data)
+	case *repeatNode:
+		return v.render(data)
+	case *paginateNode:
+		return v.render(data)
+	default:
+		return v, nil
+	}
+}
+
+// executeTemplate renders a template to a string
+func executeTemplate(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return out.String(), nil
+}
+
+// templateData builds the template context for a match
+// Query parameters and headers hold their first value; queryAll and headersAll hold every value
+func templateData(match *models.MatchResult) map[string]interface{} {
+	data := map[string]interface{}{
+		"params":     match.PathParams,
+		"method":     "",
+		"path":       "",
+		"query":      map[string]string{},
+		"queryAll":   url.Values{},
+		"headers":    map[string]string{},
+		"headersAll": map[string][]string{},
+		"body":       nil,
+		"rawBody":    "",
+	}
+	if match.PathParams == nil {
+		data["params"] = map[string]string{}
+	}
+
+	req := match.Request
+	if req == nil {
+		return data
+	}
+
+	query := make(map[string]string, len(req.Query))
+	for key, values := range req.Query {
+		if len(values) > 0 {
+			query[key] = values[0]
+		}
+	}
+
+	headers := make(map[string]string, len(req.Header))
+	for key, values := range req.Header {
+		if len(values) > 0 {
+			headers[key] = values[0]
+		}
+	}
+
+	data["method"] = req.Method
+	data["path"] = req.Path
+	data["query"] = query
+	data["queryAll"] = req.Query
+	data["headers"] = headers
+	data["headersAll"] = map[string][]string(req.Header)
+	data["rawBody"] = string(req.Body)
+	if body, ok := req.JSONBody(); ok {
+		data["body"] = body
+	}
+	return data
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
+// formatDate formats a time with a Go layout or one of the names RFC3339, RFC1123, unix and unixMilli
+func formatDate(layout string, t time.Time) string {
+	switch layout {
+	case "RFC3339":
+		return t.Format(time.RFC3339)
+	case "RFC1123":
+		return t.UTC().Format(http.TimeFormat)
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixMilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.Format(layout)
+	}
+}
+
+// addDuration adds a duration such as "24h" or "-15m" to a time
+func addDuration(duration string, t time.Time) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid duration %q: %w", duration, err)
+	}
+	return t.Add(d), nil
+}
+
+// randomInt returns a random integer between min and max, inclusive
+func randomInt(minValue, maxValue int) (int, error) {
+	if maxValue < minValue {
+		return 0, fmt.Errorf("randomInt: max %d is less than min %d", maxValue, minValue)
+	}
+	// The range of two ints can exceed an int64, e.g. from math.MinInt to math.MaxInt
+	low := big.NewInt(int64(minValue))
+	span := new(big.Int).Sub(big.NewInt(int64(maxValue)), low)
+	n, err := rand.Int(rand.Reader, span.Add(span, big.NewInt(1)))
+	if err != nil {
+		return 0, fmt.Errorf("randomInt: %w", err)
+	}
+	return int(n.Add(n, low).Int64()), nil
+}
+
+// jsonPathCache keeps the constant JSONPath expressions of parsed templates
+// Only templates add to it, so request data cannot make it grow
+var jsonPathCache sync.Map
+
+// compileJSONPaths compiles and caches the constant expressions passed to jsonPath in a template tree
+// Expressions built while the template runs are compiled on every call instead
+func compileJSONPaths(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			return compileJSONPathNodes(n.Nodes)
+		}
+	case *parse.ActionNode:
+		return compileJSONPaths(n.Pipe)
+	case *parse.IfNode:
+		return compileJSONPathNodes([]parse.Node{n.Pipe, n.List, n.ElseList})
+	case *parse.RangeNode:
+		return compileJSONPathNodes([]parse.Node{n.Pipe, n.List, n.ElseList})
+	case *parse.WithNode:
+		return compileJSONPathNodes([]parse.Node{n.Pipe, n.List, n.ElseList})
+	case *parse.TemplateNode:
+		return compileJSONPaths(n.Pipe)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, command := range n.Cmds {
+				if err := compileJSONPaths(command); err != nil {
+					return err
+				}
+			}
+		}
+	case *parse.CommandNode:
+		if err := compileJSONPathCall(n); err != nil {
+			return err
+		}
+		return compileJSONPathNodes(n.Args)
+	}
+	return nil
+}
+
+// compileJSONPathNodes compiles the constant JSONPath expressions of each node
+func compileJSONPathNodes(nodes []parse.Node) error {
+	for _, node := range nodes {
+		if err := compileJSONPaths(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compileJSONPathCall caches the expression of a jsonPath call whose expression is a string constant
+func compileJSONPathCall(command *parse.CommandNode) error {
+	if len(command.Args) < 2 {
+		return nil
+	}
+	function, isIdentifier := command.Args[0].(*parse.IdentifierNode)
+	expr, isString := command.Args[1].(*parse.StringNode)
+	if !isIdentifier || !isString || function.Ident != "jsonPath" {
+		return nil
+	}
+	if _, ok := loadJSONPath(expr.Text); ok {
+		return nil
+	}
+
+	compiled, err := jsonpath.Compile(expr.Text)
+	if err != nil {
+		return fmt.Errorf("jsonPath: %w", err)
+	}
+	jsonPathCache.Store(expr.Text, compiled)
+	return nil
+}
+
+// loadJSONPath returns the cached compiled form of a JSONPath expression
+func loadJSONPath(expr string) (*jsonpath.Path, bool) {
+	cached, ok := jsonPathCache.Load(expr)
+	if !ok {
+		return nil, false
+	}
+	path, ok := cached.(*jsonpath.Path)
+	return path, ok
+}
+
+// selectJSONPath returns the first value selected by a JSONPath expression, or nil when nothing matches
+// It is written so that it can be used in a pipeline: {{.body | jsonPath "$.user.id"}}
+func selectJSONPath(expr string, document interface{}) (interface{}, error) {
+	path, ok := loadJSONPath(expr)
+	if !ok {
+		compiled, err := jsonpath.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("jsonPath: %w", err)
+		}
+		path = compiled
+	}
+
+	values := path.Evaluate(document)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values[0], nil
+}
+
+// encodeBase64 encodes a string with standard base64
+func encodeBase64(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+// decodeBase64 decodes a standard base64 string
+func decodeBase64(value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("base64Decode: %w", err)
+	}
+	return string(data), nil
+}
+
+// toJSON encodes a value as compact JSON
+func toJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("toJson: %w", err)
+	}
+	return string(data), nil
+}
+
+// defaultValue returns value, or fallback when value is missing or empty
+// It is written so that it can be used in a pipeline: {{.query.limit | default "10"}}
+func defaultValue(fallback, value interface{}) interface{} {
+	if value == nil || value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,252 @@
+package response
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"mock-service/internal/models"
+)
+
+// renderText parses and renders a template against the given match
+func renderText(t *testing.T, text string, match *models.MatchResult) string {
+	t.Helper()
+
+	tmpl, err := parseTemplate("test", text)
+	if err != nil {
+		t.Fatalf("Failed to parse template %q: %v", text, err)
+	}
+	out, err := executeTemplate(tmpl, templateData(match))
+	if err != nil {
+		t.Fatalf("Failed to render template %q: %v", text, err)
+	}
+	return out
+}
+
+// TestTemplateData tests that the template context exposes the request
+func TestTemplateData(t *testing.T) {
+	match := &models.MatchResult{
+		PathParams: map[string]string{"id": "42"},
+		Request: &models.Request{
+			Method: "POST",
+			Path:   "/api/users/42",
+			Query:  url.Values{"tag": {"a", "b"}},
+			Header: http.Header{"X-Tenant": {"acme"}},
+			Body:   []byte(`{"user":{"name":"alice"}}`),
+		},
+	}
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{{.method}} {{.path}}", "POST /api/users/42"},
+		{"{{.params.id}}", "42"},
+		{"{{.query.tag}} {{index .queryAll.tag 1}}", "a b"},
+		{`{{index .headers "X-Tenant"}}`, "acme"},
+		{"{{.body.user.name}}", "alice"},
+		{`{{.body | jsonPath "$.user.name"}}`, "alice"},
+		{`{{.rawBody}}`, `{"user":{"name":"alice"}}`},
+		{`{{.body.user | toJson}}`, `{"name":"alice"}`},
+	}
+
+	for _, tt := range tests {
+		if got := renderText(t, tt.template, match); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.template, tt.expected, got)
+		}
+	}
+}
+
+// TestTemplateDataWithoutRequest tests that a match without a request renders empty values
+func TestTemplateDataWithoutRequest(t *testing.T) {
+	got := renderText(t, "[{{.method}}][{{.params.id}}][{{.query.x}}]", &models.MatchResult{})
+	if got != "[][][]" {
+		t.Errorf("Expected empty values, got %q", got)
+	}
+
+	got = renderText(t, `{{.query.limit | default "10"}}/{{.body | jsonPath "$.a" | default "none"}}`, &models.MatchResult{})
+	if got != "10/none" {
+		t.Errorf("Expected default values, got %q", got)
+	}
+}
+
+// TestTemplateFuncs tests the helper functions available to templates
+func TestTemplateFuncs(t *testing.T) {
+	match := &models.MatchResult{}
+
+	uuid := renderText(t, "{{uuid}}", match)
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid) {
+		t.Errorf("Expected a version 4 UUID, got %q", uuid)
+	}
+
+	for i := 0; i < 20; i++ {
+		n := renderText(t, "{{randomInt 5 7}}", match)
+		if n != "5" && n != "6" && n != "7" {
+			t.Fatalf("Expected randomInt between 5 and 7, got %s", n)
+		}
+	}
+
+	if got := renderText(t, `{{base64 "hello"}}`, match); got != "aGVsbG8=" {
+		t.Errorf("Expected base64 'aGVsbG8=', got %q", got)
+	}
+	if got := renderText(t, `{{base64Decode "aGVsbG8="}}`, match); got != "hello" {
+		t.Errorf("Expected decoded 'hello', got %q", got)
+	}
+
+	year := renderText(t, `{{now | date "2006"}}`, match)
+	if year != time.Now().Format("2006") {
+		t.Errorf("Expected current year, got %q", year)
+	}
+}
+
+// TestRandomIntRange tests that randomInt handles ranges wider than an int64
+func TestRandomIntRange(t *testing.T) {
+	if _, err := randomInt(math.MinInt, math.MaxInt); err != nil {
+		t.Errorf("Expected the full int range to be accepted, got %v", err)
+	}
+	if n, err := randomInt(math.MaxInt, math.MaxInt); err != nil || n != math.MaxInt {
+		t.Errorf("Expected %d, got %d, %v", math.MaxInt, n, err)
+	}
+	if n, err := randomInt(-3, -3); err != nil || n != -3 {
+		t.Errorf("Expected -3, got %d, %v", n, err)
+	}
+}
+
+// TestTemplateJSONPathCache tests that only the constant expressions of templates are compiled ahead and cached
+func TestTemplateJSONPathCache(t *testing.T) {
+	text := `{{if true}}{{(jsonPath "$.cached.const" .body)}}{{end}}{{.body | jsonPath .query.expr}}{{define "x"}}{{end}}`
+	match := &models.MatchResult{
+		Request: &models.Request{
+			Query: url.Values{"expr": {"$.cached.dynamic"}},
+			Body:  []byte(`{"cached":{"const":"a","dynamic":"b"}}`),
+		},
+	}
+
+	if got := renderText(t, text, match); got != "ab" {
+		t.Errorf("Expected \"ab\", got %q", got)
+	}
+	if _, ok := loadJSONPath("$.cached.const"); !ok {
+		t.Error("Expected the constant expression to be cached")
+	}
+	if _, ok := loadJSONPath("$.cached.dynamic"); ok {
+		t.Error("Expected the expression from the request not to be cached")
+	}
+
+	if _, err := parseTemplate("invalid", `{{.body | jsonPath "$["}}`); err == nil {
+		t.Error("Expected an invalid constant expression to fail when the template is parsed")
+	}
+	if got := renderText(t, "", match); got != "" {
+		t.Errorf("Expected an empty template to render nothing, got %q", got)
+	}
+}
+
+// TestFormatDate tests the named and Go layouts of the date helper
+func TestFormatDate(t *testing.T) {
+	moment := time.Date(2024, 1, 14, 15, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		layout   string
+		expected string
+	}{
+		{"RFC3339", "2024-01-14T15:30:45Z"},
+		{"RFC1123", "Sun, 14 Jan 2024 15:30:45 GMT"},
+		{"unix", "1705246245"},
+		{"unixMilli", "1705246245000"},
+		{"2006-01-02", "2024-01-14"},
+	}
+
+	for _, tt := range tests {
+		if got := formatDate(tt.layout, moment); got != tt.expected {
+			t.Errorf("formatDate(%q) = %q, expected %q", tt.layout, got, tt.expected)
+		}
+	}
+
+	later, err := addDuration("36h", moment)
+	if err != nil || formatDate("2006-01-02", later) != "2024-01-16" {
+		t.Errorf("Expected addDuration to move to 2024-01-16, got %v (%v)", later, err)
+	}
+}
+
+// TestCompileTemplates tests parsing of body and header templates
+func TestCompileTemplates(t *testing.T) {
+	text := "Hello {{.params.name}}"
+	rule := &models.MockRule{
+		Template:        true,
+		BodyText:        &text,
+		ResponseHeaders: map[string]models.HeaderValues{"Location": {"/users/{{.params.id}}"}},
+	}
+
+	if err := CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+	if rule.ResponseTemplate == nil || rule.ResponseTemplate.Text == nil || len(rule.ResponseTemplate.Headers["Location"]) != 1 {
+		t.Fatalf("Expected parsed body and header templates, got %+v", rule.ResponseTemplate)
+	}
+
+	jsonRule := &models.MockRule{
+		Template: true,
+		Response: map[string]interface{}{"id": "{{.params.id}}", "tags": []interface{}{"static", "{{.method}}"}, "n": 1.0},
+	}
+	if err := CompileResponse(jsonRule, ""); err != nil {
+		t.Fatalf("CompileResponse failed: %v", err)
+	}
+	body, ok := jsonRule.ResponseTemplate.Body.(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a compiled JSON object, got %T", jsonRule.ResponseTemplate.Body)
+	}
+	if _, ok := body["id"].(*template.Template); !ok {
+		t.Errorf("Expected id to be a template, got %T", body["id"])
+	}
+	if tags, ok := body["tags"].([]interface{}); !ok || tags[0] != "static" {
+		t.Errorf("Expected plain strings to stay strings, got %v", body["tags"])
+	}
+}
+
+// TestCompileTemplatesErrors tests that broken templates are rejected at load time
+func TestCompileTemplatesErrors(t *testing.T) {
+	broken := "{{.params.id"
+
+	tests := []struct {
+		name string
+		rule models.MockRule
+	}{
+		{"body text", models.MockRule{Template: true, BodyText: &broken}},
+		{"json body", models.MockRule{Template: true, BodyJSON: []byte(`{"a":["{{if}}"]}`)}},
+		{"header", models.MockRule{Template: true, ResponseHeaders: map[string]models.HeaderValues{"X-Id": {broken}}}},
+		{"base64", models.MockRule{Template: true, BodyBase64: "aGk="}},
+		{"unknown function", models.MockRule{Template: true, Response: map[string]interface{}{"a": "{{nope}}"}}},
+	}
+
+	for _, tt := range tests {
+		err := CompileResponse(&tt.rule, "")
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+			continue
+		}
+		if tt.name == "json body" && !strings.Contains(err.Error(), "$.a[0]") {
+			t.Errorf("%s: expected the error to name the location, got %v", tt.name, err)
+		}
+	}
+}
+
+// TestTemplateFaker tests that faker values are reproducible from request data
+func TestTemplateFaker(t *testing.T) {
+	match := &models.MatchResult{PathParams: map[string]string{"id": "42"}}
+	text := `{{$f := faker .params.id}}{{$f.Name}} <{{$f.Email}}> {{$f.Int 1 100}} {{$f.Pick "a" "b"}}`
+
+	first := renderText(t, text, match)
+	second := renderText(t, text, match)
+	if first != second {
+		t.Errorf("Expected the same output for the same id, got %q and %q", first, second)
+	}
+
+	other := renderText(t, text, &models.MatchResult{PathParams: map[string]string{"id": "43"}})
+	if first == other {
+		t.Errorf("Expected a different output for another id, got %q", other)
+	}
+}
@@ -0,0 +1,212 @@
+package response
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mock-service/internal/models"
+)
+
+// compileVariants merges every variant of a rule with the rule, compiles the result and sets up the picker
+func compileVariants(rule *models.MockRule, baseDir string) error {
+	rule.VariantRules = nil
+	rule.VariantPicker = nil
+
+	if len(rule.Variants) == 0 {
+		if rule.VariantSelection != "" {
+			return fmt.Errorf("variantSelection requires variants")
+		}
+		return nil
+	}
+
+	weights := make([]int, len(rule.Variants))
+	total := 0
+	for i := range rule.Variants {
+		variant := &rule.Variants[i]
+		if variant.Weight < 0 {
+			return fmt.Errorf("invalid variant #%d: weight must not be negative", i+1)
+		}
+		weights[i] = variant.Weight
+		if weights[i] == 0 {
+			weights[i] = 1
+		}
+		// The picker draws from the sum of the weights, which must fit in an int
+		if weights[i] > math.MaxInt-total {
+			return fmt.Errorf("invalid variant #%d: the sum of the weights is too large", i+1)
+		}
+		total += weights[i]
+
+		variantRule := mergeVariant(rule, variant)
+		if err := CompileResponse(variantRule, baseDir); err != nil {
+			return fmt.Errorf("invalid variant #%d: %w", i+1, err)
+		}
+		rule.VariantRules = append(rule.VariantRules, variantRule)
+	}
+
+	seed := time.Now().UnixNano()
+	if rule.VariantSeed != nil {
+		seed = *rule.VariantSeed
+	}
+
+	switch rule.VariantSelection {
+	case "", models.VariantSelectionWeighted:
+		rule.VariantPicker = newWeightedPicker(weights, seed)
+	case models.VariantSelectionRandom:
+		for i := range weights {
+			weights[i] = 1
+		}
+		rule.VariantPicker = newWeightedPicker(weights, seed)
+	case models.VariantSelectionRoundRobin:
+		rule.VariantPicker = &roundRobinPicker{count: uint64(len(weights))}
+	default:
+		return fmt.Errorf("unknown variantSelection %q", rule.VariantSelection)
+	}
+	return nil
+}
+
+// compileSequence merges every response of a rule's sequence with the rule and compiles the result
+func compileSequence(rule *models.MockRule, baseDir string) error {
+	rule.SequenceRules = nil
+
+	if len(rule.Responses) == 0 {
+		if rule.SequenceEnd != "" {
+			return fmt.Errorf("sequenceEnd requires responses")
+		}
+		return nil
+	}
+	if len(rule.Variants) > 0 {
+		return fmt.Errorf("only one of variants and responses may be set")
+	}
+
+	switch rule.SequenceEnd {
+	case "", models.SequenceEndRepeatLast, models.SequenceEndLoop, models.SequenceEndFallthrough:
+	default:
+		return fmt.Errorf("unknown sequenceEnd %q", rule.SequenceEnd)
+	}
+
+	for i := range rule.Responses {
+		step := &rule.Responses[i]
+		if step.Weight != 0 {
+			return fmt.Errorf("invalid response #%d: weight only applies to variants", i+1)
+		}
+
+		stepRule := mergeVariant(rule, step)
+		if err := CompileResponse(stepRule, baseDir); err != nil {
+			return fmt.Errorf("invalid response #%d: %w", i+1, err)
+		}
+		rule.SequenceRules = append(rule.SequenceRules, stepRule)
+	}
+	return nil
+}
+
+// mergeVariant returns a copy of rule answering with variant, which may also be a step of a sequence
+// Variant headers override rule headers of the same name; a variant body replaces the rule body
+func mergeVariant(rule *models.MockRule, variant *models.ResponseVariant) *models.MockRule {
+	merged := *rule
+	merged.Variants = nil
+	merged.VariantSelection = ""
+	merged.VariantSeed = nil
+	merged.VariantRules = nil
+	merged.VariantPicker = nil
+	merged.Responses = nil
+	merged.SequenceEnd = ""
+	merged.SequenceRules = nil
+
+	if variant.Code != 0 {
+		merged.Code = variant.Code
+	}
+
+	merged.ResponseHeaders = make(map[string]models.HeaderValues, len(rule.ResponseHeaders)+len(variant.ResponseHeaders))
+	for name, values := range rule.ResponseHeaders {
+		merged.ResponseHeaders[name] = values
+	}
+	for name, values := range variant.ResponseHeaders {
+		for existing := range merged.ResponseHeaders {
+			if strings.EqualFold(existing, name) {
+				delete(merged.ResponseHeaders, existing)
+			}
+		}
+		merged.ResponseHeaders[name] = values
+	}
+
+	variantBody := bodyKind(variant.Response != nil || variant.BodyJSON != nil, variant.BodyText != nil,
+		variant.BodyBase64 != "", variant.BodyFile != "")
+	if variantBody != "" {
+		// The rule's content type describes the rule's body; it does not carry over to another kind of body
+		ruleBody := bodyKind(rule.Response != nil || rule.BodyJSON != nil, rule.BodyText != nil,
+			rule.BodyBase64 != "", rule.BodyFile != "")
+		if variantBody != ruleBody {
+			merged.ContentType = ""
+		}
+		merged.Response = variant.Response
+		merged.BodyJSON = variant.BodyJSON
+		merged.BodyText = variant.BodyText
+		merged.BodyBase64 = variant.BodyBase64
+		merged.BodyFile = variant.BodyFile
+		merged.BodyFilePath = ""
+	}
+	if variant.ContentType != "" {
+		merged.ContentType = variant.ContentType
+	}
+	return &merged
+}
+
+// bodyKind names the kind of body set by the given body fields, or returns "" when none is set
+func bodyKind(isJSON, isText, isBase64, isFile bool) string {
+	switch {
+	case isJSON:
+		return "json"
+	case isText:
+		return "text"
+	case isBase64:
+		return "base64"
+	case isFile:
+		return "file"
+	}
+	return ""
+}
+
+// weightedPicker picks indexes at random in proportion to their weights
+type weightedPicker struct {
+	mu         sync.Mutex
+	rng        *rand.Rand
+	cumulative []int
+}
+
+// newWeightedPicker creates a weightedPicker for positive weights whose random sequence is determined by seed
+func newWeightedPicker(weights []int, seed int64) *weightedPicker {
+	cumulative := make([]int, len(weights))
+	total := 0
+	for i, weight := range weights {
+		total += weight
+		cumulative[i] = total
+	}
+	return &weightedPicker{rng: rand.New(rand.NewSource(seed)), cumulative: cumulative}
+}
+
+// Pick returns a random index, weighted
+func (p *weightedPicker) Pick() int {
+	p.mu.Lock()
+	n := p.rng.Intn(p.cumulative[len(p.cumulative)-1])
+	p.mu.Unlock()
+
+	// The first index whose cumulative weight exceeds n
+	return sort.SearchInts(p.cumulative, n+1)
+}
+
+// roundRobinPicker cycles through the indexes in order
+type roundRobinPicker struct {
+	next  atomic.Uint64
+	count uint64
+}
+
+// Pick returns the next index
+func (p *roundRobinPicker) Pick() int {
+	return int((p.next.Add(1) - 1) % p.count)
+}
@@ -0,0 +1,269 @@
+package response
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// compileVariantRule compiles a rule with variants and fails the test on error
+func compileVariantRule(t *testing.T, rule *models.MockRule) *models.MockRule {
+	t.Helper()
+
+	if err := CompileResponse(rule, ""); err != nil {
+		t.Fatalf("CompileResponse() error = %v", err)
+	}
+	return rule
+}
+
+// statusCodes builds n responses for rule and returns their status codes
+func statusCodes(rule *models.MockRule, n int) []int {
+	rb := NewResponseBuilder()
+	codes := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		code, _, _ := rb.BuildResponse(&models.MatchResult{Rule: rule})
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// TestVariantsRoundRobin tests that round-robin cycles through the variants in order
+func TestVariantsRoundRobin(t *testing.T) {
+	rule := compileVariantRule(t, &models.MockRule{
+		VariantSelection: models.VariantSelectionRoundRobin,
+		Variants:         []models.ResponseVariant{{Code: 200}, {Code: 503}, {Code: 429}},
+	})
+
+	got := statusCodes(rule, 6)
+	want := []int{200, 503, 429, 200, 503, 429}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// TestVariantsWeighted tests that weighted picks follow the weights and are reproducible with a seed
+func TestVariantsWeighted(t *testing.T) {
+	seed := int64(42)
+	newRule := func() *models.MockRule {
+		return compileVariantRule(t, &models.MockRule{
+			VariantSeed: &seed,
+			Variants:    []models.ResponseVariant{{Code: 200, Weight: 90}, {Code: 503, Weight: 10}},
+		})
+	}
+
+	first := statusCodes(newRule(), 1000)
+	if second := statusCodes(newRule(), 1000); !reflect.DeepEqual(first, second) {
+		t.Error("Expected the same seed to produce the same picks")
+	}
+
+	failures := 0
+	for _, code := range first {
+		if code == 503 {
+			failures++
+		}
+	}
+	if failures < 50 || failures > 150 {
+		t.Errorf("Expected about 10%% of 503 responses, got %d of 1000", failures)
+	}
+}
+
+// TestVariantsRandomIgnoresWeights tests that random selection gives every variant a chance
+func TestVariantsRandomIgnoresWeights(t *testing.T) {
+	seed := int64(7)
+	rule := compileVariantRule(t, &models.MockRule{
+		VariantSelection: models.VariantSelectionRandom,
+		VariantSeed:      &seed,
+		Variants:         []models.ResponseVariant{{Code: 200, Weight: 1000}, {Code: 503, Weight: 1}},
+	})
+
+	failures := 0
+	for _, code := range statusCodes(rule, 1000) {
+		if code == 503 {
+			failures++
+		}
+	}
+	if failures < 400 || failures > 600 {
+		t.Errorf("Expected about half of the responses to be 503, got %d of 1000", failures)
+	}
+}
+
+// TestVariantsInheritFromRule tests that variants inherit the body and headers of the rule
+func TestVariantsInheritFromRule(t *testing.T) {
+	text := "try again later"
+	rule := compileVariantRule(t, &models.MockRule{
+		Code:             201,
+		Response:         map[string]interface{}{"status": "created"},
+		ResponseHeaders:  map[string]models.HeaderValues{"X-Request-Id": {"abc"}, "retry-after": {"1"}},
+		VariantSelection: models.VariantSelectionRoundRobin,
+		Variants: []models.ResponseVariant{
+			{},
+			{Code: 503, BodyText: &text, ResponseHeaders: map[string]models.HeaderValues{"Retry-After": {"30"}}},
+		},
+	})
+	rb := NewResponseBuilder()
+
+	code, headers, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
+	if code != 201 || headers.Get("Retry-After") != "1" {
+		t.Errorf("Expected the rule status and headers, got %d %v", code, headers)
+	}
+	assertJSONBody(t, body, map[string]interface{}{"status": "created"})
+
+	code, headers, body = rb.BuildResponse(&models.MatchResult{Rule: rule})
+	if code != 503 || string(body) != text {
+		t.Errorf("Expected the variant status and body, got %d %q", code, body)
+	}
+	if headers.Get("X-Request-Id") != "abc" || len(headers.Values("Retry-After")) != 1 || headers.Get("Retry-After") != "30" {
+		t.Errorf("Expected the variant header to override the rule header, got %v", headers)
+	}
+	if headers.Get("Content-Type") != contentTypeText {
+		t.Errorf("Expected a text content type, got %q", headers.Get("Content-Type"))
+	}
+}
+
+// TestVariantsDoNotInheritTemplate tests that a variant with its own body does not answer with the rule's directives
+func TestVariantsDoNotInheritTemplate(t *testing.T) {
+	rule := compileVariantRule(t, &models.MockRule{
+		Response: map[string]interface{}{
+			"$repeat": map[string]interface{}{"count": float64(2), "item": map[string]interface{}{"a": float64(1)}},
+		},
+		VariantSelection: models.VariantSelectionRoundRobin,
+		Variants: []models.ResponseVariant{
+			{},
+			{Code: 503, Response: map[string]interface{}{"error": "down"}},
+		},
+	})
+	rb := NewResponseBuilder()
+
+	_, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
+	if string(body) != `[{"a":1},{"a":1}]` {
+		t.Errorf("Expected the rule's repeated items, got %s", body)
+	}
+
+	code, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule})
+	if code != 503 {
+		t.Errorf("Expected the variant status, got %d", code)
+	}
+	assertJSONBody(t, body, map[string]interface{}{"error": "down"})
+}
+
+// TestVariantsContentType tests that the rule's content type only carries over to variants with the same kind of body
+func TestVariantsContentType(t *testing.T) {
+	text := "down"
+	rule := compileVariantRule(t, &models.MockRule{
+		Response:         map[string]interface{}{"status": "ok"},
+		ContentType:      "application/vnd.api+json",
+		VariantSelection: models.VariantSelectionRoundRobin,
+		Variants: []models.ResponseVariant{
+			{Response: map[string]interface{}{"status": "degraded"}},
+			{Code: 503, BodyText: &text},
+			{BodyText: &text, ContentType: "text/csv"},
+		},
+	})
+	rb := NewResponseBuilder()
+
+	for _, want := range []string{"application/vnd.api+json", contentTypeText, "text/csv"} {
+		if _, headers, _ := rb.BuildResponse(&models.MatchResult{Rule: rule}); headers.Get("Content-Type") != want {
+			t.Errorf("Expected content type %q, got %q", want, headers.Get("Content-Type"))
+		}
+	}
+}
+
+// TestCompileVariantsErrors tests validation of variants
+func TestCompileVariantsErrors(t *testing.T) {
+	text := "x"
+
+	tests := []struct {
+		name string
+		rule models.MockRule
+	}{
+		{"selection without variants", models.MockRule{VariantSelection: models.VariantSelectionRoundRobin}},
+		{"unknown selection", models.MockRule{VariantSelection: "sticky", Variants: []models.ResponseVariant{{}}}},
+		{"negative weight", models.MockRule{Variants: []models.ResponseVariant{{Weight: -1}}}},
+		{"two bodies", models.MockRule{Variants: []models.ResponseVariant{{BodyText: &text, BodyBase64: "eA=="}}}},
+		{"missing body file", models.MockRule{Variants: []models.ResponseVariant{{BodyFile: "missing.json"}}}},
+		{"weights overflow", models.MockRule{Variants: []models.ResponseVariant{{Weight: math.MaxInt}, {}}}},
+	}
+
+	for _, tt := range tests {
+		if err := CompileResponse(&tt.rule, t.TempDir()); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+// TestSequenceSteps tests that the response of the claimed sequence step is built
+func TestSequenceSteps(t *testing.T) {
+	rule := compileVariantRule(t, &models.MockRule{
+		Code: 202,
+		Responses: []models.ResponseVariant{
+			{Response: map[string]interface{}{"status": "pending"}},
+			{Response: map[string]interface{}{"status": "running"}},
+			{Code: 200, Response: map[string]interface{}{"status": "done"}},
+		},
+	})
+	rb := NewResponseBuilder()
+
+	tests := []struct {
+		index  int
+		code   int
+		status string
+	}{
+		{0, 202, "pending"},
+		{1, 202, "running"},
+		{2, 200, "done"},
+		{7, 200, "done"},
+	}
+
+	for _, tt := range tests {
+		code, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule, SequenceIndex: tt.index})
+		if code != tt.code {
+			t.Errorf("step %d: expected status %d, got %d", tt.index, tt.code, code)
+		}
+		assertJSONBody(t, body, map[string]interface{}{"status": tt.status})
+	}
+}
+
+// TestSequenceStepsDoNotInheritTemplate tests that steps with their own body do not answer with the rule's directives
+func TestSequenceStepsDoNotInheritTemplate(t *testing.T) {
+	done := "done"
+	rule := compileVariantRule(t, &models.MockRule{
+		Response: map[string]interface{}{
+			"$repeat": map[string]interface{}{"count": float64(2), "item": map[string]interface{}{"a": float64(1)}},
+		},
+		Responses: []models.ResponseVariant{
+			{Response: map[string]interface{}{"status": "pending"}},
+			{BodyText: &done},
+		},
+	})
+	rb := NewResponseBuilder()
+
+	_, _, body := rb.BuildResponse(&models.MatchResult{Rule: rule, SequenceIndex: 0})
+	assertJSONBody(t, body, map[string]interface{}{"status": "pending"})
+
+	_, headers, body := rb.BuildResponse(&models.MatchResult{Rule: rule, SequenceIndex: 1})
+	if string(body) != done || headers.Get("Content-Type") != contentTypeText {
+		t.Errorf("Expected the text step, got %q %q", headers.Get("Content-Type"), body)
+	}
+}
+
+// TestCompileSequenceErrors tests validation of response sequences
+func TestCompileSequenceErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule models.MockRule
+	}{
+		{"end without responses", models.MockRule{SequenceEnd: models.SequenceEndLoop}},
+		{"unknown end", models.MockRule{SequenceEnd: "stop", Responses: []models.ResponseVariant{{}}}},
+		{"weight", models.MockRule{Responses: []models.ResponseVariant{{Weight: 2}}}},
+		{"with variants", models.MockRule{Responses: []models.ResponseVariant{{}}, Variants: []models.ResponseVariant{{}}}},
+		{"invalid step", models.MockRule{Responses: []models.ResponseVariant{{BodyBase64: "%%%"}}}},
+	}
+
+	for _, tt := range tests {
+		if err := CompileResponse(&tt.rule, ""); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
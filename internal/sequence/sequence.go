@@ -0,0 +1,121 @@
+// Package sequence tracks how far each rule has advanced through its response sequence
+package sequence
+
+import (
+	"sort"
+	"sync"
+
+	"mock-service/internal/models"
+)
+
+// Counters counts the calls to rules with a response sequence, keyed by rule ID
+// It is safe for concurrent use
+type Counters struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewCounters creates a new instance of Counters with every sequence at its start
+func NewCounters() *Counters {
+	return &Counters{counts: make(map[string]int)}
+}
+
+// Next claims the next position in the response sequence of rule and advances it
+// It returns false when the sequence is exhausted and ends with fallthrough; the rule must then be skipped
+func (c *Counters) Next(rule *models.MockRule) (int, bool) {
+	return c.NextIf(rule, nil)
+}
+
+// NextIf is Next, but the position is only claimed when accept, if set, returns true
+// accept runs once a position is available, under the lock, so no other request can take that position
+// in between; it must not call back into the counters
+func (c *Counters) NextIf(rule *models.MockRule, accept func() bool) (int, bool) {
+	length := len(rule.SequenceRules)
+	if length == 0 {
+		return 0, accept == nil || accept()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := c.counts[rule.ID]
+	var index, next int
+	switch rule.SequenceEnd {
+	case models.SequenceEndLoop:
+		index, next = count%length, (count+1)%length
+	case models.SequenceEndFallthrough:
+		if count >= length {
+			return 0, false
+		}
+		index, next = count, count+1
+	default:
+		// The count stops at the length, where the last response keeps being returned
+		index, next = min(count, length-1), min(count+1, length)
+	}
+
+	if accept != nil && !accept() {
+		return 0, false
+	}
+	c.counts[rule.ID] = next
+	return index, true
+}
+
+// Available reports whether rule may still match
+// Only a sequence that ends with fallthrough and has been exhausted is unavailable
+func (c *Counters) Available(rule *models.MockRule) bool {
+	if len(rule.SequenceRules) == 0 || rule.SequenceEnd != models.SequenceEndFallthrough {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[rule.ID] < len(rule.SequenceRules)
+}
+
+// Reset moves the sequence of the rule with the given ID back to its start
+func (c *Counters) Reset(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.counts, id)
+}
+
+// ResetAll moves every sequence back to its start
+func (c *Counters) ResetAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = make(map[string]int)
+}
+
+// Restore replaces every count with the given ones, e.g. from a saved snapshot
+func (c *Counters) Restore(counts []Count) {
+	restored := make(map[string]int, len(counts))
+	for _, count := range counts {
+		if count.Count > 0 {
+			restored[count.RuleID] = count.Count
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = restored
+}
+
+// Counts returns the number of calls counted for each rule ID, sorted by ID
+// Counts of loop sequences wrap around and counts of other sequences stop at their length
+func (c *Counters) Counts() []Count {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make([]Count, 0, len(c.counts))
+	for id, count := range c.counts {
+		counts = append(counts, Count{RuleID: id, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].RuleID < counts[j].RuleID })
+	return counts
+}
+
+// Count is the position of one rule in its response sequence
+type Count struct {
+	RuleID string `json:"ruleId"`
+	Count  int    `json:"count"`
+}
@@ -0,0 +1,185 @@
+package sequence
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"mock-service/internal/models"
+)
+
+// sequenceRule returns a rule with a response sequence of the given length
+func sequenceRule(id, end string, length int) *models.MockRule {
+	rule := &models.MockRule{ID: id, SequenceEnd: end}
+	for i := 0; i < length; i++ {
+		rule.SequenceRules = append(rule.SequenceRules, &models.MockRule{})
+	}
+	return rule
+}
+
+// positions claims n positions of rule and returns them, with -1 for a refused claim
+func positions(counters *Counters, rule *models.MockRule, n int) []int {
+	result := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		index, ok := counters.Next(rule)
+		if !ok {
+			index = -1
+		}
+		result = append(result, index)
+	}
+	return result
+}
+
+// TestNextPolicies tests how each end policy continues after the last response
+func TestNextPolicies(t *testing.T) {
+	tests := []struct {
+		end  string
+		want []int
+	}{
+		{"", []int{0, 1, 2, 2, 2}},
+		{models.SequenceEndRepeatLast, []int{0, 1, 2, 2, 2}},
+		{models.SequenceEndLoop, []int{0, 1, 2, 0, 1}},
+		{models.SequenceEndFallthrough, []int{0, 1, 2, -1, -1}},
+	}
+
+	for _, tt := range tests {
+		got := positions(NewCounters(), sequenceRule("r", tt.end, 3), 5)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sequenceEnd %q: expected %v, got %v", tt.end, tt.want, got)
+		}
+	}
+}
+
+// TestNextWithoutSequence tests that rules without a sequence are always available at position 0
+func TestNextWithoutSequence(t *testing.T) {
+	counters := NewCounters()
+	rule := &models.MockRule{ID: "plain"}
+
+	if index, ok := counters.Next(rule); index != 0 || !ok {
+		t.Errorf("Expected position 0, got %d, %v", index, ok)
+	}
+	if !counters.Available(rule) || len(counters.Counts()) != 0 {
+		t.Error("Expected rules without a sequence to be available and not counted")
+	}
+}
+
+// TestNextIf tests that a refused position is left for the next call
+func TestNextIf(t *testing.T) {
+	counters := NewCounters()
+	rule := sequenceRule("r", models.SequenceEndFallthrough, 2)
+	refuse := func() bool { return false }
+
+	if _, ok := counters.NextIf(rule, refuse); ok {
+		t.Error("Expected a refused position not to be claimed")
+	}
+	if index, ok := counters.NextIf(rule, func() bool { return true }); index != 0 || !ok {
+		t.Errorf("Expected position 0 after a refusal, got %d, %v", index, ok)
+	}
+
+	called := false
+	counters.Next(rule)
+	if _, ok := counters.NextIf(rule, func() bool { called = true; return true }); ok || called {
+		t.Error("Expected an exhausted sequence not to call accept")
+	}
+	if _, ok := counters.NextIf(&models.MockRule{ID: "plain"}, refuse); ok {
+		t.Error("Expected accept to apply to rules without a sequence")
+	}
+}
+
+// TestAvailable tests that only exhausted fallthrough sequences are unavailable
+func TestAvailable(t *testing.T) {
+	counters := NewCounters()
+	fallthroughRule := sequenceRule("f", models.SequenceEndFallthrough, 1)
+	repeatRule := sequenceRule("r", models.SequenceEndRepeatLast, 1)
+
+	positions(counters, fallthroughRule, 1)
+	positions(counters, repeatRule, 3)
+
+	if counters.Available(fallthroughRule) {
+		t.Error("Expected an exhausted fallthrough sequence to be unavailable")
+	}
+	if !counters.Available(repeatRule) {
+		t.Error("Expected a repeat-last sequence to stay available")
+	}
+}
+
+// TestReset tests resetting one sequence and every sequence
+func TestReset(t *testing.T) {
+	counters := NewCounters()
+	first := sequenceRule("a", "", 3)
+	second := sequenceRule("b", "", 3)
+	positions(counters, first, 2)
+	positions(counters, second, 1)
+
+	want := []Count{{RuleID: "a", Count: 2}, {RuleID: "b", Count: 1}}
+	if got := counters.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	counters.Reset("a")
+	if index, _ := counters.Next(first); index != 0 {
+		t.Errorf("Expected the reset sequence to start over, got position %d", index)
+	}
+	if index, _ := counters.Next(second); index != 1 {
+		t.Errorf("Expected the other sequence to keep its position, got %d", index)
+	}
+
+	counters.ResetAll()
+	if len(counters.Counts()) != 0 {
+		t.Errorf("Expected no counts after ResetAll, got %v", counters.Counts())
+	}
+}
+
+// TestRestore tests that restored counts replace the current ones
+func TestRestore(t *testing.T) {
+	counters := NewCounters()
+	rule := sequenceRule("a", "", 3)
+	positions(counters, sequenceRule("b", "", 3), 1)
+
+	counters.Restore([]Count{{RuleID: "a", Count: 2}, {RuleID: "c", Count: 0}})
+
+	want := []Count{{RuleID: "a", Count: 2}}
+	if got := counters.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	if index, _ := counters.Next(rule); index != 2 {
+		t.Errorf("Expected the sequence to continue at position 2, got %d", index)
+	}
+}
+
+// TestNextConcurrent tests that concurrent requests claim every position exactly once
+func TestNextConcurrent(t *testing.T) {
+	const requests = 200
+	counters := NewCounters()
+	rule := sequenceRule("c", models.SequenceEndFallthrough, requests/2)
+
+	var mu sync.Mutex
+	claimed := make(map[int]int)
+	refused := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			index, ok := counters.Next(rule)
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				claimed[index]++
+			} else {
+				refused++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != requests/2 || refused != requests/2 {
+		t.Errorf("Expected %d distinct positions and %d refusals, got %d and %d", requests/2, requests/2, len(claimed), refused)
+	}
+	for index, count := range claimed {
+		if count != 1 {
+			t.Errorf("Expected position %d to be claimed once, got %d", index, count)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Package testutil holds the helpers shared by the tests of several packages
+package testutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Ptr returns a pointer to a copy of v, for the optional fields of test fixtures
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// DecodeJSON parses a JSON document for use in tests
+func DecodeJSON(t *testing.T, document string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		t.Fatalf("Invalid test document: %v", err)
+	}
+	return value
+}
@@ -0,0 +1,366 @@
+package xpath
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Node is an element of a parsed XML document
+// Names are stored without namespace prefixes so that expressions can address
+// SOAP and other namespaced documents by local name
+type Node struct {
+	Name     string
+	Attrs    map[string]string
+	Children []*Node
+	text     strings.Builder
+	content  strings.Builder
+}
+
+// Text returns the character data directly contained in the element
+func (n *Node) Text() string {
+	return strings.TrimSpace(n.text.String())
+}
+
+// Content returns the character data of the element and all its descendants in document order
+func (n *Node) Content() string {
+	return strings.TrimSpace(n.content.String())
+}
+
+// Parse parses an XML document and returns a synthetic root node whose only child is the document element
+func Parse(data []byte) (*Node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &Node{}
+	stack := []*Node{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &Node{Name: t.Name.Local, Attrs: make(map[string]string, len(t.Attr))}
+			for _, attr := range t.Attr {
+				node.Attrs[attr.Name.Local] = attr.Value
+			}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			stack[len(stack)-1].text.Write(t)
+			for _, open := range stack {
+				open.content.Write(t)
+			}
+		}
+	}
+
+	if len(root.Children) != 1 {
+		return nil, fmt.Errorf("invalid XML: expected a single document element")
+	}
+	return root, nil
+}
+
+// Path is a compiled XPath expression
+// The supported subset covers location paths used for request matching:
+//
+//	/a/b          child steps from the document root
+//	//b           descendant steps
+//	*             any element
+//	[2]           position predicate (1-based)
+//	[@id]         attribute existence predicate
+//	[@id='7']     attribute value predicate
+//	[name='x']    child element text predicate
+//	/@attr        attribute value (must be last)
+//	/text()       direct text of the element (must be last)
+//	count(path)   number of nodes selected by path
+//
+// Namespace prefixes in expressions are ignored and elements are matched by local name
+type Path struct {
+	expression string
+	steps      []step
+	attribute  string
+	text       bool
+	count      bool
+}
+
+// step is one location step of a compiled path
+type step struct {
+	descendant bool
+	name       string
+	predicates []predicate
+}
+
+// predicate filters the nodes selected by a step
+type predicate struct {
+	position  int
+	attribute string
+	child     string
+	value     string
+	hasValue  bool
+}
+
+// Compile parses an XPath expression
+func Compile(expression string) (*Path, error) {
+	expr := strings.TrimSpace(expression)
+	path := &Path{expression: expression}
+
+	if strings.HasPrefix(expr, "count(") && strings.HasSuffix(expr, ")") {
+		path.count = true
+		expr = strings.TrimSpace(expr[len("count(") : len(expr)-1])
+	}
+
+	if !strings.HasPrefix(expr, "/") {
+		return nil, fmt.Errorf("xpath %q must be an absolute path", expression)
+	}
+
+	for len(expr) > 0 {
+		descendant := strings.HasPrefix(expr, "//")
+		if descendant {
+			expr = expr[2:]
+		} else {
+			expr = expr[1:]
+		}
+
+		segment, rest := splitSegment(expr)
+		expr = rest
+		if segment == "" {
+			return nil, fmt.Errorf("xpath %q has an empty step", expression)
+		}
+
+		if err := path.addSegment(segment, descendant, rest == ""); err != nil {
+			return nil, fmt.Errorf("xpath %q: %w", expression, err)
+		}
+	}
+
+	if len(path.steps) == 0 {
+		return nil, fmt.Errorf("xpath %q selects no element", expression)
+	}
+	return path, nil
+}
+
+// addSegment adds a single location step to the path
+func (p *Path) addSegment(segment string, descendant, last bool) error {
+	if strings.HasPrefix(segment, "@") || segment == "text()" {
+		if !last || descendant {
+			return fmt.Errorf("%s must be the last step", segment)
+		}
+		if p.count {
+			return fmt.Errorf("count() requires an element path")
+		}
+		if segment == "text()" {
+			p.text = true
+		} else {
+			p.attribute = localName(segment[1:])
+		}
+		return nil
+	}
+
+	s := step{descendant: descendant}
+	name := segment
+	if open := strings.IndexByte(segment, '['); open >= 0 {
+		name = segment[:open]
+		predicates, err := parsePredicates(segment[open:])
+		if err != nil {
+			return err
+		}
+		s.predicates = predicates
+	}
+
+	s.name = localName(name)
+	if s.name == "" {
+		return fmt.Errorf("missing element name in %q", segment)
+	}
+	p.steps = append(p.steps, s)
+	return nil
+}
+
+// splitSegment splits the next step from the rest of the expression, ignoring slashes inside predicates
+func splitSegment(expr string) (segment, rest string) {
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '/':
+			if depth == 0 {
+				return expr[:i], expr[i:]
+			}
+		}
+	}
+	return expr, ""
+}
+
+// parsePredicates parses a sequence of [...] predicates
+func parsePredicates(expr string) ([]predicate, error) {
+	var predicates []predicate
+	for len(expr) > 0 {
+		if expr[0] != '[' {
+			return nil, fmt.Errorf("unexpected %q after predicate", expr)
+		}
+		end := strings.IndexByte(expr, ']')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated predicate")
+		}
+		pred, err := parsePredicate(strings.TrimSpace(expr[1:end]))
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, pred)
+		expr = expr[end+1:]
+	}
+	return predicates, nil
+}
+
+// parsePredicate parses the content of a single predicate
+func parsePredicate(content string) (predicate, error) {
+	if position, err := strconv.Atoi(content); err == nil {
+		if position < 1 {
+			return predicate{}, fmt.Errorf("position predicate must be at least 1")
+		}
+		return predicate{position: position}, nil
+	}
+
+	var pred predicate
+	target := content
+	if eq := strings.IndexByte(content, '='); eq >= 0 {
+		target = strings.TrimSpace(content[:eq])
+		literal := strings.TrimSpace(content[eq+1:])
+		if len(literal) < 2 || (literal[0] != '\'' && literal[0] != '"') || literal[len(literal)-1] != literal[0] {
+			return predicate{}, fmt.Errorf("predicate value %s must be a quoted string", literal)
+		}
+		pred.value = literal[1 : len(literal)-1]
+		pred.hasValue = true
+	}
+
+	if strings.HasPrefix(target, "@") {
+		pred.attribute = localName(target[1:])
+	} else {
+		pred.child = localName(target)
+	}
+	if pred.attribute == "" && pred.child == "" {
+		return predicate{}, fmt.Errorf("invalid predicate [%s]", content)
+	}
+	return pred, nil
+}
+
+// localName strips a namespace prefix from a name
+func localName(name string) string {
+	if colon := strings.LastIndexByte(name, ':'); colon >= 0 {
+		return name[colon+1:]
+	}
+	return name
+}
+
+// String returns the original expression
+func (p *Path) String() string {
+	return p.expression
+}
+
+// Evaluate returns the string values selected by the path in a parsed document
+// Elements yield their text content, attributes their value and count() the number of selected elements
+func (p *Path) Evaluate(root *Node) []string {
+	nodes := []*Node{root}
+	for _, s := range p.steps {
+		nodes = s.apply(nodes)
+	}
+
+	if p.count {
+		return []string{strconv.Itoa(len(nodes))}
+	}
+
+	values := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		switch {
+		case p.attribute != "":
+			if value, ok := node.Attrs[p.attribute]; ok {
+				values = append(values, value)
+			}
+		case p.text:
+			values = append(values, node.Text())
+		default:
+			values = append(values, node.Content())
+		}
+	}
+	return values
+}
+
+// apply selects the nodes addressed by the step from every context node
+func (s step) apply(context []*Node) []*Node {
+	var result []*Node
+	for _, node := range context {
+		var candidates []*Node
+		if s.descendant {
+			candidates = descendants(node)
+		} else {
+			candidates = node.Children
+		}
+
+		var selected []*Node
+		for _, candidate := range candidates {
+			if s.name == "*" || candidate.Name == s.name {
+				selected = append(selected, candidate)
+			}
+		}
+
+		for _, pred := range s.predicates {
+			selected = pred.filter(selected)
+		}
+		result = append(result, selected...)
+	}
+	return result
+}
+
+// filter keeps the nodes satisfying the predicate
+func (pred predicate) filter(nodes []*Node) []*Node {
+	if pred.position > 0 {
+		if pred.position <= len(nodes) {
+			return []*Node{nodes[pred.position-1]}
+		}
+		return nil
+	}
+
+	var result []*Node
+	for _, node := range nodes {
+		if pred.matches(node) {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// matches reports whether a node satisfies an attribute or child predicate
+func (pred predicate) matches(node *Node) bool {
+	if pred.attribute != "" {
+		value, ok := node.Attrs[pred.attribute]
+		return ok && (!pred.hasValue || value == pred.value)
+	}
+
+	for _, child := range node.Children {
+		if child.Name == pred.child && (!pred.hasValue || child.Content() == pred.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// descendants returns every element below node in document order
+func descendants(node *Node) []*Node {
+	var result []*Node
+	for _, child := range node.Children {
+		result = append(result, child)
+		result = append(result, descendants(child)...)
+	}
+	return result
+}
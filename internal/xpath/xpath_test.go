@@ -0,0 +1,104 @@
+package xpath
+
+import (
+	"reflect"
+	"testing"
+)
+
+const soapDocument = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/" xmlns:m="urn:orders">
+  <soap:Body>
+    <m:PlaceOrder priority="high">
+      <m:Customer id="42">Alice</m:Customer>
+      <m:Item sku="A1">Keyboard</m:Item>
+      <m:Item sku="B2">Mouse</m:Item>
+    </m:PlaceOrder>
+  </soap:Body>
+</soap:Envelope>`
+
+// TestEvaluate tests the supported XPath expressions
+func TestEvaluate(t *testing.T) {
+	root, err := Parse([]byte(soapDocument))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{"/soap:Envelope/soap:Body/m:PlaceOrder/m:Customer", []string{"Alice"}},
+		{"/Envelope/Body/PlaceOrder/Customer/@id", []string{"42"}},
+		{"//Item", []string{"Keyboard", "Mouse"}},
+		{"//Item[2]", []string{"Mouse"}},
+		{"//Item[@sku='A1']", []string{"Keyboard"}},
+		{"//Item/@sku", []string{"A1", "B2"}},
+		{"//PlaceOrder[@priority]/Customer", []string{"Alice"}},
+		{"//PlaceOrder[Customer='Alice']/@priority", []string{"high"}},
+		{"//PlaceOrder/*/text()", []string{"Alice", "Keyboard", "Mouse"}},
+		{"count(//Item)", []string{"2"}},
+		{"count(//Missing)", []string{"0"}},
+		{"//Missing", []string{}},
+		{"//Item[@sku='Z9']", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			path, err := Compile(tt.expression)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+
+			got := path.Evaluate(root)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestNodeContent tests that element content includes descendant text
+func TestNodeContent(t *testing.T) {
+	root, err := Parse([]byte(`<a>Hello <b>big</b> world</a>`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	document := root.Children[0]
+	if document.Content() != "Hello big world" {
+		t.Errorf("Expected content 'Hello big world', got %q", document.Content())
+	}
+	if document.Text() != "Hello  world" {
+		t.Errorf("Expected text 'Hello  world', got %q", document.Text())
+	}
+}
+
+// TestParseErrors tests that malformed documents are rejected
+func TestParseErrors(t *testing.T) {
+	for _, document := range []string{"", "not xml", "<a><b></a>", "<a/><b/>"} {
+		if _, err := Parse([]byte(document)); err == nil {
+			t.Errorf("Expected Parse(%q) to fail", document)
+		}
+	}
+}
+
+// TestCompileErrors tests that unsupported expressions are rejected
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		"Envelope/Body",
+		"/",
+		"/a//",
+		"/a/@id/b",
+		"/a/text()/b",
+		"//a[0]",
+		"//a[@id=7]",
+		"//a[@id='7'",
+		"count(//a/@id)",
+	}
+
+	for _, expression := range tests {
+		if _, err := Compile(expression); err == nil {
+			t.Errorf("Expected Compile(%q) to fail", expression)
+		}
+	}
+}